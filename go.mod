@@ -3,6 +3,7 @@ module github.com/sigstore/sigstore
 go 1.22.0
 
 require (
+	github.com/ProtonMail/go-crypto v1.1.3
 	github.com/coreos/go-oidc/v3 v3.10.0
 	github.com/go-jose/go-jose/v3 v3.0.3
 	github.com/go-rod/rod v0.116.1
@@ -19,9 +20,11 @@ require (
 	golang.org/x/crypto v0.24.0
 	golang.org/x/oauth2 v0.21.0
 	golang.org/x/term v0.21.0
+	software.sslmate.com/src/go-pkcs12 v0.7.3
 )
 
 require (
+	github.com/cloudflare/circl v1.3.7 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-jose/go-jose/v4 v4.0.1 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
@@ -127,13 +127,25 @@ func formatTime(t time.Time) string {
 //
 // It returns nil if issueTime < epoch < expirationTime, and error otherwise.
 func CheckExpiration(cert *x509.Certificate, epoch time.Time) error {
+	return CheckExpirationWithSkew(cert, epoch, 0)
+}
+
+// CheckExpirationWithSkew verifies that epoch is during the validity period of
+// the certificate provided, extended by skew at both ends to tolerate clock skew
+// between the signer (or timestamp authority) and the verifier.
+//
+// It returns nil if issueTime-skew <= epoch <= expirationTime+skew, and error otherwise.
+func CheckExpirationWithSkew(cert *x509.Certificate, epoch time.Time, skew time.Duration) error {
 	if cert == nil {
 		return errors.New("certificate is nil")
 	}
-	if cert.NotAfter.Before(epoch) {
+	if skew < 0 {
+		return errors.New("clock skew tolerance must not be negative")
+	}
+	if cert.NotAfter.Add(skew).Before(epoch) {
 		return fmt.Errorf("certificate expiration time %s is before %s", formatTime(cert.NotAfter), formatTime(epoch))
 	}
-	if cert.NotBefore.After(epoch) {
+	if cert.NotBefore.Add(-skew).After(epoch) {
 		return fmt.Errorf("certificate issued time %s is before %s", formatTime(cert.NotBefore), formatTime(epoch))
 	}
 	return nil
@@ -356,6 +356,34 @@ func TestCheckExpiration(t *testing.T) {
 	}
 }
 
+func TestCheckExpirationWithSkew(t *testing.T) {
+	cert := &x509.Certificate{
+		NotAfter:  time.Unix(4444, 0),
+		NotBefore: time.Unix(2222, 0),
+	}
+	testCases := []struct {
+		name    string
+		epoch   time.Time
+		skew    time.Duration
+		wantErr bool
+	}{
+		{name: "valid without skew", epoch: time.Unix(3333, 0)},
+		{name: "expired without skew", epoch: time.Unix(4445, 0), wantErr: true},
+		{name: "expired within skew", epoch: time.Unix(4445, 0), skew: time.Second},
+		{name: "expired beyond skew", epoch: time.Unix(4447, 0), skew: time.Second, wantErr: true},
+		{name: "not valid yet within skew", epoch: time.Unix(2221, 0), skew: time.Second},
+		{name: "not valid yet beyond skew", epoch: time.Unix(2219, 0), skew: time.Second, wantErr: true},
+		{name: "negative skew", epoch: time.Unix(3333, 0), skew: -time.Second, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := CheckExpirationWithSkew(cert, tc.epoch, tc.skew); (err != nil) != tc.wantErr {
+				t.Errorf("CheckExpirationWithSkew() returned %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
 func TestParseCSR(t *testing.T) {
 	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
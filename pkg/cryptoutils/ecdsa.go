@@ -0,0 +1,185 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptoutils
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// UnmarshalECDSASignature parses a DER-encoded ECDSA signature (an ASN.1 SEQUENCE of the
+// two INTEGERs R and S) into its components. Malformed encodings, non-canonical (non-DER)
+// encodings, trailing data and non-positive values are rejected.
+func UnmarshalECDSASignature(der []byte) (r, s *big.Int, err error) {
+	var inner cryptobyte.String
+	input := cryptobyte.String(der)
+	r, s = new(big.Int), new(big.Int)
+	if !input.ReadASN1(&inner, asn1.SEQUENCE) {
+		return nil, nil, errors.New("invalid ECDSA signature: not an ASN.1 SEQUENCE")
+	}
+	if !input.Empty() {
+		return nil, nil, errors.New("invalid ECDSA signature: trailing data after SEQUENCE")
+	}
+	if !inner.ReadASN1Integer(r) || !inner.ReadASN1Integer(s) {
+		return nil, nil, errors.New("invalid ECDSA signature: malformed INTEGER")
+	}
+	if !inner.Empty() {
+		return nil, nil, errors.New("invalid ECDSA signature: unexpected data after S")
+	}
+	if r.Sign() <= 0 || s.Sign() <= 0 {
+		return nil, nil, errors.New("invalid ECDSA signature: R and S must be positive")
+	}
+
+	// cryptobyte enforces minimal INTEGER encodings; re-encoding additionally catches
+	// any non-minimal length encodings so that only canonical DER is accepted
+	canonical, err := MarshalECDSASignature(r, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(canonical, der) {
+		return nil, nil, errors.New("invalid ECDSA signature: non-canonical DER encoding")
+	}
+	return r, s, nil
+}
+
+// MarshalECDSASignature returns the DER encoding of an ECDSA signature with the given R and S
+// components, which must both be positive.
+func MarshalECDSASignature(r, s *big.Int) ([]byte, error) {
+	if r == nil || s == nil {
+		return nil, errors.New("R and S must not be nil")
+	}
+	if r.Sign() <= 0 || s.Sign() <= 0 {
+		return nil, errors.New("R and S must be positive")
+	}
+	var b cryptobyte.Builder
+	b.AddASN1(asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1BigInt(r)
+		b.AddASN1BigInt(s)
+	})
+	return b.Bytes()
+}
+
+// MarshalECDSASignatureRaw returns the IEEE P1363 (R||S) encoding of an ECDSA signature over
+// the given curve. R and S are each left-padded with zeros to the curve's byte size, so the
+// output is always twice that size even when either component has leading zero bytes.
+func MarshalECDSASignatureRaw(curve elliptic.Curve, r, s *big.Int) ([]byte, error) {
+	if curve == nil {
+		return nil, errors.New("curve must not be nil")
+	}
+	raw, err := MarshalECDSASignatureRawWidth(ecdsaComponentSize(curve), r, s)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s", err, curve.Params().Name)
+	}
+	return raw, nil
+}
+
+// MarshalECDSASignatureRawWidth returns the R||S encoding of an ECDSA signature with each of R
+// and S written as a big-endian unsigned integer left-padded with zeros to exactly width bytes,
+// for systems that expect a component width other than the curve's byte size. The output is
+// always 2*width bytes; an error is returned if either component does not fit.
+func MarshalECDSASignatureRawWidth(width int, r, s *big.Int) ([]byte, error) {
+	if width <= 0 {
+		return nil, errors.New("component width must be positive")
+	}
+	if r == nil || s == nil {
+		return nil, errors.New("R and S must not be nil")
+	}
+	if r.Sign() <= 0 || s.Sign() <= 0 {
+		return nil, errors.New("R and S must be positive")
+	}
+	if len(r.Bytes()) > width || len(s.Bytes()) > width {
+		return nil, fmt.Errorf("R and S must fit in %d bytes", width)
+	}
+	raw := make([]byte, 2*width)
+	r.FillBytes(raw[:width])
+	s.FillBytes(raw[width:])
+	return raw, nil
+}
+
+// UnmarshalECDSASignatureRaw parses an IEEE P1363 (R||S) encoded ECDSA signature over the given
+// curve. Fixed-length input of twice the curve's byte size is split at the curve's byte size.
+// Shorter, even-length input from encoders that strip leading zeros is also accepted and is
+// split in half.
+func UnmarshalECDSASignatureRaw(curve elliptic.Curve, raw []byte) (r, s *big.Int, err error) {
+	if curve == nil {
+		return nil, nil, errors.New("curve must not be nil")
+	}
+	size := ecdsaComponentSize(curve)
+	if len(raw) == 0 || len(raw) > 2*size || len(raw)%2 != 0 {
+		return nil, nil, fmt.Errorf("invalid IEEE P1363 encoded signature length %d for %s", len(raw), curve.Params().Name)
+	}
+	r = new(big.Int).SetBytes(raw[:len(raw)/2])
+	s = new(big.Int).SetBytes(raw[len(raw)/2:])
+	if r.Sign() <= 0 || s.Sign() <= 0 {
+		return nil, nil, errors.New("invalid ECDSA signature: R and S must be positive")
+	}
+	return r, s, nil
+}
+
+// UnmarshalECDSASignatureRawWidth parses an R||S encoded ECDSA signature in which each of R and
+// S is a big-endian unsigned integer of exactly width bytes. Unlike UnmarshalECDSASignatureRaw,
+// input of any length other than 2*width is rejected rather than split in half, so minimal-length
+// encodings that would be ambiguous are never accepted.
+func UnmarshalECDSASignatureRawWidth(width int, raw []byte) (r, s *big.Int, err error) {
+	if width <= 0 {
+		return nil, nil, errors.New("component width must be positive")
+	}
+	if len(raw) != 2*width {
+		return nil, nil, fmt.Errorf("invalid R||S encoded signature length %d, expected %d", len(raw), 2*width)
+	}
+	r = new(big.Int).SetBytes(raw[:width])
+	s = new(big.Int).SetBytes(raw[width:])
+	if r.Sign() <= 0 || s.Sign() <= 0 {
+		return nil, nil, errors.New("invalid ECDSA signature: R and S must be positive")
+	}
+	return r, s, nil
+}
+
+// ECDSASignatureDERToRaw converts a DER-encoded ECDSA signature over the given curve to the
+// fixed-width IEEE P1363 (R||S) encoding. The DER input must be canonical, as for
+// UnmarshalECDSASignature.
+func ECDSASignatureDERToRaw(curve elliptic.Curve, der []byte) ([]byte, error) {
+	r, s, err := UnmarshalECDSASignature(der)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalECDSASignatureRaw(curve, r, s)
+}
+
+// ECDSASignatureRawToDER converts a fixed-width IEEE P1363 (R||S) encoded ECDSA signature over
+// the given curve to DER. The input must be exactly twice the curve's byte size.
+func ECDSASignatureRawToDER(curve elliptic.Curve, raw []byte) ([]byte, error) {
+	if curve == nil {
+		return nil, errors.New("curve must not be nil")
+	}
+	r, s, err := UnmarshalECDSASignatureRawWidth(ecdsaComponentSize(curve), raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s", err, curve.Params().Name)
+	}
+	return MarshalECDSASignature(r, s)
+}
+
+// ecdsaComponentSize returns the size in bytes of each of R and S in a fixed-length
+// encoding over the given curve
+func ecdsaComponentSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
@@ -0,0 +1,229 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptoutils
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestECDSASignatureRoundTrip(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("sign me"))
+	der, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, s, err := UnmarshalECDSASignature(der)
+	if err != nil {
+		t.Fatalf("unexpected error unmarshalling signature: %v", err)
+	}
+	if !ecdsa.Verify(&priv.PublicKey, digest[:], r, s) {
+		t.Error("R and S do not verify")
+	}
+
+	marshalled, err := MarshalECDSASignature(r, s)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling signature: %v", err)
+	}
+	if !ecdsa.VerifyASN1(&priv.PublicKey, digest[:], marshalled) {
+		t.Error("marshalled signature does not verify")
+	}
+}
+
+func TestUnmarshalECDSASignatureInvalid(t *testing.T) {
+	tests := map[string][]byte{
+		"empty":               {},
+		"not a sequence":      {0x02, 0x01, 0x01},
+		"trailing data":       {0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01, 0x00},
+		"missing S":           {0x30, 0x03, 0x02, 0x01, 0x01},
+		"extra element":       {0x30, 0x09, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01},
+		"non-minimal integer": {0x30, 0x07, 0x02, 0x02, 0x00, 0x01, 0x02, 0x01, 0x01},
+		"non-minimal length":  {0x30, 0x81, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01},
+		"zero R":              {0x30, 0x06, 0x02, 0x01, 0x00, 0x02, 0x01, 0x01},
+		"negative S":          {0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0xff},
+		"truncated":           {0x30, 0x06, 0x02, 0x01, 0x01, 0x02},
+	}
+	for name, der := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := UnmarshalECDSASignature(der); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestMarshalECDSASignatureInvalid(t *testing.T) {
+	if _, err := MarshalECDSASignature(nil, big.NewInt(1)); err == nil {
+		t.Error("expected error for nil R")
+	}
+	if _, err := MarshalECDSASignature(big.NewInt(1), big.NewInt(0)); err == nil {
+		t.Error("expected error for zero S")
+	}
+	if _, err := MarshalECDSASignature(big.NewInt(-1), big.NewInt(1)); err == nil {
+		t.Error("expected error for negative R")
+	}
+}
+
+func TestECDSASignatureRawFixedLength(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		size := (curve.Params().BitSize + 7) / 8
+		// short components, as produced when R or S has leading zero bytes
+		r := big.NewInt(1)
+		s := new(big.Int).Lsh(big.NewInt(1), uint(8*(size-2)))
+		raw, err := MarshalECDSASignatureRaw(curve, r, s)
+		if err != nil {
+			t.Fatalf("%s: unexpected error marshalling signature: %v", curve.Params().Name, err)
+		}
+		if len(raw) != 2*size {
+			t.Fatalf("%s: expected %d bytes, got %d", curve.Params().Name, 2*size, len(raw))
+		}
+		gotR, gotS, err := UnmarshalECDSASignatureRaw(curve, raw)
+		if err != nil {
+			t.Fatalf("%s: unexpected error unmarshalling signature: %v", curve.Params().Name, err)
+		}
+		if gotR.Cmp(r) != 0 || gotS.Cmp(s) != 0 {
+			t.Errorf("%s: round trip mismatch", curve.Params().Name)
+		}
+	}
+}
+
+func TestECDSASignatureRawUnpadded(t *testing.T) {
+	r, s := big.NewInt(0x0102), big.NewInt(0x0304)
+	gotR, gotS, err := UnmarshalECDSASignatureRaw(elliptic.P256(), []byte{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("unexpected error unmarshalling unpadded signature: %v", err)
+	}
+	if gotR.Cmp(r) != 0 || gotS.Cmp(s) != 0 {
+		t.Errorf("expected R=%v S=%v, got R=%v S=%v", r, s, gotR, gotS)
+	}
+}
+
+func TestECDSASignatureRawInvalid(t *testing.T) {
+	curve := elliptic.P256()
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 256)
+	if _, err := MarshalECDSASignatureRaw(curve, tooBig, big.NewInt(1)); err == nil {
+		t.Error("expected error marshalling R larger than the curve size")
+	}
+	if _, err := MarshalECDSASignatureRaw(curve, big.NewInt(0), big.NewInt(1)); err == nil {
+		t.Error("expected error marshalling zero R")
+	}
+	for _, raw := range [][]byte{nil, {1, 2, 3}, make([]byte, 66), make([]byte, 64)} {
+		if _, _, err := UnmarshalECDSASignatureRaw(curve, raw); err == nil {
+			t.Errorf("expected error unmarshalling %d byte signature", len(raw))
+		}
+	}
+}
+
+func TestECDSASignatureRawWidth(t *testing.T) {
+	r, s := big.NewInt(0x0102), big.NewInt(0x03)
+	raw, err := MarshalECDSASignatureRawWidth(4, r, s)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling signature: %v", err)
+	}
+	// each component is big-endian and left-padded to the width
+	if want := []byte{0, 0, 1, 2, 0, 0, 0, 3}; !bytes.Equal(raw, want) {
+		t.Errorf("expected %x, got %x", want, raw)
+	}
+	gotR, gotS, err := UnmarshalECDSASignatureRawWidth(4, raw)
+	if err != nil {
+		t.Fatalf("unexpected error unmarshalling signature: %v", err)
+	}
+	if gotR.Cmp(r) != 0 || gotS.Cmp(s) != 0 {
+		t.Errorf("expected R=%v S=%v, got R=%v S=%v", r, s, gotR, gotS)
+	}
+
+	if _, err := MarshalECDSASignatureRawWidth(1, r, s); err == nil {
+		t.Error("expected error marshalling R wider than the width")
+	}
+	if _, err := MarshalECDSASignatureRawWidth(0, r, s); err == nil {
+		t.Error("expected error marshalling with zero width")
+	}
+	// minimal-length encodings are rejected rather than split in half
+	for _, raw := range [][]byte{{1, 2, 3}, {1, 2, 3, 4}, make([]byte, 10), make([]byte, 8)} {
+		if _, _, err := UnmarshalECDSASignatureRawWidth(4, raw); err == nil {
+			t.Errorf("expected error unmarshalling %x", raw)
+		}
+	}
+	if _, _, err := UnmarshalECDSASignatureRawWidth(-1, nil); err == nil {
+		t.Error("expected error unmarshalling with negative width")
+	}
+}
+
+func TestECDSASignatureDERRawConversion(t *testing.T) {
+	msg := sha256.Sum256([]byte("sign me"))
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			size := (curve.Params().BitSize + 7) / 8
+			for i := 0; i < 50; i++ {
+				der, err := ecdsa.SignASN1(rand.Reader, priv, msg[:])
+				if err != nil {
+					t.Fatal(err)
+				}
+				raw, err := ECDSASignatureDERToRaw(curve, der)
+				if err != nil {
+					t.Fatalf("unexpected error converting DER to raw: %v", err)
+				}
+				if len(raw) != 2*size {
+					t.Fatalf("expected %d bytes, got %d", 2*size, len(raw))
+				}
+				r, s, err := UnmarshalECDSASignatureRawWidth(size, raw)
+				if err != nil {
+					t.Fatalf("unexpected error unmarshalling raw signature: %v", err)
+				}
+				if !ecdsa.Verify(&priv.PublicKey, msg[:], r, s) {
+					t.Fatal("raw signature does not verify")
+				}
+				back, err := ECDSASignatureRawToDER(curve, raw)
+				if err != nil {
+					t.Fatalf("unexpected error converting raw to DER: %v", err)
+				}
+				if !bytes.Equal(back, der) {
+					t.Fatalf("round trip mismatch: %x != %x", back, der)
+				}
+			}
+		})
+	}
+
+	curve := elliptic.P256()
+	if _, err := ECDSASignatureRawToDER(curve, make([]byte, 63)); err == nil {
+		t.Error("expected error converting short raw signature")
+	}
+	if _, err := ECDSASignatureRawToDER(nil, make([]byte, 64)); err == nil {
+		t.Error("expected error converting with nil curve")
+	}
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 256)
+	der, err := MarshalECDSASignature(tooBig, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ECDSASignatureDERToRaw(curve, der); err == nil {
+		t.Errorf("expected error converting DER with R wider than %s", curve.Params().Name)
+	}
+}
@@ -0,0 +1,117 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptoutils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// KeyType identifies the algorithm family of a public key
+type KeyType string
+
+// Key types recognized by ValidatePublicKey
+const (
+	KeyTypeRSA     KeyType = "rsa"
+	KeyTypeECDSA   KeyType = "ecdsa"
+	KeyTypeED25519 KeyType = "ed25519"
+)
+
+var (
+	// ErrMalformedPublicKey is returned by ValidatePublicKey when a key is not a well-formed key of its type
+	ErrMalformedPublicKey = errors.New("malformed public key")
+	// ErrKeyTypeNotAllowed is returned by ValidatePublicKey when a key's type is unsupported or not allowed
+	ErrKeyTypeNotAllowed = errors.New("public key type not allowed")
+	// ErrKeySizeNotAllowed is returned by ValidatePublicKey when an RSA modulus is outside the allowed size range
+	ErrKeySizeNotAllowed = errors.New("public key size not allowed")
+	// ErrCurveNotAllowed is returned by ValidatePublicKey when an ECDSA key's curve is not allowed
+	ErrCurveNotAllowed = errors.New("elliptic curve not allowed")
+)
+
+// KeyConstraints restricts the public keys accepted by ValidatePublicKey. Zero-valued fields
+// impose no restriction.
+type KeyConstraints struct {
+	// AllowedTypes lists the accepted key types
+	AllowedTypes []KeyType
+	// MinRSABits and MaxRSABits bound the size of an RSA modulus, inclusive
+	MinRSABits int
+	MaxRSABits int
+	// AllowedCurves lists the accepted curves for ECDSA keys
+	AllowedCurves []elliptic.Curve
+}
+
+// ValidatePublicKey checks that pub is a well-formed RSA, ECDSA (P-256, P-384 or P-521) or
+// Ed25519 public key that satisfies constraints. Errors wrap ErrMalformedPublicKey, ErrKeyTypeNotAllowed,
+// ErrKeySizeNotAllowed or ErrCurveNotAllowed.
+//
+// Unlike ValidatePubKey, no fixed policy is applied beyond the supplied constraints.
+func ValidatePublicKey(pub crypto.PublicKey, constraints KeyConstraints) error {
+	var keyType KeyType
+	switch pk := pub.(type) {
+	case *rsa.PublicKey:
+		keyType = KeyTypeRSA
+		if pk == nil || pk.N == nil || pk.N.Sign() <= 0 || pk.E < 3 || pk.E%2 == 0 {
+			return fmt.Errorf("%w: invalid RSA modulus or exponent", ErrMalformedPublicKey)
+		}
+	case *ecdsa.PublicKey:
+		keyType = KeyTypeECDSA
+		if pk == nil || pk.Curve == nil || pk.X == nil || pk.Y == nil {
+			return fmt.Errorf("%w: incomplete ECDSA key", ErrMalformedPublicKey)
+		}
+		switch pk.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return fmt.Errorf("%w: unsupported curve %s", ErrCurveNotAllowed, pk.Curve.Params().Name)
+		}
+		// checks that the point is on the curve and not the point at infinity
+		if _, err := pk.ECDH(); err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformedPublicKey, err)
+		}
+	case ed25519.PublicKey:
+		keyType = KeyTypeED25519
+		if len(pk) != ed25519.PublicKeySize {
+			return fmt.Errorf("%w: Ed25519 key must be %d bytes, got %d", ErrMalformedPublicKey, ed25519.PublicKeySize, len(pk))
+		}
+	default:
+		return fmt.Errorf("%w: unsupported key type %T", ErrKeyTypeNotAllowed, pub)
+	}
+
+	if len(constraints.AllowedTypes) > 0 && !slices.Contains(constraints.AllowedTypes, keyType) {
+		return fmt.Errorf("%w: %s", ErrKeyTypeNotAllowed, keyType)
+	}
+
+	switch pk := pub.(type) {
+	case *rsa.PublicKey:
+		bits := pk.N.BitLen()
+		if constraints.MinRSABits > 0 && bits < constraints.MinRSABits {
+			return fmt.Errorf("%w: RSA key is %d bits, minimum is %d", ErrKeySizeNotAllowed, bits, constraints.MinRSABits)
+		}
+		if constraints.MaxRSABits > 0 && bits > constraints.MaxRSABits {
+			return fmt.Errorf("%w: RSA key is %d bits, maximum is %d", ErrKeySizeNotAllowed, bits, constraints.MaxRSABits)
+		}
+	case *ecdsa.PublicKey:
+		if len(constraints.AllowedCurves) > 0 && !slices.Contains(constraints.AllowedCurves, pk.Curve) {
+			return fmt.Errorf("%w: %s", ErrCurveNotAllowed, pk.Curve.Params().Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptoutils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestValidatePublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offCurve := &ecdsa.PublicKey{Curve: elliptic.P256(), X: big.NewInt(1), Y: big.NewInt(1)}
+
+	tests := []struct {
+		name        string
+		pub         crypto.PublicKey
+		constraints KeyConstraints
+		wantErr     error
+	}{
+		{"rsa unconstrained", &rsaKey.PublicKey, KeyConstraints{}, nil},
+		{"ecdsa unconstrained", &p256.PublicKey, KeyConstraints{}, nil},
+		{"ed25519 unconstrained", edPub, KeyConstraints{}, nil},
+		{"type allowed", &p256.PublicKey, KeyConstraints{AllowedTypes: []KeyType{KeyTypeECDSA}}, nil},
+		{"type not allowed", edPub, KeyConstraints{AllowedTypes: []KeyType{KeyTypeRSA, KeyTypeECDSA}}, ErrKeyTypeNotAllowed},
+		{"unsupported type", "not a key", KeyConstraints{}, ErrKeyTypeNotAllowed},
+		{"rsa size allowed", &rsaKey.PublicKey, KeyConstraints{MinRSABits: 2048, MaxRSABits: 4096}, nil},
+		{"rsa too small", &rsaKey.PublicKey, KeyConstraints{MinRSABits: 3072}, ErrKeySizeNotAllowed},
+		{"rsa too large", &rsaKey.PublicKey, KeyConstraints{MaxRSABits: 1024}, ErrKeySizeNotAllowed},
+		{"curve allowed", &p384.PublicKey, KeyConstraints{AllowedCurves: []elliptic.Curve{elliptic.P256(), elliptic.P384()}}, nil},
+		{"curve not allowed", &p384.PublicKey, KeyConstraints{AllowedCurves: []elliptic.Curve{elliptic.P256()}}, ErrCurveNotAllowed},
+		{"unsupported curve", &ecdsa.PublicKey{Curve: elliptic.P224(), X: big.NewInt(1), Y: big.NewInt(1)}, KeyConstraints{}, ErrCurveNotAllowed},
+		{"point not on curve", offCurve, KeyConstraints{}, ErrMalformedPublicKey},
+		{"rsa missing modulus", &rsa.PublicKey{E: 65537}, KeyConstraints{}, ErrMalformedPublicKey},
+		{"rsa even exponent", &rsa.PublicKey{N: rsaKey.N, E: 4}, KeyConstraints{}, ErrMalformedPublicKey},
+		{"ed25519 wrong length", ed25519.PublicKey(edPub[:16]), KeyConstraints{}, ErrMalformedPublicKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePublicKey(tt.pub, tt.constraints)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptoutils
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	// ErrIncorrectPKCS12Password is returned when a PKCS#12 bundle cannot be decrypted with the provided password
+	ErrIncorrectPKCS12Password = errors.New("incorrect PKCS#12 password")
+	// ErrMalformedPKCS12 is returned when a PKCS#12 bundle cannot be parsed
+	ErrMalformedPKCS12 = errors.New("malformed PKCS#12 bundle")
+)
+
+// LoadPKCS12File reads a PKCS#12 (.p12/.pfx) file and returns its contents as described in UnmarshalPKCS12.
+func LoadPKCS12File(path string, pf PassFunc) (crypto.PrivateKey, *x509.Certificate, []*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("reading PKCS#12 file: %w", err)
+	}
+	return UnmarshalPKCS12(data, pf)
+}
+
+// UnmarshalPKCS12 decodes a PKCS#12 bundle containing a single private key, returning the key,
+// the certificate for that key, and any remaining certificates in the bundle as its chain. pf
+// supplies the bundle password; a nil pf or nil password is treated as an empty password.
+// Errors wrap ErrIncorrectPKCS12Password or ErrMalformedPKCS12 as appropriate.
+func UnmarshalPKCS12(data []byte, pf PassFunc) (crypto.PrivateKey, *x509.Certificate, []*x509.Certificate, error) {
+	var password []byte
+	if pf != nil {
+		var err error
+		if password, err = pf(false); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	priv, leaf, rest, err := pkcs12.DecodeChain(data, string(password))
+	switch {
+	case errors.Is(err, pkcs12.ErrIncorrectPassword):
+		// only returned when the bundle's MAC does not match the password
+		return nil, nil, nil, ErrIncorrectPKCS12Password
+	case err != nil:
+		return nil, nil, nil, fmt.Errorf("%w: %w", ErrMalformedPKCS12, err)
+	}
+
+	// the leaf is not necessarily the first certificate in the bundle
+	certs := append([]*x509.Certificate{leaf}, rest...)
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("%w: unsupported private key type %T", ErrMalformedPKCS12, priv)
+	}
+	for i, cert := range certs {
+		if EqualKeys(cert.PublicKey, signer.Public()) == nil {
+			chain := append(certs[:i:i], certs[i+1:]...)
+			return priv, cert, chain, nil
+		}
+	}
+	return nil, nil, nil, fmt.Errorf("%w: no certificate found for private key", ErrMalformedPKCS12)
+}
@@ -0,0 +1,136 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptoutils
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testPKCS12 is an ECDSA P-256 key with a leaf certificate (CN=test-leaf) issued by a CA
+// (CN=test-ca), exported with "openssl pkcs12 -export -legacy" and the password "hunter2".
+const testPKCS12 = `
+MIIE0gIBAzCCBJgGCSqGSIb3DQEHAaCCBIkEggSFMIIEgTCCA3cGCSqGSIb3DQEHBqCCA2gwggNk
+AgEAMIIDXQYJKoZIhvcNAQcBMBwGCiqGSIb3DQEMAQYwDgQIudxUmN2ULFECAggAgIIDMAkRB1G/
+RlA6+h9kH6o0ci5w1GWSN18G3dokxKZucyyvsAqnq4M7FeL8NB0E0gqijjTAZLzH5HKM8zv/9CEO
+ZQxooNNmY7LDxGr5OKUoGVSauU9YmX37e0Zh1YKDQlP579B4tLEtL89UMp061MSEfqptmWXRDQ7R
+COp+WesVjaGbmJfR9Py/y10cP1tld63NwKJgVSJECxJ9GBUCGwydy8DTXqahtuoJlQYg+y5Rhm2B
+XD94U5jHUUDmDHgjGa5VJMNDOpX0wxw0XQrQWgkTnOgsu8b9B9JwHzru49K6cvKrp9OwnHaCGzvd
+NB4/tdiwE3I8h2XTAC8rS7Ds2G66Phgt+UXG7bfXf2DH6hM3F+ZMD8NMcYRSuSbJ6S/1r7ohZt1l
+MaTQcE7Avc5kpJcwSqZHdum1EMC7yXuCCjFVC3GWwzBa7TCmzLQYwvPtyhYM631cC0qWPEP9ndDw
+xh6r2kVkXbBzMo1l6I8EiJudiEGSc6IsxbQhd/wprw1og7VfP8xqCEg562OzRxLq16ktm6TDcQ1J
+xo4VWcUwM7niauU6PBf2BTPwQ+fx6fY65cKY3AKzrHxpDlg9FMqz0yzm7jUrdDYQys6x1EEzIYX9
+8SN52GBHXEhZCS1t4xdMm8BQLZIfYnDaL9H+1A8elEpAOlz/78EltUFVVTZOUJipTtTsPPf0sSUP
+Y/qAFgL7A5HPbfeQgMQ9ax6tiPVafMmx1H1U4kZBc/Fuo9KcJOP1QHqZw+vfACXvHKo+wRh2+VjA
+euMrQwAdN7PgrLc8PhvU08yIvFFlsA2o9W3xEHnhdozQBIBSWTLRECQMP6zucFljc6c1G7XHJEWi
+uyz2ACWpGLknBMph9G+5XBcPmaPmWsA+kHEQjQr4srx75hrst6rjaA+hyOPf04vPPh6YwyV0HJBY
+a1AaFDB2I4ttrCOYY5Q/AMIQeQ+jH3DMnZENJych4+6Hr1veEKw22z2CS3r3wqgOYFAc3Y+6Qgm0
+SHPxJVEqJYVtjQBYY0nmNjl7fwRCQVIdYua48xdQcy1c442B22RDEKB/5aIdf9jTa0qxa5QfyhzE
+JOPUb/FPJN+1scs0zTCCAQIGCSqGSIb3DQEHAaCB9ASB8TCB7jCB6wYLKoZIhvcNAQwKAQKggbQw
+gbEwHAYKKoZIhvcNAQwBAzAOBAg8SzI05u7OqAICCAAEgZCOlAWkNjG7boLWAev4OIJXCRHP5c6c
+nUxVSUHJx19CGal8uXokC7I8maDHL/1wyzdnCUn0zj55n2c1Dn5EUvatNm68PvNj9hl6ufvroNGW
+WI/CDJr604SA5DUrAuuZ9FJhg6vEdeeLkRbub08WGtMtbFiQVtUef7EU4L1Pz5sGUDN6e/5DWK25
+Nt+uZxUQZFYxJTAjBgkqhkiG9w0BCRUxFgQU7LbQ1m2nXsl7B5QLyQ2lIL86L4QwMTAhMAkGBSsO
+AwIaBQAEFELNy6EZvFSmnzbungcL7K5HdLy9BAh8C5i3lSNZmAICCAA=`
+
+// testPKCS12AES is a bundle with the same structure as testPKCS12, exported with OpenSSL 3's
+// default "openssl pkcs12 -export" (PBES2 with AES-256-CBC, and a SHA-256 MAC) and the password
+// "hunter2".
+const testPKCS12AES = `
+MIIFXAIBAzCCBRIGCSqGSIb3DQEHAaCCBQMEggT/MIIE+zCCA7IGCSqGSIb3DQEHBqCCA6MwggOf
+AgEAMIIDmAYJKoZIhvcNAQcBMFcGCSqGSIb3DQEFDTBKMCkGCSqGSIb3DQEFDDAcBAgl5dTHQmoL
+igICCAAwDAYIKoZIhvcNAgkFADAdBglghkgBZQMEASoEEGup9iJ7I5FePL6VR0gCKQWAggMw6Uiy
+yG0ymcXAhbCRvmtGZ2sQ/U9FokZ/ZNZXXKt3mLaKtQh4mIELAwB1f5oNWx6Td3EBp4d3bb6pvc2/
+FJSB3TyWxzHh4PwrkqQIqAPkCpA+ewIQuQU08u9aTED6JmdcO9Y+ooTqXRpfJtTvfbetiOPTSUgs
+5DaEkEz2ZhXfGYDS4IPgcQLlsXr0A/b+ra15H01wgS9bS/8/EdRPlPpDPkbbnLzVs9q6s1tuMs9f
+73je4FbSVbcb26wHOWIrfR42d9kEBe4C0QkJEsBxdDEpYX+Jqj4vMauM7H562KCoU+eBNrDYSWQ3
+ZtS1JqvaTmCID8ABBGVRVTSaDxGnnINiNDSUcP7s45ItlKJ/qSKhgF+Ai8cyg9D29MOYsulIsrww
+xhy8CnIvdAzRPwS+oONrZGEM5cat2+K9YVUTPBIDWQHbbrhI0hItsmVjgE19hrBYzUuf3nEk98sW
+CmjkUaxW7KHFESTkFeyb1gzc85M8MMwWDxyZXbt9L10QkMKUX19lkU42gqmv6/uUhG+CcpjcgIgf
+NFojo3LdQJfaLLPXMSJqGllGBt33oapI32eyfOjym5/Q7kWoOuGZMG874Vhmu1bZEOL/Z92giUo2
+E/cLabZYWMjz6ZOm4orzWkcxL0LIVTCL+3XBsWri+3aNJKD65ku9V7yhrfSTM8p5jnbvEwv3Edbt
+WuswsbaoSmtHXEKZkAQctW4aoLRkfuz/L7yS0q6d5xn8PhB8u5y0am0Rk4uGv60v+vlrj74ttQ34
+OTm1Hfu8qMwk90Q8oCgnovAG/TClFxHZCpsmAvMXJOABeaEMpvxkNjJ6d4FvrRioI4KqWSlaYut6
+s2vxjw4v2gmBFrix/OuCnfiL7T7LsSnXg+5K4UQRVjlv236kAen2b64fSntk0yFlLGcwgUDw/b66
+2PF3iOfzOCdJeXDUOUb8gHZrYABabtoKo942W4jHPWW+hAbyjWtY2FLzfS2VR2kdsPS98nhLCWc/
+0H3fouQNDlfloNGGC9ur2cbsXkk+S/gbFPh6Cx/kJ5JQKFsPNagU/7Bq8SgNQigmT2dj8hulM2fi
+waFhg6UI+SpxHH85JraUMIIBQQYJKoZIhvcNAQcBoIIBMgSCAS4wggEqMIIBJgYLKoZIhvcNAQwK
+AQKgge8wgewwVwYJKoZIhvcNAQUNMEowKQYJKoZIhvcNAQUMMBwECHcpY069bx3nAgIIADAMBggq
+hkiG9w0CCQUAMB0GCWCGSAFlAwQBKgQQuNo5N1LBaVcdgDgbD46bxgSBkOjua0XRt21SOT+tNN3T
+zNnnvylTdzjFoMb00sQkAHDgulsvclstTT0aMN2wHG5tcJamxcHPxoJkYqZdWrCNTUxsqvF6K892
+VX4pxs7etY4iw6or6pNCTwaOsu+ncZwSMd/qQSFOu50t8Xijn/4uQSbpAr/6ZJnWQNw9QohweZ56
+qceOX45QNJ9jVCeeIw+8YDElMCMGCSqGSIb3DQEJFTEWBBSqJVCj3yCA2wLOzkxdquHRXI2SqTBB
+MDEwDQYJYIZIAWUDBAIBBQAEICDo1WdP9hamH6HPRTlSSh8rhpI0b59oF0fTmlomFmPVBAi+pGse
+mOWWlAICCAA=`
+
+func TestUnmarshalPKCS12(t *testing.T) {
+	for name, bundle := range map[string]string{
+		"legacy": testPKCS12,
+		"aes":    testPKCS12AES,
+	} {
+		t.Run(name, func(t *testing.T) {
+			testUnmarshalPKCS12(t, bundle)
+		})
+	}
+}
+
+func testUnmarshalPKCS12(t *testing.T, bundle string) {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(bundle)
+	if err != nil {
+		t.Fatalf("decoding test bundle: %v", err)
+	}
+
+	priv, leaf, chain, err := UnmarshalPKCS12(data, StaticPasswordFunc([]byte("hunter2")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ecKey, ok := priv.(*ecdsa.PrivateKey)
+	if !ok {
+		t.Fatalf("expected *ecdsa.PrivateKey, got %T", priv)
+	}
+	if leaf.Subject.CommonName != "test-leaf" {
+		t.Errorf("unexpected leaf certificate %q", leaf.Subject.CommonName)
+	}
+	if err := EqualKeys(leaf.PublicKey, ecKey.Public()); err != nil {
+		t.Errorf("leaf certificate does not match private key: %v", err)
+	}
+	if len(chain) != 1 || chain[0].Subject.CommonName != "test-ca" {
+		t.Errorf("unexpected chain: %v", chain)
+	}
+
+	if _, _, _, err := UnmarshalPKCS12(data, StaticPasswordFunc([]byte("wrong"))); !errors.Is(err, ErrIncorrectPKCS12Password) {
+		t.Errorf("expected ErrIncorrectPKCS12Password, got %v", err)
+	}
+	if _, _, _, err := UnmarshalPKCS12(data[:len(data)/2], StaticPasswordFunc([]byte("hunter2"))); !errors.Is(err, ErrMalformedPKCS12) {
+		t.Errorf("expected ErrMalformedPKCS12 for truncated bundle, got %v", err)
+	}
+	if _, _, _, err := UnmarshalPKCS12([]byte("not a bundle"), nil); !errors.Is(err, ErrMalformedPKCS12) {
+		t.Errorf("expected ErrMalformedPKCS12, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.p12")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := LoadPKCS12File(path, StaticPasswordFunc([]byte("hunter2"))); err != nil {
+		t.Errorf("unexpected error loading file: %v", err)
+	}
+}
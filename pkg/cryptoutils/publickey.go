@@ -22,9 +22,11 @@ import (
 	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/sha1" // nolint:gosec
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/pem"
 	"errors"
@@ -97,6 +99,18 @@ func SKID(pub crypto.PublicKey) ([]byte, error) {
 	return skid[:], nil
 }
 
+// KeyFingerprint returns a stable identifier for a public key: the unpadded base64url encoding
+// of the SHA-256 hash of its DER-encoded SubjectPublicKeyInfo. This is the "kid" derivation
+// commonly used by JOSE and COSE tooling, and is the same regardless of how the key was serialized.
+func KeyFingerprint(pub crypto.PublicKey) (string, error) {
+	derPubBytes, err := MarshalPublicKeyToDER(pub)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(derPubBytes)
+	return base64.RawURLEncoding.EncodeToString(digest[:]), nil
+}
+
 // EqualKeys compares two public keys. Supports RSA, ECDSA and ED25519.
 // If not equal, the error message contains hex-encoded SHA1 hashes of the DER-encoded keys
 func EqualKeys(first, second crypto.PublicKey) error {
@@ -21,7 +21,9 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"errors"
 	"strings"
@@ -118,6 +120,42 @@ func TestSKIDED25519(t *testing.T) {
 	}
 }
 
+func TestKeyFingerprint(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey failed: %v", err)
+	}
+	fp, err := KeyFingerprint(priv.Public())
+	if err != nil {
+		t.Fatalf("KeyFingerprint failed: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey failed: %v", err)
+	}
+	digest := sha256.Sum256(der)
+	if expected := base64.RawURLEncoding.EncodeToString(digest[:]); fp != expected {
+		t.Errorf("KeyFingerprint() = %q, expected %q", fp, expected)
+	}
+
+	// the fingerprint does not depend on how the key was serialized
+	pemBytes, err := MarshalPublicKeyToPEM(priv.Public())
+	if err != nil {
+		t.Fatalf("MarshalPublicKeyToPEM failed: %v", err)
+	}
+	rtPub, err := UnmarshalPEMToPublicKey(pemBytes)
+	if err != nil {
+		t.Fatalf("UnmarshalPEMToPublicKey failed: %v", err)
+	}
+	if rtFP, err := KeyFingerprint(rtPub); err != nil || rtFP != fp {
+		t.Errorf("KeyFingerprint() of round-tripped key = %q (%v), expected %q", rtFP, err, fp)
+	}
+
+	if _, err := KeyFingerprint(nil); err == nil {
+		t.Error("expected error for nil key")
+	}
+}
+
 func TestEqualKeys(t *testing.T) {
 	// Test RSA (success and failure)
 	privRsa, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -0,0 +1,151 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptoutils
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// OIDIssuer is the OID of the deprecated Fulcio OIDC issuer extension, whose value is the raw issuer URL
+	OIDIssuer = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	// OIDIssuerV2 is the OID of the Fulcio OIDC issuer extension, whose value is a DER-encoded UTF8String
+	OIDIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// RootPolicy pairs a trusted root certificate with the policy that leaf certificates
+// chaining to that root must satisfy
+type RootPolicy struct {
+	Root *x509.Certificate
+	// Policy is applied to the leaf certificate of chains that build to Root. A nil
+	// Policy rejects all such chains.
+	Policy func(leaf *x509.Certificate) error
+}
+
+// MultiRootVerifier verifies certificate chains against several trusted roots, applying
+// the policy of whichever root a chain builds to
+type MultiRootVerifier struct {
+	roots    *x509.CertPool
+	policies []RootPolicy
+}
+
+// NewMultiRootVerifier returns a MultiRootVerifier trusting the roots in policies
+func NewMultiRootVerifier(policies ...RootPolicy) (*MultiRootVerifier, error) {
+	if len(policies) == 0 {
+		return nil, errors.New("at least one root policy must be provided")
+	}
+	roots := x509.NewCertPool()
+	for i, p := range policies {
+		if p.Root == nil {
+			return nil, fmt.Errorf("root policy %d has no root certificate", i)
+		}
+		roots.AddCert(p.Root)
+	}
+	return &MultiRootVerifier{
+		roots:    roots,
+		policies: policies,
+	}, nil
+}
+
+// Verify builds chains from leaf to the trusted roots, using opts for everything other
+// than the roots, and returns the chains whose root's policy accepts leaf. An error is
+// returned if no chain can be built, or if no policy accepts leaf.
+//
+// As with x509.Certificate.Verify, opts.KeyUsages defaults to server authentication, so
+// it must be set to x509.ExtKeyUsageCodeSigning to verify Fulcio-issued certificates.
+func (m *MultiRootVerifier) Verify(leaf *x509.Certificate, opts x509.VerifyOptions) ([][]*x509.Certificate, error) {
+	if leaf == nil {
+		return nil, errors.New("certificate cannot be nil")
+	}
+	opts.Roots = m.roots
+	chains, err := leaf.Verify(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var accepted [][]*x509.Certificate
+	var errs []error
+	for _, chain := range chains {
+		root := chain[len(chain)-1]
+		policy, ok := m.policyFor(root)
+		switch {
+		case !ok || policy == nil:
+			errs = append(errs, fmt.Errorf("no policy for root %q", root.Subject))
+		default:
+			if err := policy(leaf); err != nil {
+				errs = append(errs, fmt.Errorf("rejected by policy for root %q: %w", root.Subject, err))
+				continue
+			}
+			accepted = append(accepted, chain)
+		}
+	}
+	if len(accepted) == 0 {
+		return nil, fmt.Errorf("certificate not accepted by any root policy: %w", errors.Join(errs...))
+	}
+	return accepted, nil
+}
+
+func (m *MultiRootVerifier) policyFor(root *x509.Certificate) (func(*x509.Certificate) error, bool) {
+	for _, p := range m.policies {
+		if p.Root.Equal(root) {
+			return p.Policy, true
+		}
+	}
+	return nil, false
+}
+
+// GetOIDCIssuer returns the OIDC issuer recorded in a Fulcio-issued certificate, or an
+// empty string if the certificate has no issuer extension
+func GetOIDCIssuer(cert *x509.Certificate) (string, error) {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(OIDIssuerV2) {
+			var issuer string
+			rest, err := asn1.Unmarshal(ext.Value, &issuer)
+			if err != nil {
+				return "", fmt.Errorf("parsing OIDC issuer extension: %w", err)
+			}
+			if len(rest) != 0 {
+				return "", errors.New("trailing data after OIDC issuer extension")
+			}
+			return issuer, nil
+		}
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(OIDIssuer) {
+			return string(ext.Value), nil
+		}
+	}
+	return "", nil
+}
+
+// AllowedIssuersPolicy returns a RootPolicy policy that accepts certificates issued for
+// one of the given OIDC issuers
+func AllowedIssuersPolicy(issuers ...string) func(*x509.Certificate) error {
+	return func(leaf *x509.Certificate) error {
+		issuer, err := GetOIDCIssuer(leaf)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(issuers, issuer) {
+			return fmt.Errorf("OIDC issuer %q is not allowed", issuer)
+		}
+		return nil
+	}
+}
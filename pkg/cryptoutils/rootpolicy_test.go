@@ -0,0 +1,80 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptoutils
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/sigstore/sigstore/test"
+)
+
+func TestMultiRootVerifier(t *testing.T) {
+	publicRoot, publicKey, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateRoot, privateKey, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	untrustedRoot, untrustedKey, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewMultiRootVerifier(
+		RootPolicy{Root: publicRoot, Policy: AllowedIssuersPolicy("https://public.example.com")},
+		RootPolicy{Root: privateRoot},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating verifier: %v", err)
+	}
+	opts := x509.VerifyOptions{KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}}
+
+	tests := []struct {
+		name    string
+		issuer  string
+		root    *x509.Certificate
+		wantErr bool
+	}{
+		{name: "allowed issuer", issuer: "https://public.example.com", root: publicRoot},
+		{name: "disallowed issuer", issuer: "https://private.example.com", root: publicRoot, wantErr: true},
+		{name: "root without policy", issuer: "https://public.example.com", root: privateRoot, wantErr: true},
+		{name: "untrusted root", issuer: "https://public.example.com", root: untrustedRoot, wantErr: true},
+	}
+	keys := map[*x509.Certificate]*ecdsa.PrivateKey{publicRoot: publicKey, privateRoot: privateKey, untrustedRoot: untrustedKey}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaf, _, err := test.GenerateLeafCert("subject@example.com", tt.issuer, tt.root, keys[tt.root])
+			if err != nil {
+				t.Fatal(err)
+			}
+			chains, err := v.Verify(leaf, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !chains[0][len(chains[0])-1].Equal(tt.root) {
+				t.Errorf("expected chain to build to the expected root")
+			}
+		})
+	}
+
+	if _, err := NewMultiRootVerifier(); err == nil {
+		t.Error("expected error creating verifier without roots")
+	}
+}
@@ -77,8 +77,10 @@ func (d *DefaultFlowClientCredentials) CodeURL() (string, error) {
 
 // DefaultFlowClientCredentials fetches an OIDC Identity token using the Client Credentials Grant flow as specified in RFC8628
 type DefaultFlowClientCredentials struct {
-	Issuer  string
-	codeURL string
+	Issuer string
+	// Audience, if set, is sent as the audience parameter in the token request
+	Audience string
+	codeURL  string
 }
 
 // NewClientCredentialsFlow creates a new DefaultFlowClientCredentials that retrieves an OIDC Identity Token using a Client Credentials Grant
@@ -88,17 +90,20 @@ func NewClientCredentialsFlow(issuer string) *DefaultFlowClientCredentials {
 	}
 }
 
-func (d *DefaultFlowClientCredentials) clientCredentialsFlow(_ *oidc.Provider, clientID, clientSecret, redirectURL string) (string, error) {
+func (d *DefaultFlowClientCredentials) clientCredentialsFlow(_ *oidc.Provider, clientID, clientSecret, redirectURL string, scopes []string) (string, error) {
 	data := url.Values{
 		"client_id":     []string{clientID},
 		"client_secret": []string{clientSecret},
-		"scope":         []string{"openid email"},
+		"scope":         []string{strings.Join(scopesOrDefault(scopes), " ")},
 		"grant_type":    []string{"client_credentials"},
 	}
 	if redirectURL != "" {
 		// If a redirect uri is provided then use it
 		data["redirect_uri"] = []string{redirectURL}
 	}
+	if d.Audience != "" {
+		data["audience"] = []string{d.Audience}
+	}
 
 	codeURL, err := d.CodeURL()
 	if err != nil {
@@ -134,7 +139,7 @@ func (d *DefaultFlowClientCredentials) clientCredentialsFlow(_ *oidc.Provider, c
 
 // GetIDToken gets an OIDC ID Token from the specified provider using the Client Credentials Grant flow
 func (d *DefaultFlowClientCredentials) GetIDToken(p *oidc.Provider, cfg oauth2.Config) (*OIDCIDToken, error) {
-	idToken, err := d.clientCredentialsFlow(p, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL)
+	idToken, err := d.clientCredentialsFlow(p, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes)
 	if err != nil {
 		return nil, err
 	}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -73,7 +74,7 @@ func TestClientCredentialsFlowTokenGetter_ccFlow(t *testing.T) {
 
 	tokenCh, errCh := make(chan string), make(chan error)
 	go func() {
-		token, err := dtg.clientCredentialsFlow(p, "sigstore", "", "")
+		token, err := dtg.clientCredentialsFlow(p, "sigstore", "", "", nil)
 		tokenCh <- token
 		errCh <- err
 	}()
@@ -89,3 +90,40 @@ func TestClientCredentialsFlowTokenGetter_ccFlow(t *testing.T) {
 		t.Fatal("expected mytoken")
 	}
 }
+
+func TestClientCredentialsFlowTokenGetter_scopesAndAudience(t *testing.T) {
+	var gotForm url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/openid-configuration" {
+			_, _ = w.Write([]byte(strings.ReplaceAll(wellKnownOIDCConfig, "ISSUER", fmt.Sprintf("http://%s", r.Host))))
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		gotForm = r.PostForm
+		b, _ := json.Marshal(tokenResponse("mytoken", ""))
+		_, _ = w.Write(b)
+	}))
+	defer ts.Close()
+
+	dtg := DefaultFlowClientCredentials{
+		Issuer:   ts.URL,
+		Audience: "sigstore-ca",
+	}
+	p, err := oidc.NewProvider(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := dtg.clientCredentialsFlow(p, "sigstore", "", "", []string{"openid", "email", "groups"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := gotForm.Get("scope"); got != "openid email groups" {
+		t.Errorf("expected custom scopes to be requested, got %q", got)
+	}
+	if got := gotForm.Get("audience"); got != "sigstore-ca" {
+		t.Errorf("expected audience to be requested, got %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauthflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ClientIDFile is the name of the file holding the client ID when credentials are read from a directory
+	ClientIDFile = "client_id"
+	// ClientSecretFile is the name of the file holding the client secret when credentials are read from a directory
+	ClientSecretFile = "client_secret"
+)
+
+// ClientCredentials holds the OIDC client ID and secret used to authenticate to an IdP.
+// The secret is redacted when the value is formatted, so that it is not accidentally logged.
+type ClientCredentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// String returns the client ID with the secret redacted
+func (c ClientCredentials) String() string {
+	return fmt.Sprintf("{ClientID:%s ClientSecret:REDACTED}", c.ClientID)
+}
+
+// GoString returns the client ID with the secret redacted
+func (c ClientCredentials) GoString() string {
+	return c.String()
+}
+
+// LoadClientCredentials reads OIDC client credentials from path. If path is a directory,
+// such as a mounted Kubernetes secret, the client ID and secret are read from the
+// ClientIDFile and ClientSecretFile files within it, either of which may be absent if
+// the value is empty. Otherwise path must be a JSON file with "client_id" and
+// "client_secret" fields.
+func LoadClientCredentials(path string) (*ClientCredentials, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading OIDC client credentials: %w", err)
+	}
+
+	creds := &ClientCredentials{}
+	if fi.IsDir() {
+		if creds.ClientID, err = readCredentialFile(filepath.Join(path, ClientIDFile)); err != nil {
+			return nil, err
+		}
+		if creds.ClientSecret, err = readCredentialFile(filepath.Join(path, ClientSecretFile)); err != nil {
+			return nil, err
+		}
+	} else {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading OIDC client credentials: %w", err)
+		}
+		if err := json.Unmarshal(b, creds); err != nil {
+			// don't wrap the error, as it may quote the file contents
+			return nil, fmt.Errorf("parsing OIDC client credentials from %s: invalid JSON", path)
+		}
+	}
+
+	if creds.ClientID == "" {
+		return nil, fmt.Errorf("no OIDC client ID found in %s", path)
+	}
+	return creds, nil
+}
+
+func readCredentialFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading OIDC client credentials: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// OIDConnectWithCredentialsFile requests an OIDC Identity Token like OIDConnect, reading the
+// client ID and secret from credentialsPath with LoadClientCredentials
+func OIDConnectWithCredentialsFile(issuer, credentialsPath, redirectURL string, tg TokenGetter, opts ...OIDConnectOption) (*OIDCIDToken, error) {
+	creds, err := LoadClientCredentials(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	return OIDConnect(issuer, creds.ClientID, creds.ClientSecret, redirectURL, tg, opts...)
+}
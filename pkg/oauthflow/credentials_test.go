@@ -0,0 +1,78 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauthflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadClientCredentials(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "creds.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"client_id":"sigstore","client_secret":"hunter2"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	creds, err := LoadClientCredentials(jsonPath)
+	if err != nil {
+		t.Fatalf("unexpected error loading JSON credentials: %v", err)
+	}
+	if creds.ClientID != "sigstore" || creds.ClientSecret != "hunter2" {
+		t.Errorf("unexpected credentials loaded from JSON: %s", creds)
+	}
+	if s := fmt.Sprintf("%v %+v %#v", creds, creds, *creds); strings.Contains(s, "hunter2") {
+		t.Errorf("client secret was not redacted: %s", s)
+	}
+
+	mountDir := filepath.Join(dir, "mount")
+	if err := os.Mkdir(mountDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mountDir, ClientIDFile), []byte("sigstore\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	creds, err = LoadClientCredentials(mountDir)
+	if err != nil {
+		t.Fatalf("unexpected error loading credentials from directory: %v", err)
+	}
+	if creds.ClientID != "sigstore" || creds.ClientSecret != "" {
+		t.Errorf("unexpected credentials loaded from directory: %s", creds)
+	}
+
+	if _, err := LoadClientCredentials(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error loading missing credentials file")
+	}
+
+	badPath := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(badPath, []byte("hunter2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadClientCredentials(badPath); err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("expected error without file contents, got %v", err)
+	}
+
+	emptyPath := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(emptyPath, []byte(`{}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadClientCredentials(emptyPath); err == nil {
+		t.Error("expected error loading credentials without a client ID")
+	}
+}
@@ -51,8 +51,9 @@ type deviceResp struct {
 }
 
 type tokenResp struct {
-	IDToken string `json:"id_token"`
-	Error   string `json:"error"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token"`
+	Error        string `json:"error"`
 }
 
 // DeviceFlowTokenGetter fetches an OIDC Identity token using the Device Code Grant flow as specified in RFC8628
@@ -60,7 +61,9 @@ type DeviceFlowTokenGetter struct {
 	MessagePrinter func(string)
 	Sleeper        func(time.Duration)
 	Issuer         string
-	codeURL        string
+	// Audience, if set, is sent as the audience parameter in the device code and token requests
+	Audience string
+	codeURL  string
 }
 
 // NewDeviceFlowTokenGetter creates a new DeviceFlowTokenGetter that retrieves an OIDC Identity Token using a Device Code Grant
@@ -83,16 +86,16 @@ func NewDeviceFlowTokenGetterForIssuer(issuer string) *DeviceFlowTokenGetter {
 	}
 }
 
-func (d *DeviceFlowTokenGetter) deviceFlow(p *oidc.Provider, clientID, redirectURL string) (string, error) {
+func (d *DeviceFlowTokenGetter) deviceFlow(p *oidc.Provider, clientID, redirectURL string, scopes []string) (*tokenResp, error) {
 	// require that OIDC provider support PKCE to provide sufficient security for the CLI
 	pkce, err := NewPKCE(p)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	data := url.Values{
 		"client_id":             []string{clientID},
-		"scope":                 []string{"openid email"},
+		"scope":                 []string{strings.Join(scopesOrDefault(scopes), " ")},
 		"code_challenge_method": []string{pkce.Method},
 		"code_challenge":        []string{pkce.Challenge},
 	}
@@ -100,29 +103,32 @@ func (d *DeviceFlowTokenGetter) deviceFlow(p *oidc.Provider, clientID, redirectU
 		// If a redirect uri is provided then use it
 		data["redirect_uri"] = []string{redirectURL}
 	}
+	if d.Audience != "" {
+		data["audience"] = []string{d.Audience}
+	}
 
 	codeURL, err := d.CodeURL()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	/* #nosec */
 	resp, err := http.PostForm(codeURL, data)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("%s: %s", resp.Status, b)
+		return nil, fmt.Errorf("%s: %s", resp.Status, b)
 	}
 
 	parsed := deviceResp{}
 	if err := json.Unmarshal(b, &parsed); err != nil {
-		return "", err
+		return nil, err
 	}
 	uri := parsed.VerificationURIComplete
 	if uri == "" {
@@ -136,52 +142,55 @@ func (d *DeviceFlowTokenGetter) deviceFlow(p *oidc.Provider, clientID, redirectU
 		data := url.Values{
 			"grant_type":    []string{"urn:ietf:params:oauth:grant-type:device_code"},
 			"device_code":   []string{parsed.DeviceCode},
-			"scope":         []string{"openid", "email"},
+			"scope":         scopesOrDefault(scopes),
 			"code_verifier": []string{pkce.Value},
 		}
+		if d.Audience != "" {
+			data["audience"] = []string{d.Audience}
+		}
 
 		/* #nosec */
 		resp, err := http.PostForm(p.Endpoint().TokenURL, data)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		defer resp.Body.Close()
 
 		b, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		tr := tokenResp{}
 		if err := json.Unmarshal(b, &tr); err != nil {
-			return "", err
+			return nil, err
 		}
 
 		if tr.IDToken != "" {
 			d.MessagePrinter("Token received!")
-			return tr.IDToken, nil
+			return &tr, nil
 		}
 		switch tr.Error {
 		case "access_denied", "expired_token":
-			return "", fmt.Errorf("error obtaining token: %s", tr.Error)
+			return nil, fmt.Errorf("error obtaining token: %s", tr.Error)
 		case "authorization_pending":
 			d.Sleeper(time.Duration(parsed.Interval) * time.Second)
 		case "slow_down":
 			// Add ten seconds if we got told to slow down
 			d.Sleeper(time.Duration(parsed.Interval)*time.Second + 10*time.Second)
 		default:
-			return "", fmt.Errorf("unexpected error in device flow: %s", tr.Error)
+			return nil, fmt.Errorf("unexpected error in device flow: %s", tr.Error)
 		}
 	}
 }
 
 // GetIDToken gets an OIDC ID Token from the specified provider using the device code grant flow
 func (d *DeviceFlowTokenGetter) GetIDToken(p *oidc.Provider, cfg oauth2.Config) (*OIDCIDToken, error) {
-	idToken, err := d.deviceFlow(p, cfg.ClientID, cfg.RedirectURL)
+	tr, err := d.deviceFlow(p, cfg.ClientID, cfg.RedirectURL, cfg.Scopes)
 	if err != nil {
 		return nil, err
 	}
 	verifier := p.Verifier(&oidc.Config{ClientID: cfg.ClientID})
-	parsedIDToken, err := verifier.Verify(context.Background(), idToken)
+	parsedIDToken, err := verifier.Verify(context.Background(), tr.IDToken)
 	if err != nil {
 		return nil, err
 	}
@@ -192,8 +201,9 @@ func (d *DeviceFlowTokenGetter) GetIDToken(p *oidc.Provider, cfg oauth2.Config)
 	}
 
 	return &OIDCIDToken{
-		RawString: idToken,
-		Subject:   subj,
+		RawString:    tr.IDToken,
+		Subject:      subj,
+		RefreshToken: tr.RefreshToken,
 	}, nil
 }
 
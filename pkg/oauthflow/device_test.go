@@ -133,7 +133,11 @@ func TestDeviceFlowTokenGetter_deviceFlow(t *testing.T) {
 
 	tokenCh, errCh := make(chan string), make(chan error)
 	go func() {
-		token, err := dtg.deviceFlow(p, "sigstore", "")
+		tr, err := dtg.deviceFlow(p, "sigstore", "", nil)
+		token := ""
+		if tr != nil {
+			token = tr.IDToken
+		}
 		tokenCh <- token
 		errCh <- err
 	}()
@@ -19,7 +19,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"regexp"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/go-jose/go-jose/v3"
@@ -43,8 +45,9 @@ type TokenGetter interface {
 
 // OIDCIDToken represents an OIDC Identity Token
 type OIDCIDToken struct {
-	RawString string // RawString provides the raw token (a base64-encoded JWT) value
-	Subject   string // Subject is the extracted subject from the raw token
+	RawString    string // RawString provides the raw token (a base64-encoded JWT) value
+	Subject      string // Subject is the extracted subject from the raw token
+	RefreshToken string // RefreshToken is the refresh token issued with the ID token, if any
 }
 
 // init
@@ -58,6 +61,12 @@ func init() {
 	}
 }
 
+// AudienceOpt requests the value of aud as the audience of the issued token (either on URL or in form body);
+// this is required by some IdPs for the token to be accepted by a CA
+func AudienceOpt(aud string) oauth2.AuthCodeOption {
+	return oauth2.SetAuthURLParam("audience", aud)
+}
+
 // ConnectorIDOpt requests the value of prov as a the connector_id (either on URL or in form body) on the initial request;
 // this is used by Dex
 func ConnectorIDOpt(prov string) oauth2.AuthCodeOption {
@@ -91,13 +100,86 @@ var PublicInstanceMicrosoftIDTokenGetter = &InteractiveIDTokenGetter{
 	ExtraAuthURLParams: []oauth2.AuthCodeOption{ConnectorIDOpt(PublicInstanceMicrosoftAuthSubURL)},
 }
 
+// defaultScopes are requested by every flow unless the oauth2.Config specifies its own scopes
+var defaultScopes = []string{oidc.ScopeOpenID, "email"}
+
+// OIDConnectOption configures optional behaviour of OIDConnect
+type OIDConnectOption func(*oidConnectOptions)
+
+type oidConnectOptions struct {
+	extraScopes            []string
+	expectedIssuer         string
+	subjectMatcher         *regexp.Regexp
+	tokenCache             *tokenCache
+	tokenCacheErrorHandler func(error)
+}
+
+// WithScopes requests the given scopes in addition to the default "openid" and "email" scopes
+func WithScopes(scopes ...string) OIDConnectOption {
+	return func(o *oidConnectOptions) {
+		o.extraScopes = append(o.extraScopes, scopes...)
+	}
+}
+
+// WithExpectedIssuer requires the "iss" claim of the returned token to equal issuer
+func WithExpectedIssuer(issuer string) OIDConnectOption {
+	return func(o *oidConnectOptions) {
+		o.expectedIssuer = issuer
+	}
+}
+
+// WithSubjectMatcher requires the subject of the returned token (its verified email, or
+// its "sub" claim if it has no email) to match re. Anchor the expression to require an
+// exact match.
+func WithSubjectMatcher(re *regexp.Regexp) OIDConnectOption {
+	return func(o *oidConnectOptions) {
+		o.subjectMatcher = re
+	}
+}
+
+// ClaimMismatchError is returned by OIDConnect when the token does not have the issuer or
+// subject required by WithExpectedIssuer or WithSubjectMatcher
+type ClaimMismatchError struct {
+	// Claim is the name of the mismatched claim, either "iss" or "sub"
+	Claim string
+	// Expected is the expected issuer, or the subject pattern
+	Expected string
+	// Actual is the value found in the token
+	Actual string
+}
+
+func (e *ClaimMismatchError) Error() string {
+	return fmt.Sprintf("oidc: token %s claim %q does not match expected %q", e.Claim, e.Actual, e.Expected)
+}
+
 // OIDConnect requests an OIDC Identity Token from the specified issuer using the specified client credentials and TokenGetter
 // NOTE: If the redirectURL is empty a listener on localhost:0 is configured with '/auth/callback' as default path.
-func OIDConnect(issuer, id, secret, redirectURL string, tg TokenGetter) (*OIDCIDToken, error) {
+func OIDConnect(issuer, id, secret, redirectURL string, tg TokenGetter, opts ...OIDConnectOption) (*OIDCIDToken, error) {
+	o := &oidConnectOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	// Check if it's a StaticTokenGetter since NewProvider below will make
 	// network calls unnecessarily and they are ignored.
 	if sg, ok := tg.(*StaticTokenGetter); ok {
-		return sg.GetIDToken(nil, oauth2.Config{})
+		tok, err := sg.GetIDToken(nil, oauth2.Config{})
+		if err != nil {
+			return nil, err
+		}
+		if err := o.validate(tok); err != nil {
+			return nil, err
+		}
+		return tok, nil
+	}
+	scopes := append(append([]string{}, defaultScopes...), o.extraScopes...)
+	var cacheKey, refreshToken string
+	if o.tokenCache != nil {
+		cacheKey = tokenCacheKey(issuer, id, scopes, tokenAudience(tg), tokenAuthParams(tg))
+		var tok *OIDCIDToken
+		if tok, refreshToken = o.tokenCache.get(cacheKey); tok != nil && o.validate(tok) == nil {
+			return tok, nil
+		}
 	}
 	provider, err := oidc.NewProvider(context.Background(), issuer)
 	if err != nil {
@@ -107,17 +189,66 @@ func OIDConnect(issuer, id, secret, redirectURL string, tg TokenGetter) (*OIDCID
 		ClientID:     id,
 		ClientSecret: secret,
 		Endpoint:     provider.Endpoint(),
-		Scopes:       []string{oidc.ScopeOpenID, "email"},
+		Scopes:       scopes,
 		RedirectURL:  redirectURL,
 	}
 
-	return tg.GetIDToken(provider, config)
+	var tok *OIDCIDToken
+	if refreshToken != "" {
+		if tok, err = refreshIDToken(provider, config, refreshToken); err != nil || o.validate(tok) != nil {
+			// fall back to a new flow, which replaces the stale entry
+			o.tokenCache.remove(cacheKey)
+			tok = nil
+		}
+	}
+	if tok == nil {
+		if tok, err = tg.GetIDToken(provider, config); err != nil {
+			return nil, err
+		}
+		// the token getter has already verified the token's signature and expiry
+		if err := o.validate(tok); err != nil {
+			return nil, err
+		}
+	}
+	if o.tokenCache != nil {
+		if err := o.tokenCache.put(cacheKey, tok); err != nil && o.tokenCacheErrorHandler != nil {
+			o.tokenCacheErrorHandler(fmt.Errorf("caching token: %w", err))
+		}
+	}
+	return tok, nil
+}
+
+// validate checks the token's issuer and subject against those required by the options
+func (o *oidConnectOptions) validate(tok *OIDCIDToken) error {
+	if o.expectedIssuer != "" {
+		c, err := unverifiedClaims(tok.RawString)
+		if err != nil {
+			return err
+		}
+		if c.Issuer != o.expectedIssuer {
+			return &ClaimMismatchError{Claim: "iss", Expected: o.expectedIssuer, Actual: c.Issuer}
+		}
+	}
+	if o.subjectMatcher != nil && !o.subjectMatcher.MatchString(tok.Subject) {
+		return &ClaimMismatchError{Claim: "sub", Expected: o.subjectMatcher.String(), Actual: tok.Subject}
+	}
+	return nil
+}
+
+// scopesOrDefault returns scopes, or the default scopes if none are given
+func scopesOrDefault(scopes []string) []string {
+	if len(scopes) == 0 {
+		return defaultScopes
+	}
+	return scopes
 }
 
 type claims struct {
-	Email    string `json:"email"`
-	Verified bool   `json:"email_verified"`
-	Subject  string `json:"sub"`
+	Issuer   string  `json:"iss"`
+	Email    string  `json:"email"`
+	Verified bool    `json:"email_verified"`
+	Subject  string  `json:"sub"`
+	Expiry   float64 `json:"exp"`
 }
 
 // SubjectFromToken extracts the subject claim from an OIDC Identity Token
@@ -150,21 +281,16 @@ type StaticTokenGetter struct {
 
 // GetIDToken extracts an OIDCIDToken from the raw token *without verification*
 func (stg *StaticTokenGetter) GetIDToken(_ *oidc.Provider, _ oauth2.Config) (*OIDCIDToken, error) {
-	unsafeTok, err := jose.ParseSigned(stg.RawToken)
-	if err != nil {
-		return nil, err
-	}
 	// THIS LOGIC IS GENERALLY UNSAFE BUT OK HERE
 	// We are only parsing the id-token passed directly to a command line tool by a user, so it is trusted locally.
 	// We need to extract the email address to attach an additional signed proof to the server.
 	// THE SERVER WILL DO REAL VERIFICATION HERE
-	unsafePayload := unsafeTok.UnsafePayloadWithoutVerification()
-	claims := claims{}
-	if err := json.Unmarshal(unsafePayload, &claims); err != nil {
+	claims, err := unverifiedClaims(stg.RawToken)
+	if err != nil {
 		return nil, err
 	}
 
-	subj, err := subjectFromClaims(claims)
+	subj, err := subjectFromClaims(*claims)
 	if err != nil {
 		return nil, err
 	}
@@ -174,3 +300,16 @@ func (stg *StaticTokenGetter) GetIDToken(_ *oidc.Provider, _ oauth2.Config) (*OI
 		Subject:   subj,
 	}, nil
 }
+
+// unverifiedClaims parses the claims of a raw token *without verification*
+func unverifiedClaims(rawToken string) (*claims, error) {
+	unsafeTok, err := jose.ParseSigned(rawToken)
+	if err != nil {
+		return nil, err
+	}
+	c := &claims{}
+	if err := json.Unmarshal(unsafeTok.UnsafePayloadWithoutVerification(), c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -20,10 +20,12 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/go-jose/go-jose/v3"
@@ -185,3 +187,79 @@ func TestStaticTokenGetter_GetIDToken(t *testing.T) {
 		})
 	}
 }
+
+func TestOIDConnectClaimValidation(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: priv}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(claims{
+		Issuer:   "https://issuer.example.com",
+		Email:    "foo@example.com",
+		Verified: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jws, err := signer.Sign(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jws.CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stg := &StaticTokenGetter{RawToken: token}
+
+	tests := []struct {
+		name      string
+		opts      []OIDConnectOption
+		wantClaim string
+	}{
+		{
+			name: "no validation",
+		},
+		{
+			name: "matching issuer and subject",
+			opts: []OIDConnectOption{
+				WithExpectedIssuer("https://issuer.example.com"),
+				WithSubjectMatcher(regexp.MustCompile(`^.*@example\.com$`)),
+			},
+		},
+		{
+			name:      "wrong issuer",
+			opts:      []OIDConnectOption{WithExpectedIssuer("https://other.example.com")},
+			wantClaim: "iss",
+		},
+		{
+			name:      "wrong subject",
+			opts:      []OIDConnectOption{WithSubjectMatcher(regexp.MustCompile(`^.*@other\.com$`))},
+			wantClaim: "sub",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, err := OIDConnect("", "", "", "", stg, tt.opts...)
+			if tt.wantClaim == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if tok != nil {
+				t.Error("expected no token when validation fails")
+			}
+			var mismatch *ClaimMismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("expected ClaimMismatchError, got %v", err)
+			}
+			if mismatch.Claim != tt.wantClaim {
+				t.Errorf("expected mismatch on %s claim, got %s", tt.wantClaim, mismatch.Claim)
+			}
+		})
+	}
+}
@@ -42,6 +42,8 @@ type InteractiveIDTokenGetter struct {
 	ExtraAuthURLParams []oauth2.AuthCodeOption
 	Input              io.Reader
 	Output             io.Writer
+	// Audience, if set, is sent as the audience parameter in the authorization and token requests
+	Audience string
 }
 
 // GetIDToken gets an OIDC ID Token from the specified provider using an interactive browser session
@@ -75,6 +77,9 @@ func (i *InteractiveIDTokenGetter) GetIDToken(p *oidc.Provider, cfg oauth2.Confi
 	if len(i.ExtraAuthURLParams) > 0 {
 		opts = append(opts, i.ExtraAuthURLParams...)
 	}
+	if i.Audience != "" {
+		opts = append(opts, AudienceOpt(i.Audience))
+	}
 	authCodeURL := cfg.AuthCodeURL(stateToken, opts...)
 	var code string
 	if err := browserOpener(authCodeURL); err != nil {
@@ -89,7 +94,11 @@ func (i *InteractiveIDTokenGetter) GetIDToken(p *oidc.Provider, cfg oauth2.Confi
 			code = i.doOobFlow(&cfg, stateToken, opts)
 		}
 	}
-	token, err := cfg.Exchange(context.Background(), code, append(pkce.TokenURLOpts(), oidc.Nonce(nonce))...)
+	exchangeOpts := append(pkce.TokenURLOpts(), oidc.Nonce(nonce))
+	if i.Audience != "" {
+		exchangeOpts = append(exchangeOpts, AudienceOpt(i.Audience))
+	}
+	token, err := cfg.Exchange(context.Background(), code, exchangeOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -121,8 +130,9 @@ func (i *InteractiveIDTokenGetter) GetIDToken(p *oidc.Provider, cfg oauth2.Confi
 	}
 
 	returnToken := OIDCIDToken{
-		RawString: idToken,
-		Subject:   email,
+		RawString:    idToken,
+		Subject:      email,
+		RefreshToken: token.RefreshToken,
 	}
 	return &returnToken, nil
 }
@@ -0,0 +1,256 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauthflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// tokenCacheExpiryLeeway is how long before its expiry a cached token stops being reused,
+// so that it is not rejected by a server shortly after being returned
+const tokenCacheExpiryLeeway = time.Minute
+
+// tokenCache stores ID tokens on disk, one file per issuer, client ID, scopes, audience and
+// extra authorization URL parameters
+type tokenCache struct {
+	dir string
+}
+
+type cachedToken struct {
+	RawToken     string    `json:"raw_token"`
+	Subject      string    `json:"subject"`
+	Expiry       time.Time `json:"expiry"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+}
+
+// WithTokenCache caches ID tokens obtained by OIDConnect in dir, keyed by issuer, client ID,
+// requested scopes, audience and extra authorization URL parameters (such as the connector ID
+// selecting an identity provider), and reuses a cached token until it expires. If the identity
+// provider issued a refresh token with the ID token, it is cached too and used to obtain a new
+// ID token once the cached one expires. If dir is empty, a "sigstore/oauthflow" directory under
+// os.UserCacheDir is used. Cache files are only readable by the current user. Corrupt or
+// expired entries are discarded.
+func WithTokenCache(dir string) OIDConnectOption {
+	return func(o *oidConnectOptions) {
+		o.tokenCache = &tokenCache{dir: dir}
+	}
+}
+
+// WithoutTokenCache disables a token cache enabled by an earlier WithTokenCache option
+func WithoutTokenCache() OIDConnectOption {
+	return func(o *oidConnectOptions) {
+		o.tokenCache = nil
+	}
+}
+
+// WithTokenCacheErrorHandler calls f with errors writing to the token cache, which are
+// otherwise ignored as they do not prevent a token from being returned
+func WithTokenCacheErrorHandler(f func(error)) OIDConnectOption {
+	return func(o *oidConnectOptions) {
+		o.tokenCacheErrorHandler = f
+	}
+}
+
+// tokenCacheKey returns the key of the cache entry for a token requested from issuer by
+// clientID with the given scopes, audience and encoded extra authorization URL parameters. The
+// scopes are sorted, so their order does not matter.
+func tokenCacheKey(issuer, clientID string, scopes []string, audience, authParams string) string {
+	sorted := append([]string{}, scopes...)
+	sort.Strings(sorted)
+	h := sha256.New()
+	for _, part := range append([]string{issuer, clientID, audience, authParams}, sorted...) {
+		// length-prefix each part so that distinct keys never hash the same bytes
+		_ = binary.Write(h, binary.BigEndian, uint64(len(part)))
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// tokenAudience returns the audience requested by the token getters in this package, or the
+// empty string
+func tokenAudience(tg TokenGetter) string {
+	switch g := tg.(type) {
+	case *InteractiveIDTokenGetter:
+		return g.Audience
+	case *DeviceFlowTokenGetter:
+		return g.Audience
+	case *DefaultFlowClientCredentials:
+		return g.Audience
+	}
+	return ""
+}
+
+// tokenAuthParams returns the extra authorization URL parameters requested by the token getters
+// in this package, URL-encoded in sorted order, or the empty string
+func tokenAuthParams(tg TokenGetter) string {
+	g, ok := tg.(*InteractiveIDTokenGetter)
+	if !ok || len(g.ExtraAuthURLParams) == 0 {
+		return ""
+	}
+	// the options are opaque, so apply them to an empty config to recover their values
+	raw := strings.TrimPrefix((&oauth2.Config{}).AuthCodeURL("", g.ExtraAuthURLParams...), "?")
+	q, err := url.ParseQuery(raw)
+	if err != nil {
+		return raw
+	}
+	q.Del("response_type")
+	q.Del("client_id")
+	return q.Encode()
+}
+
+func (c *tokenCache) path(key string) (string, error) {
+	dir := c.dir
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("finding token cache directory: %w", err)
+		}
+		dir = filepath.Join(userCacheDir, "sigstore", "oauthflow")
+	}
+	return filepath.Join(dir, key+".json"), nil
+}
+
+// get returns the cached token for key, or nil if there is no unexpired cached token. If the
+// cached token has expired, the refresh token cached with it (if any) is returned instead.
+func (c *tokenCache) get(key string) (*OIDCIDToken, string) {
+	path, err := c.path(key)
+	if err != nil {
+		return nil, ""
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ""
+	}
+	entry := cachedToken{}
+	if err := json.Unmarshal(b, &entry); err != nil || entry.RawToken == "" {
+		// discard corrupt entries
+		_ = os.Remove(path)
+		return nil, ""
+	}
+	if time.Now().Add(tokenCacheExpiryLeeway).After(entry.Expiry) {
+		if entry.RefreshToken == "" {
+			// discard expired entries that cannot be refreshed
+			_ = os.Remove(path)
+		}
+		return nil, entry.RefreshToken
+	}
+	return &OIDCIDToken{
+		RawString:    entry.RawToken,
+		Subject:      entry.Subject,
+		RefreshToken: entry.RefreshToken,
+	}, ""
+}
+
+// remove discards the cache entry for key
+func (c *tokenCache) remove(key string) {
+	if path, err := c.path(key); err == nil {
+		_ = os.Remove(path)
+	}
+}
+
+// put stores the token, and its refresh token if any, for key
+func (c *tokenCache) put(key string, tok *OIDCIDToken) error {
+	claims, err := unverifiedClaims(tok.RawString)
+	if err != nil {
+		return err
+	}
+	if claims.Expiry == 0 {
+		return errors.New("token has no expiry")
+	}
+	b, err := json.Marshal(cachedToken{
+		RawToken:     tok.RawString,
+		Subject:      tok.Subject,
+		Expiry:       time.Unix(int64(claims.Expiry), 0),
+		RefreshToken: tok.RefreshToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	path, err := c.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating token cache directory: %w", err)
+	}
+	// write to a temporary file first so that readers never see a partial entry
+	f, err := os.CreateTemp(filepath.Dir(path), ".token-*")
+	if err != nil {
+		return fmt.Errorf("creating token cache file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return fmt.Errorf("setting token cache file permissions: %w", err)
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("writing token cache file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing token cache file: %w", err)
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// refreshIDToken uses refreshToken to obtain a new ID token from provider, verifying it as the
+// token getters do
+func refreshIDToken(provider *oidc.Provider, config oauth2.Config, refreshToken string) (*OIDCIDToken, error) {
+	ctx := context.Background()
+	token, err := config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("refreshing token: %w", err)
+	}
+	idToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("id_token not present in refresh response")
+	}
+	parsedIDToken, err := provider.Verifier(&oidc.Config{ClientID: config.ClientID}).Verify(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+	if parsedIDToken.AccessTokenHash != "" {
+		if err := parsedIDToken.VerifyAccessToken(token.AccessToken); err != nil {
+			return nil, err
+		}
+	}
+	subj, err := SubjectFromToken(parsedIDToken)
+	if err != nil {
+		return nil, err
+	}
+	// the token source keeps the old refresh token if the provider did not rotate it
+	return &OIDCIDToken{
+		RawString:    idToken,
+		Subject:      subj,
+		RefreshToken: token.RefreshToken,
+	}, nil
+}
@@ -0,0 +1,292 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauthflow
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v3"
+	"golang.org/x/oauth2"
+)
+
+func signedTestToken(t *testing.T, c claims) string {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: priv}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jws, err := signer.Sign(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jws.CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestTokenCache(t *testing.T) {
+	cache := &tokenCache{dir: filepath.Join(t.TempDir(), "cache")}
+	key := tokenCacheKey("https://issuer.example.com", "sigstore", defaultScopes, "", "")
+
+	if tok, _ := cache.get(key); tok != nil {
+		t.Fatalf("expected no cached token, got %v", tok)
+	}
+
+	tok := &OIDCIDToken{
+		RawString: signedTestToken(t, claims{Subject: "foo", Expiry: float64(time.Now().Add(time.Hour).Unix())}),
+		Subject:   "foo",
+	}
+	if err := cache.put(key, tok); err != nil {
+		t.Fatalf("unexpected error caching token: %v", err)
+	}
+	path, err := cache.path(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Errorf("expected cache file permissions 0600, got %o", fi.Mode().Perm())
+	}
+
+	got, _ := cache.get(key)
+	if got == nil || *got != *tok {
+		t.Fatalf("expected cached token %v, got %v", tok, got)
+	}
+	for name, other := range map[string]string{
+		"client":      tokenCacheKey("https://issuer.example.com", "other-client", defaultScopes, "", ""),
+		"scopes":      tokenCacheKey("https://issuer.example.com", "sigstore", append([]string{"groups"}, defaultScopes...), "", ""),
+		"audience":    tokenCacheKey("https://issuer.example.com", "sigstore", defaultScopes, "other-audience", ""),
+		"auth params": tokenCacheKey("https://issuer.example.com", "sigstore", defaultScopes, "", "connector_id=other"),
+	} {
+		if got, _ := cache.get(other); got != nil {
+			t.Errorf("expected no cached token for a different %s, got %v", name, got)
+		}
+	}
+	if reordered := tokenCacheKey("https://issuer.example.com", "sigstore", []string{"email", "openid"}, "", ""); reordered != key {
+		t.Error("expected the order of scopes not to affect the cache key")
+	}
+
+	// expired tokens are discarded
+	expired := &OIDCIDToken{
+		RawString: signedTestToken(t, claims{Subject: "foo", Expiry: float64(time.Now().Add(-time.Hour).Unix())}),
+		Subject:   "foo",
+	}
+	if err := cache.put(key, expired); err != nil {
+		t.Fatalf("unexpected error caching token: %v", err)
+	}
+	if got, _ := cache.get(key); got != nil {
+		t.Errorf("expected expired token to be discarded, got %v", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected expired cache entry to be removed, got %v", err)
+	}
+
+	// corrupt entries are discarded
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cache.get(key); got != nil {
+		t.Errorf("expected corrupt entry to be discarded, got %v", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected corrupt cache entry to be removed, got %v", err)
+	}
+
+	// the refresh token of an expired token is kept
+	expired.RefreshToken = "refresh"
+	if err := cache.put(key, expired); err != nil {
+		t.Fatalf("unexpected error caching token: %v", err)
+	}
+	if got, refreshToken := cache.get(key); got != nil || refreshToken != "refresh" {
+		t.Errorf("expected only the refresh token of an expired entry, got %v and %q", got, refreshToken)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected refreshable cache entry to be kept, got %v", err)
+	}
+
+	// tokens without an expiry are not cached
+	if err := cache.put(key, &OIDCIDToken{RawString: signedTestToken(t, claims{Subject: "foo"})}); err == nil {
+		t.Error("expected error caching token without expiry")
+	}
+}
+
+func TestOIDConnectTokenCache(t *testing.T) {
+	dir := t.TempDir()
+	tok := &OIDCIDToken{
+		RawString: signedTestToken(t, claims{Subject: "foo", Expiry: float64(time.Now().Add(time.Hour).Unix())}),
+		Subject:   "foo",
+	}
+	key := tokenCacheKey("https://issuer.example.com", "sigstore", defaultScopes, "", "")
+	if err := (&tokenCache{dir: dir}).put(key, tok); err != nil {
+		t.Fatal(err)
+	}
+
+	// a cached token is returned without contacting the issuer
+	got, err := OIDConnect("https://issuer.example.com", "sigstore", "", "", DefaultIDTokenGetter, WithTokenCache(dir))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *got != *tok {
+		t.Errorf("expected cached token %v, got %v", tok, got)
+	}
+}
+
+func TestOIDConnectTokenCacheConnectorID(t *testing.T) {
+	dir := t.TempDir()
+	tok := &OIDCIDToken{
+		RawString: signedTestToken(t, claims{Subject: "foo", Expiry: float64(time.Now().Add(time.Hour).Unix())}),
+		Subject:   "foo",
+	}
+	github := tokenAuthParams(PublicInstanceGithubIDTokenGetter)
+	for _, tg := range []TokenGetter{DefaultIDTokenGetter, PublicInstanceGoogleIDTokenGetter, PublicInstanceMicrosoftIDTokenGetter} {
+		if tokenAuthParams(tg) == github {
+			t.Fatalf("expected %+v to have different auth params than the GitHub getter", tg)
+		}
+	}
+	if reordered := tokenAuthParams(&InteractiveIDTokenGetter{ExtraAuthURLParams: []oauth2.AuthCodeOption{AudienceOpt("aud"), ConnectorIDOpt("c")}}); reordered != tokenAuthParams(&InteractiveIDTokenGetter{ExtraAuthURLParams: []oauth2.AuthCodeOption{ConnectorIDOpt("c"), AudienceOpt("aud")}}) {
+		t.Error("expected the order of auth params not to affect the cache key")
+	}
+
+	// issuer.invalid cannot be resolved, so a cache miss fails instead of starting a flow
+	const issuer = "https://issuer.invalid"
+	key := tokenCacheKey(issuer, "sigstore", defaultScopes, "", github)
+	if err := (&tokenCache{dir: dir}).put(key, tok); err != nil {
+		t.Fatal(err)
+	}
+	got, err := OIDConnect(issuer, "sigstore", "", "", PublicInstanceGithubIDTokenGetter, WithTokenCache(dir))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *got != *tok {
+		t.Errorf("expected cached token %v, got %v", tok, got)
+	}
+	for _, tg := range []TokenGetter{PublicInstanceGoogleIDTokenGetter, PublicInstanceMicrosoftIDTokenGetter} {
+		if got, err := OIDConnect(issuer, "sigstore", "", "", tg, WithTokenCache(dir)); err == nil {
+			t.Errorf("expected a getter with a different connector ID not to share the cached token, got %v", got)
+		}
+	}
+}
+
+type failingTokenGetter struct{}
+
+func (failingTokenGetter) GetIDToken(_ *oidc.Provider, _ oauth2.Config) (*OIDCIDToken, error) {
+	return nil, errors.New("unexpected interactive flow")
+}
+
+func TestOIDConnectTokenCacheRefresh(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: priv}, (&jose.SignerOptions{}).WithHeader("kid", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var refreshTokens []string
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_, _ = w.Write([]byte(strings.ReplaceAll(wellKnownOIDCConfig, "ISSUER", ts.URL)))
+		case "/keys":
+			_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: priv.Public(), KeyID: "test", Algorithm: "RS256", Use: "sig"}}})
+		case "/token":
+			if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "refresh_token" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			refreshTokens = append(refreshTokens, r.Form.Get("refresh_token"))
+			raw, _ := json.Marshal(map[string]interface{}{
+				"iss":            ts.URL,
+				"aud":            "sigstore",
+				"exp":            time.Now().Add(time.Hour).Unix(),
+				"email":          "foo@example.com",
+				"email_verified": true,
+			})
+			jws, err := signer.Sign(raw)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			idToken, _ := jws.CompactSerialize()
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "access",
+				"token_type":    "Bearer",
+				"refresh_token": "rotated",
+				"id_token":      idToken,
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	cache := &tokenCache{dir: dir}
+	key := tokenCacheKey(ts.URL, "sigstore", defaultScopes, "", "")
+	expired := &OIDCIDToken{
+		RawString:    signedTestToken(t, claims{Subject: "foo", Expiry: float64(time.Now().Add(-time.Hour).Unix())}),
+		Subject:      "foo",
+		RefreshToken: "original",
+	}
+	if err := cache.put(key, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	// the expired token is replaced using the refresh token, without an interactive flow
+	got, err := OIDConnect(ts.URL, "sigstore", "", "", failingTokenGetter{}, WithTokenCache(dir))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Subject != "foo@example.com" || got.RefreshToken != "rotated" {
+		t.Errorf("unexpected refreshed token %+v", got)
+	}
+	if len(refreshTokens) != 1 || refreshTokens[0] != "original" {
+		t.Errorf("expected one refresh with the cached refresh token, got %v", refreshTokens)
+	}
+	cached, _ := cache.get(key)
+	if cached == nil || *cached != *got {
+		t.Errorf("expected the refreshed token to be cached, got %v", cached)
+	}
+}
@@ -0,0 +1,83 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const aggregateDigestLabel = "sigstore-aggregate-digest-v1"
+
+// CombineDigests deterministically combines an ordered list of message digests into a single
+// aggregate digest. The result is hashFunc applied to a fixed label, the number of digests and
+// each digest prefixed with its big-endian uint64 length, so that adding, removing or
+// reordering digests always yields a different aggregate.
+func CombineDigests(hashFunc crypto.Hash, digests ...[]byte) ([]byte, error) {
+	if len(digests) == 0 {
+		return nil, errors.New("at least one digest must be provided")
+	}
+	if !hashFunc.Available() {
+		return nil, fmt.Errorf("unsupported hash algorithm: %q", hashFunc.String())
+	}
+	hasher := hashFunc.New()
+	hasher.Write([]byte(aggregateDigestLabel))
+	hasher.Write(binary.BigEndian.AppendUint64(nil, uint64(len(digests))))
+	for i, d := range digests {
+		if len(d) == 0 {
+			return nil, fmt.Errorf("digest %d is empty", i)
+		}
+		hasher.Write(binary.BigEndian.AppendUint64(nil, uint64(len(d))))
+		hasher.Write(d)
+	}
+	return hasher.Sum(nil), nil
+}
+
+// AggregateDigest hashes each message with hashFunc and combines the digests, in order, with CombineDigests.
+func AggregateDigest(hashFunc crypto.Hash, messages ...io.Reader) ([]byte, error) {
+	digests := make([][]byte, 0, len(messages))
+	for i, m := range messages {
+		d, err := hashMessage(m, hashFunc)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		digests = append(digests, d)
+	}
+	return CombineDigests(hashFunc, digests...)
+}
+
+// SignAggregate signs the aggregate digest of messages (see AggregateDigest), so that one
+// signature covers all of them.
+func SignAggregate(s Signer, hashFunc crypto.Hash, messages []io.Reader, opts ...SignOption) ([]byte, error) {
+	aggregate, err := AggregateDigest(hashFunc, messages...)
+	if err != nil {
+		return nil, err
+	}
+	return s.SignMessage(bytes.NewReader(aggregate), opts...)
+}
+
+// VerifyAggregate verifies a signature created by SignAggregate over the same messages in the same order.
+func VerifyAggregate(v Verifier, sig []byte, hashFunc crypto.Hash, messages []io.Reader, opts ...VerifyOption) error {
+	aggregate, err := AggregateDigest(hashFunc, messages...)
+	if err != nil {
+		return err
+	}
+	return v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(aggregate), opts...)
+}
@@ -0,0 +1,81 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"io"
+	"testing"
+)
+
+func readers(messages ...string) []io.Reader {
+	rs := make([]io.Reader, 0, len(messages))
+	for _, m := range messages {
+		rs = append(rs, bytes.NewReader([]byte(m)))
+	}
+	return rs
+}
+
+func TestAggregate(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+
+	sig, err := SignAggregate(sv, crypto.SHA256, readers("a", "b", "c"))
+	if err != nil {
+		t.Fatalf("unexpected error signing aggregate: %v", err)
+	}
+	if err := VerifyAggregate(sv, sig, crypto.SHA256, readers("a", "b", "c")); err != nil {
+		t.Errorf("unexpected error verifying aggregate: %v", err)
+	}
+
+	for name, messages := range map[string][]io.Reader{
+		"reordered": readers("b", "a", "c"),
+		"missing":   readers("a", "b"),
+		"extra":     readers("a", "b", "c", "d"),
+		"modified":  readers("a", "b", "x"),
+		"resplit":   readers("ab", "c"),
+	} {
+		if err := VerifyAggregate(sv, sig, crypto.SHA256, messages); err == nil {
+			t.Errorf("%s: expected error verifying aggregate", name)
+		}
+	}
+
+	// combining precomputed digests yields the same aggregate
+	var digests [][]byte
+	for _, m := range []string{"a", "b", "c"} {
+		d := sha256.Sum256([]byte(m))
+		digests = append(digests, d[:])
+	}
+	combined, err := CombineDigests(crypto.SHA256, digests...)
+	if err != nil {
+		t.Fatalf("unexpected error combining digests: %v", err)
+	}
+	aggregate, err := AggregateDigest(crypto.SHA256, readers("a", "b", "c")...)
+	if err != nil {
+		t.Fatalf("unexpected error computing aggregate digest: %v", err)
+	}
+	if !bytes.Equal(combined, aggregate) {
+		t.Errorf("expected combined digest %x to equal aggregate digest %x", combined, aggregate)
+	}
+
+	if _, err := CombineDigests(crypto.SHA256); err == nil {
+		t.Error("expected error combining no digests")
+	}
+}
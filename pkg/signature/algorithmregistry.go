@@ -0,0 +1,187 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Algorithm identifies a signature scheme, i.e. a key type together with the hash function
+// and padding used when signing
+type Algorithm string
+
+// Signature algorithms known to the default AlgorithmRegistry
+const (
+	AlgorithmECDSAP256SHA256   Algorithm = "ecdsa-p256-sha256"
+	AlgorithmECDSAP384SHA384   Algorithm = "ecdsa-p384-sha384"
+	AlgorithmECDSAP521SHA512   Algorithm = "ecdsa-p521-sha512"
+	AlgorithmRSAPKCS1v15SHA256 Algorithm = "rsa-pkcs1v15-sha256"
+	AlgorithmRSAPKCS1v15SHA384 Algorithm = "rsa-pkcs1v15-sha384"
+	AlgorithmRSAPKCS1v15SHA512 Algorithm = "rsa-pkcs1v15-sha512"
+	AlgorithmRSAPSSSHA256      Algorithm = "rsa-pss-sha256"
+	AlgorithmRSAPSSSHA384      Algorithm = "rsa-pss-sha384"
+	AlgorithmRSAPSSSHA512      Algorithm = "rsa-pss-sha512"
+	AlgorithmED25519           Algorithm = "ed25519"
+	AlgorithmED25519ph         Algorithm = "ed25519ph"
+)
+
+// NamingScheme identifies an external convention for naming signature algorithms
+type NamingScheme string
+
+// Naming schemes known to the default AlgorithmRegistry
+const (
+	// NamingSchemeJOSE names algorithms by their JWS "alg" header value (RFC 7518, RFC 8037)
+	NamingSchemeJOSE NamingScheme = "jose"
+	// NamingSchemeSSH names algorithms by their SSH signature format (RFC 5656, RFC 8332, RFC 8709)
+	NamingSchemeSSH NamingScheme = "ssh"
+	// NamingSchemeOpenSSL names algorithms by their OpenSSL signature algorithm short name
+	NamingSchemeOpenSSL NamingScheme = "openssl"
+)
+
+// AlgorithmRegistry maps between Algorithm identifiers and the names used for them by
+// external naming schemes. Additional schemes and names can be registered with Register.
+// It is safe for concurrent use.
+type AlgorithmRegistry struct {
+	mu         sync.RWMutex
+	toName     map[NamingScheme]map[Algorithm]string
+	toInternal map[NamingScheme]map[string]Algorithm
+}
+
+// DefaultAlgorithmRegistry is an AlgorithmRegistry populated with the JOSE, SSH and OpenSSL
+// names of the package's algorithms
+var DefaultAlgorithmRegistry = NewAlgorithmRegistry()
+
+// NewAlgorithmRegistry returns an AlgorithmRegistry populated with the JOSE, SSH and OpenSSL
+// names of the package's algorithms
+func NewAlgorithmRegistry() *AlgorithmRegistry {
+	r := &AlgorithmRegistry{
+		toName:     map[NamingScheme]map[Algorithm]string{},
+		toInternal: map[NamingScheme]map[string]Algorithm{},
+	}
+	defaults := map[NamingScheme]map[Algorithm]string{
+		NamingSchemeJOSE: {
+			AlgorithmECDSAP256SHA256:   "ES256",
+			AlgorithmECDSAP384SHA384:   "ES384",
+			AlgorithmECDSAP521SHA512:   "ES512",
+			AlgorithmRSAPKCS1v15SHA256: "RS256",
+			AlgorithmRSAPKCS1v15SHA384: "RS384",
+			AlgorithmRSAPKCS1v15SHA512: "RS512",
+			AlgorithmRSAPSSSHA256:      "PS256",
+			AlgorithmRSAPSSSHA384:      "PS384",
+			AlgorithmRSAPSSSHA512:      "PS512",
+			AlgorithmED25519:           "EdDSA",
+		},
+		NamingSchemeSSH: {
+			AlgorithmECDSAP256SHA256:   "ecdsa-sha2-nistp256",
+			AlgorithmECDSAP384SHA384:   "ecdsa-sha2-nistp384",
+			AlgorithmECDSAP521SHA512:   "ecdsa-sha2-nistp521",
+			AlgorithmRSAPKCS1v15SHA256: "rsa-sha2-256",
+			AlgorithmRSAPKCS1v15SHA512: "rsa-sha2-512",
+			AlgorithmED25519:           "ssh-ed25519",
+		},
+		NamingSchemeOpenSSL: {
+			AlgorithmECDSAP256SHA256:   "ecdsa-with-SHA256",
+			AlgorithmECDSAP384SHA384:   "ecdsa-with-SHA384",
+			AlgorithmECDSAP521SHA512:   "ecdsa-with-SHA512",
+			AlgorithmRSAPKCS1v15SHA256: "sha256WithRSAEncryption",
+			AlgorithmRSAPKCS1v15SHA384: "sha384WithRSAEncryption",
+			AlgorithmRSAPKCS1v15SHA512: "sha512WithRSAEncryption",
+			AlgorithmED25519:           "ED25519",
+		},
+	}
+	for scheme, names := range defaults {
+		for alg, name := range names {
+			// the defaults are known not to conflict
+			_ = r.Register(scheme, alg, name)
+		}
+	}
+	return r
+}
+
+// Register maps alg to name in the given naming scheme, creating the scheme if needed. It
+// returns an error if alg or name is already mapped to something else in that scheme.
+func (r *AlgorithmRegistry) Register(scheme NamingScheme, alg Algorithm, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.toName[scheme][alg]; ok && existing != name {
+		return fmt.Errorf("algorithm %s is already registered as %q in naming scheme %s", alg, existing, scheme)
+	}
+	if existing, ok := r.toInternal[scheme][name]; ok && existing != alg {
+		return fmt.Errorf("name %q is already registered for algorithm %s in naming scheme %s", name, existing, scheme)
+	}
+	if r.toName[scheme] == nil {
+		r.toName[scheme] = map[Algorithm]string{}
+		r.toInternal[scheme] = map[string]Algorithm{}
+	}
+	r.toName[scheme][alg] = name
+	r.toInternal[scheme][name] = alg
+	return nil
+}
+
+// Name returns the name of alg in the given naming scheme
+func (r *AlgorithmRegistry) Name(scheme NamingScheme, alg Algorithm) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name, ok := r.toName[scheme][alg]
+	if !ok {
+		return "", fmt.Errorf("algorithm %s has no name in naming scheme %s", alg, scheme)
+	}
+	return name, nil
+}
+
+// Lookup returns the Algorithm with the given name in the given naming scheme
+func (r *AlgorithmRegistry) Lookup(scheme NamingScheme, name string) (Algorithm, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	alg, ok := r.toInternal[scheme][name]
+	if !ok {
+		return "", fmt.Errorf("unknown algorithm %q in naming scheme %s", name, scheme)
+	}
+	return alg, nil
+}
+
+// Names returns the names of algs in the given naming scheme, e.g. to render a list of supported
+// algorithms for a particular ecosystem. Algorithms without a name in the scheme are omitted.
+func (r *AlgorithmRegistry) Names(scheme NamingScheme, algs ...Algorithm) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(algs))
+	for _, alg := range algs {
+		if name, ok := r.toName[scheme][alg]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Algorithms returns the algorithms that have a name in the given naming scheme, sorted
+func (r *AlgorithmRegistry) Algorithms(scheme NamingScheme) []Algorithm {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	algs := make([]Algorithm, 0, len(r.toName[scheme]))
+	for alg := range r.toName[scheme] {
+		algs = append(algs, alg)
+	}
+	sort.Slice(algs, func(i, j int) bool { return algs[i] < algs[j] })
+	return algs
+}
@@ -0,0 +1,81 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAlgorithmRegistry(t *testing.T) {
+	r := NewAlgorithmRegistry()
+
+	for scheme, want := range map[NamingScheme]string{
+		NamingSchemeJOSE:    "ES256",
+		NamingSchemeSSH:     "ecdsa-sha2-nistp256",
+		NamingSchemeOpenSSL: "ecdsa-with-SHA256",
+	} {
+		name, err := r.Name(scheme, AlgorithmECDSAP256SHA256)
+		if err != nil {
+			t.Fatalf("unexpected error getting %s name: %v", scheme, err)
+		}
+		if name != want {
+			t.Errorf("expected %s name %q, got %q", scheme, want, name)
+		}
+		alg, err := r.Lookup(scheme, name)
+		if err != nil {
+			t.Fatalf("unexpected error looking up %s name: %v", scheme, err)
+		}
+		if alg != AlgorithmECDSAP256SHA256 {
+			t.Errorf("expected %s, got %s", AlgorithmECDSAP256SHA256, alg)
+		}
+	}
+
+	if _, err := r.Name(NamingSchemeSSH, AlgorithmRSAPSSSHA256); err == nil {
+		t.Error("expected error for algorithm without an SSH name")
+	}
+	if _, err := r.Lookup(NamingSchemeJOSE, "none"); err == nil {
+		t.Error("expected error looking up unknown name")
+	}
+
+	got := r.Names(NamingSchemeJOSE, AlgorithmED25519, AlgorithmRSAPSSSHA512)
+	if want := []string{"EdDSA", "PS512"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected names %v, got %v", want, got)
+	}
+
+	// custom schemes can be registered
+	internal := NamingScheme("internal")
+	if err := r.Register(internal, AlgorithmECDSAP256SHA256, "p256"); err != nil {
+		t.Fatalf("unexpected error registering name: %v", err)
+	}
+	if err := r.Register(internal, AlgorithmECDSAP256SHA256, "p256"); err != nil {
+		t.Errorf("expected re-registering the same name to succeed: %v", err)
+	}
+	if err := r.Register(internal, AlgorithmECDSAP384SHA384, "p256"); err == nil {
+		t.Error("expected error registering a name for two algorithms")
+	}
+	if err := r.Register(internal, AlgorithmECDSAP256SHA256, "nistp256"); err == nil {
+		t.Error("expected error registering two names for an algorithm")
+	}
+	if algs := r.Algorithms(internal); !reflect.DeepEqual(algs, []Algorithm{AlgorithmECDSAP256SHA256}) {
+		t.Errorf("unexpected algorithms for custom scheme: %v", algs)
+	}
+
+	// registrations do not affect other registries
+	if _, err := DefaultAlgorithmRegistry.Lookup(internal, "p256"); err == nil {
+		t.Error("expected custom scheme to be local to its registry")
+	}
+}
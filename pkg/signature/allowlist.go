@@ -0,0 +1,109 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// AlgorithmNotAllowedError is returned by verifiers loaded with options.WithAllowedAlgorithms
+// when the verifier's algorithm is not in the allowlist
+type AlgorithmNotAllowedError struct {
+	Algorithm Algorithm
+}
+
+func (e *AlgorithmNotAllowedError) Error() string {
+	return fmt.Sprintf("signature algorithm %q is not allowed", e.Algorithm)
+}
+
+// AlgorithmFor returns the identifier of the signature algorithm used by a verifier for
+// publicKey with the given hash function, e.g. "ecdsa-p256-sha256" or "rsa-pss-sha512".
+// Combinations without a predefined Algorithm constant are named in the same format.
+func AlgorithmFor(publicKey crypto.PublicKey, hashFunc crypto.Hash, rsaPSS, ed25519ph bool) (Algorithm, error) {
+	hashName := strings.ToLower(strings.ReplaceAll(hashFunc.String(), "-", ""))
+	switch pk := publicKey.(type) {
+	case *rsa.PublicKey:
+		if rsaPSS {
+			return Algorithm("rsa-pss-" + hashName), nil
+		}
+		return Algorithm("rsa-pkcs1v15-" + hashName), nil
+	case *ecdsa.PublicKey:
+		if pk == nil || pk.Curve == nil {
+			return "", errors.New("invalid ECDSA public key specified")
+		}
+		return Algorithm(fmt.Sprintf("ecdsa-p%d-%s", pk.Curve.Params().BitSize, hashName)), nil
+	case ed25519.PublicKey:
+		if ed25519ph {
+			return AlgorithmED25519ph, nil
+		}
+		return AlgorithmED25519, nil
+	}
+	return "", errors.New("unsupported public key type")
+}
+
+// allowlistVerifier rejects signatures whose algorithm is not allowed. The algorithm is
+// determined on each call, as the hash function can be overridden by WithCryptoSignerOpts.
+type allowlistVerifier struct {
+	Verifier
+	publicKey crypto.PublicKey
+	hashFunc  crypto.Hash
+	rsaPSS    bool
+	ed25519ph bool
+	allowlist []string
+}
+
+func newAllowlistVerifier(v Verifier, publicKey crypto.PublicKey, hashFunc crypto.Hash, rsaPSS, ed25519ph bool, allowlist []string) (Verifier, error) {
+	if _, err := AlgorithmFor(publicKey, hashFunc, rsaPSS, ed25519ph); err != nil {
+		return nil, err
+	}
+	return &allowlistVerifier{
+		Verifier:  v,
+		publicKey: publicKey,
+		hashFunc:  hashFunc,
+		rsaPSS:    rsaPSS,
+		ed25519ph: ed25519ph,
+		allowlist: allowlist,
+	}, nil
+}
+
+// VerifySignature verifies the signature with the wrapped verifier if the algorithm it would
+// use, taking into account any hash function given by WithCryptoSignerOpts, is allowed
+func (a *allowlistVerifier) VerifySignature(signature, message io.Reader, opts ...VerifyOption) error {
+	hashFunc := a.hashFunc
+	for _, opt := range opts {
+		var signerOpts crypto.SignerOpts
+		opt.ApplyCryptoSignerOpts(&signerOpts)
+		if signerOpts != nil {
+			hashFunc = signerOpts.HashFunc()
+		}
+	}
+	alg, err := AlgorithmFor(a.publicKey, hashFunc, a.rsaPSS, a.ed25519ph)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(a.allowlist, string(alg)) {
+		return &AlgorithmNotAllowedError{Algorithm: alg}
+	}
+	return a.Verifier.VerifySignature(signature, message, opts...)
+}
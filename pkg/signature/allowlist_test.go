@@ -0,0 +1,138 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1" // nolint:gosec
+	"errors"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestAllowedAlgorithms(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error generating key: %v", err)
+	}
+	sv, err := LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error loading signer/verifier: %v", err)
+	}
+	message := []byte("sign me")
+	sig, err := sv.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+
+	v, err := LoadVerifierWithOpts(priv.Public(), options.WithHash(crypto.SHA256),
+		options.WithAllowedAlgorithms(string(AlgorithmECDSAP256SHA256), string(AlgorithmED25519)))
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+	if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying with allowed algorithm: %v", err)
+	}
+
+	v, err = LoadVerifierWithOpts(priv.Public(), options.WithHash(crypto.SHA256),
+		options.WithAllowedAlgorithms(string(AlgorithmECDSAP384SHA384)))
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+	var notAllowed *AlgorithmNotAllowedError
+	if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); !errors.As(err, &notAllowed) {
+		t.Fatalf("expected AlgorithmNotAllowedError, got %v", err)
+	}
+	if notAllowed.Algorithm != AlgorithmECDSAP256SHA256 {
+		t.Errorf("unexpected algorithm in error: %q", notAllowed.Algorithm)
+	}
+
+	// the hash function given per call is checked against the allowlist
+	sha1Digest := sha1.Sum(message) // nolint:gosec
+	sha1Sig, err := ecdsa.SignASN1(rand.Reader, priv, sha1Digest[:])
+	if err != nil {
+		t.Fatalf("unexpected error signing with SHA-1: %v", err)
+	}
+	v, err = LoadVerifierWithOpts(priv.Public(), options.WithHash(crypto.SHA256),
+		options.WithAllowedAlgorithms(string(AlgorithmECDSAP256SHA256)))
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+	if err := v.VerifySignature(bytes.NewReader(sha1Sig), bytes.NewReader(message), options.WithCryptoSignerOpts(crypto.SHA1)); !errors.As(err, &notAllowed) {
+		t.Fatalf("expected AlgorithmNotAllowedError for a SHA-1 signature, got %v", err)
+	}
+	if notAllowed.Algorithm != "ecdsa-p256-sha1" {
+		t.Errorf("unexpected algorithm in error: %q", notAllowed.Algorithm)
+	}
+
+	// no allowlist: permissive, and the concrete verifier type is preserved
+	v, err = LoadVerifierWithOpts(priv.Public(), options.WithHash(crypto.SHA256))
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+	if _, ok := v.(*ECDSAVerifier); !ok {
+		t.Errorf("expected *ECDSAVerifier, got %T", v)
+	}
+}
+
+func TestAlgorithmFor(t *testing.T) {
+	ecKey, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPub, _ := ecKey.PublicKey()
+	rsaKey, _, err := NewDefaultRSAPKCS1v15SignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPub, _ := rsaKey.PublicKey()
+	edKey, _, err := NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, _ := edKey.PublicKey()
+
+	tests := []struct {
+		pub       crypto.PublicKey
+		hash      crypto.Hash
+		rsaPSS    bool
+		ed25519ph bool
+		want      Algorithm
+	}{
+		{ecPub, crypto.SHA256, false, false, AlgorithmECDSAP256SHA256},
+		{ecPub, crypto.SHA512, false, false, "ecdsa-p256-sha512"},
+		{rsaPub, crypto.SHA384, false, false, AlgorithmRSAPKCS1v15SHA384},
+		{rsaPub, crypto.SHA256, true, false, AlgorithmRSAPSSSHA256},
+		{edPub, crypto.SHA512, false, false, AlgorithmED25519},
+		{edPub, crypto.SHA512, false, true, AlgorithmED25519ph},
+	}
+	for _, tt := range tests {
+		got, err := AlgorithmFor(tt.pub, tt.hash, tt.rsaPSS, tt.ed25519ph)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		} else if got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+	if _, err := AlgorithmFor("not a key", crypto.SHA256, false, false); err == nil {
+		t.Error("expected error for unsupported key type")
+	}
+}
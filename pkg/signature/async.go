@@ -0,0 +1,68 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// AsyncSigner is implemented by Signers whose backend accepts a signing request and completes
+// it later (e.g. an HSM fronted by a work queue), so that the caller is not blocked for the
+// duration of the request
+type AsyncSigner interface {
+	Signer
+	// SignMessageAsync submits message for signing and returns a SignFuture for the result
+	// without waiting for it. ctx bounds the request itself, not only its submission.
+	SignMessageAsync(ctx context.Context, message io.Reader, opts ...SignOption) (*SignFuture, error)
+}
+
+// NewSignFuture returns a pending SignFuture along with the function that completes it, for use
+// by AsyncSigner implementations. Only the first call to complete has any effect.
+func NewSignFuture() (*SignFuture, func(sig []byte, err error)) {
+	f := &SignFuture{done: make(chan struct{})}
+	var once sync.Once
+	return f, func(sig []byte, err error) {
+		once.Do(func() { f.complete(sig, err) })
+	}
+}
+
+// SignMessageAsync signs message with s and returns a SignFuture for the result. If s is an
+// AsyncSigner the request is submitted to it and SignMessageAsync returns without waiting;
+// otherwise the message is signed synchronously with ctx passed as options.WithContext, and
+// the returned SignFuture has already completed.
+//
+// An error is returned only if the request could not be submitted; failures to sign are
+// reported by the SignFuture.
+func SignMessageAsync(ctx context.Context, s Signer, message io.Reader, opts ...SignOption) (*SignFuture, error) {
+	if s == nil {
+		return nil, errors.New("signer cannot be nil")
+	}
+	if message == nil {
+		return nil, errors.New("message cannot be nil")
+	}
+	if as, ok := s.(AsyncSigner); ok {
+		return as.SignMessageAsync(ctx, message, opts...)
+	}
+	f, complete := NewSignFuture()
+	opts = append(opts[:len(opts):len(opts)], options.WithContext(ctx))
+	complete(s.SignMessage(message, opts...))
+	return f, nil
+}
@@ -0,0 +1,104 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// deferredSigner is an AsyncSigner that completes each request when release is closed
+type deferredSigner struct {
+	SignerVerifier
+	release chan struct{}
+}
+
+func (d *deferredSigner) SignMessageAsync(_ context.Context, message io.Reader, opts ...SignOption) (*SignFuture, error) {
+	f, complete := NewSignFuture()
+	go func() {
+		<-d.release
+		complete(d.SignerVerifier.SignMessage(message, opts...))
+	}()
+	return f, nil
+}
+
+func TestSignMessageAsync(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	message := []byte("sign me")
+	ctx := context.Background()
+
+	// a synchronous signer completes before SignMessageAsync returns
+	f, err := SignMessageAsync(ctx, sv, bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error submitting: %v", err)
+	}
+	select {
+	case <-f.Done():
+	default:
+		t.Fatal("expected synchronous signer to complete immediately")
+	}
+	sig, err := f.Wait(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying: %v", err)
+	}
+
+	// an AsyncSigner does not block the caller
+	d := &deferredSigner{SignerVerifier: sv, release: make(chan struct{})}
+	f, err = SignMessageAsync(ctx, d, bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error submitting: %v", err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := f.Wait(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled waiting for pending request, got %v", err)
+	}
+	close(d.release)
+	sig, err = f.Wait(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error signing asynchronously: %v", err)
+	}
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying asynchronous signature: %v", err)
+	}
+
+	if _, err := SignMessageAsync(ctx, nil, bytes.NewReader(message)); err == nil {
+		t.Error("expected error for nil signer")
+	}
+	if _, err := SignMessageAsync(ctx, sv, nil); err == nil {
+		t.Error("expected error for nil message")
+	}
+}
+
+func TestNewSignFuture(t *testing.T) {
+	f, complete := NewSignFuture()
+	errSign := errors.New("sign failed")
+	complete(nil, errSign)
+	// later completions are ignored
+	complete([]byte("sig"), nil)
+	if sig, err := f.Wait(context.Background()); !errors.Is(err, errSign) || sig != nil {
+		t.Errorf("expected first completion to win, got %q, %v", sig, err)
+	}
+}
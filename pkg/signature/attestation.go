@@ -0,0 +1,100 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// KeyAttestationError is returned when a key's hardware attestation (see options.WithKeyAttestation)
+// does not validate, in which case the key must not be trusted
+type KeyAttestationError struct {
+	// Reason briefly describes which check failed
+	Reason string
+	Err    error
+}
+
+func (e *KeyAttestationError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("key attestation failed: %s", e.Reason)
+	}
+	return fmt.Sprintf("key attestation failed: %s: %v", e.Reason, e.Err)
+}
+
+func (e *KeyAttestationError) Unwrap() error {
+	return e.Err
+}
+
+// VerifyKeyAttestation checks that attestation vouches for publicKey: the leaf of the attestation
+// chain must certify publicKey, the chain must validate to one of the attestation roots, the leaf
+// must carry every required extension with its expected value, and the policy (if any) must
+// accept the leaf. Any failure is reported as a *KeyAttestationError.
+func VerifyKeyAttestation(publicKey crypto.PublicKey, attestation options.KeyAttestation) error {
+	if len(attestation.Chain) == 0 || attestation.Chain[0] == nil {
+		return &KeyAttestationError{Reason: "no attestation certificate provided"}
+	}
+	if attestation.Roots == nil {
+		return &KeyAttestationError{Reason: "no attestation roots provided"}
+	}
+	leaf := attestation.Chain[0]
+	if err := cryptoutils.EqualKeys(publicKey, leaf.PublicKey); err != nil {
+		return &KeyAttestationError{Reason: "attestation certificate does not certify the key", Err: err}
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range attestation.Chain[1:] {
+		intermediates.AddCert(c)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         attestation.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   attestation.CurrentTime,
+		// attestation certificates are not issued for any particular extended key usage
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return &KeyAttestationError{Reason: "attestation chain does not validate", Err: err}
+	}
+
+	for oid, want := range attestation.RequiredExtensions {
+		found := false
+		for _, ext := range leaf.Extensions {
+			if ext.Id.String() != oid {
+				continue
+			}
+			found = true
+			if want != nil && !bytes.Equal(ext.Value, want) {
+				return &KeyAttestationError{Reason: fmt.Sprintf("unexpected value for attestation extension %s", oid)}
+			}
+			break
+		}
+		if !found {
+			return &KeyAttestationError{Reason: fmt.Sprintf("attestation extension %s is missing", oid)}
+		}
+	}
+
+	if attestation.Policy != nil {
+		if err := attestation.Policy(leaf); err != nil {
+			return &KeyAttestationError{Reason: "attestation policy rejected the certificate", Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+var (
+	testNonExportableOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	asn1True             = []byte{0x01, 0x01, 0xff}
+	asn1False            = []byte{0x01, 0x01, 0x00}
+)
+
+func newAttestationCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error generating key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test attestation root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, priv.Public(), priv)
+	if err != nil {
+		t.Fatalf("unexpected error creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error parsing certificate: %v", err)
+	}
+	return cert, priv
+}
+
+func newAttestationLeaf(t *testing.T, ca *x509.Certificate, caPriv *ecdsa.PrivateKey, pub *ecdsa.PublicKey, nonExportable []byte) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "attested key"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if nonExportable != nil {
+		template.ExtraExtensions = []pkix.Extension{{Id: testNonExportableOID, Value: nonExportable}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, pub, caPriv)
+	if err != nil {
+		t.Fatalf("unexpected error creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error parsing certificate: %v", err)
+	}
+	return cert
+}
+
+func TestVerifyKeyAttestation(t *testing.T) {
+	ca, caPriv := newAttestationCA(t)
+	otherCA, otherCAPriv := newAttestationCA(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	required := map[string][]byte{testNonExportableOID.String(): asn1True}
+
+	for _, tt := range []struct {
+		name        string
+		attestation options.KeyAttestation
+		valid       bool
+	}{
+		{
+			name:        "valid",
+			attestation: options.KeyAttestation{Chain: []*x509.Certificate{newAttestationLeaf(t, ca, caPriv, &key.PublicKey, asn1True)}, Roots: roots, RequiredExtensions: required},
+			valid:       true,
+		},
+		{
+			name:        "presence only",
+			attestation: options.KeyAttestation{Chain: []*x509.Certificate{newAttestationLeaf(t, ca, caPriv, &key.PublicKey, asn1False)}, Roots: roots, RequiredExtensions: map[string][]byte{testNonExportableOID.String(): nil}},
+			valid:       true,
+		},
+		{
+			name:        "empty chain",
+			attestation: options.KeyAttestation{Roots: roots},
+		},
+		{
+			name:        "no roots",
+			attestation: options.KeyAttestation{Chain: []*x509.Certificate{newAttestationLeaf(t, ca, caPriv, &key.PublicKey, asn1True)}},
+		},
+		{
+			name:        "certifies a different key",
+			attestation: options.KeyAttestation{Chain: []*x509.Certificate{newAttestationLeaf(t, ca, caPriv, &otherKey.PublicKey, asn1True)}, Roots: roots},
+		},
+		{
+			name:        "untrusted root",
+			attestation: options.KeyAttestation{Chain: []*x509.Certificate{newAttestationLeaf(t, otherCA, otherCAPriv, &key.PublicKey, asn1True)}, Roots: roots},
+		},
+		{
+			name:        "expired",
+			attestation: options.KeyAttestation{Chain: []*x509.Certificate{newAttestationLeaf(t, ca, caPriv, &key.PublicKey, asn1True)}, Roots: roots, CurrentTime: time.Now().Add(2 * time.Hour)},
+		},
+		{
+			name:        "missing extension",
+			attestation: options.KeyAttestation{Chain: []*x509.Certificate{newAttestationLeaf(t, ca, caPriv, &key.PublicKey, nil)}, Roots: roots, RequiredExtensions: required},
+		},
+		{
+			name:        "unexpected extension value",
+			attestation: options.KeyAttestation{Chain: []*x509.Certificate{newAttestationLeaf(t, ca, caPriv, &key.PublicKey, asn1False)}, Roots: roots, RequiredExtensions: required},
+		},
+		{
+			name: "rejected by policy",
+			attestation: options.KeyAttestation{
+				Chain:  []*x509.Certificate{newAttestationLeaf(t, ca, caPriv, &key.PublicKey, asn1True)},
+				Roots:  roots,
+				Policy: func(*x509.Certificate) error { return errors.New("firmware too old") },
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyKeyAttestation(&key.PublicKey, tt.attestation)
+			if tt.valid {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var attErr *KeyAttestationError
+			if !errors.As(err, &attErr) {
+				t.Errorf("expected *KeyAttestationError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadVerifierWithKeyAttestation(t *testing.T) {
+	ca, caPriv := newAttestationCA(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	attestation := options.KeyAttestation{
+		Chain:              []*x509.Certificate{newAttestationLeaf(t, ca, caPriv, &key.PublicKey, asn1True)},
+		Roots:              roots,
+		RequiredExtensions: map[string][]byte{testNonExportableOID.String(): asn1True},
+	}
+	if _, err := LoadVerifierWithOpts(&key.PublicKey, options.WithKeyAttestation(attestation)); err != nil {
+		t.Fatalf("unexpected error loading attested verifier: %v", err)
+	}
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	v, err := LoadVerifierWithOpts(&otherKey.PublicKey, options.WithKeyAttestation(attestation))
+	var attErr *KeyAttestationError
+	if !errors.As(err, &attErr) || v != nil {
+		t.Errorf("expected *KeyAttestationError and no verifier, got %v, %v", v, err)
+	}
+}
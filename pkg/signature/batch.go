@@ -0,0 +1,99 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// BatchItem is a signature and the message it is expected to be over, to be verified by a
+// BatchVerifier. Options are passed to the verifier for this item only.
+type BatchItem struct {
+	Signature []byte
+	Message   []byte
+	Options   []VerifyOption
+}
+
+// BatchVerifier verifies many signatures made by a single key, e.g. the entries of a
+// transparency log, in parallel.
+type BatchVerifier struct {
+	verifier    Verifier
+	concurrency int
+}
+
+// NewBatchVerifier returns a BatchVerifier that verifies with v, using up to concurrency
+// goroutines. Values less than 1 use one goroutine per CPU (see runtime.NumCPU).
+func NewBatchVerifier(v Verifier, concurrency int) (*BatchVerifier, error) {
+	if v == nil {
+		return nil, errors.New("verifier cannot be nil")
+	}
+	if concurrency < 1 {
+		concurrency = runtime.NumCPU()
+	}
+	return &BatchVerifier{
+		verifier:    v,
+		concurrency: concurrency,
+	}, nil
+}
+
+// VerifyBatch verifies each item and returns one result per item, in the same order: nil if
+// the signature is valid, otherwise the verification error. Items that have not been verified
+// when ctx is done get ctx.Err() as their result. ctx is also passed to the verifier with
+// options.WithContext, for verifiers that make RPCs.
+func (b *BatchVerifier) VerifyBatch(ctx context.Context, items []BatchItem) []error {
+	results := make([]error, len(items))
+	verify := func(i int) {
+		if err := ctx.Err(); err != nil {
+			results[i] = err
+			return
+		}
+		item := items[i]
+		opts := make([]VerifyOption, 0, len(item.Options)+1)
+		opts = append(opts, item.Options...)
+		opts = append(opts, options.WithContext(ctx))
+		results[i] = b.verifier.VerifySignature(bytes.NewReader(item.Signature), bytes.NewReader(item.Message), opts...)
+	}
+	if b.concurrency == 1 || len(items) < 2 {
+		for i := range items {
+			verify(i)
+		}
+		return results
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(b.concurrency, len(items)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				verify(i)
+			}
+		}()
+	}
+	for i := range items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
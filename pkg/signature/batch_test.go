@@ -0,0 +1,107 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestBatchVerifier(t *testing.T) {
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaSV, _, err := NewDefaultRSAPKCS1v15SignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, sv := range map[string]SignerVerifier{"ecdsa": ecdsaSV, "rsa": rsaSV} {
+		items := make([]BatchItem, 50)
+		for i := range items {
+			msg := []byte(fmt.Sprintf("log entry %d", i))
+			label := []byte("entry")
+			sig, err := sv.SignMessage(bytes.NewReader(msg), options.WithDomainSeparation(label))
+			if err != nil {
+				t.Fatalf("%s: unexpected error signing: %v", name, err)
+			}
+			items[i] = BatchItem{Signature: sig, Message: msg, Options: []VerifyOption{options.WithDomainSeparation(label)}}
+			switch i % 5 {
+			case 1:
+				items[i].Message = []byte("tampered")
+			case 2:
+				items[i].Options = nil
+			}
+		}
+
+		for _, concurrency := range []int{0, 1, 4} {
+			bv, err := NewBatchVerifier(sv, concurrency)
+			if err != nil {
+				t.Fatalf("%s: unexpected error creating batch verifier: %v", name, err)
+			}
+			results := bv.VerifyBatch(context.Background(), items)
+			if len(results) != len(items) {
+				t.Fatalf("%s, concurrency %d: got %d results, want %d", name, concurrency, len(results), len(items))
+			}
+			for i, err := range results {
+				if wantValid := i%5 != 1 && i%5 != 2; (err == nil) != wantValid {
+					t.Errorf("%s, concurrency %d: item %d valid = %v, want %v (err: %v)", name, concurrency, i, err == nil, wantValid, err)
+				}
+			}
+		}
+	}
+}
+
+func TestBatchVerifierCanceled(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("message")
+	sig, err := sv.SignMessage(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := make([]BatchItem, 10)
+	for i := range items {
+		items[i] = BatchItem{Signature: sig, Message: msg}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bv, err := NewBatchVerifier(sv, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, err := range bv.VerifyBatch(ctx, items) {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("item %d: expected context.Canceled, got %v", i, err)
+		}
+	}
+
+	if _, err := NewBatchVerifier(nil, 1); err == nil {
+		t.Error("expected error creating batch verifier without a verifier")
+	}
+	if results := bv.VerifyBatch(context.Background(), nil); len(results) != 0 {
+		t.Errorf("expected no results for an empty batch, got %d", len(results))
+	}
+}
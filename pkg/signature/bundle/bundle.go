@@ -0,0 +1,196 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// Bundle is the subset of the Sigstore bundle (protobuf-specs dev.sigstore.bundle.v1.Bundle)
+// in its canonical JSON form that is needed to extract the message signature.
+type Bundle struct {
+	MediaType            string               `json:"mediaType"`
+	VerificationMaterial VerificationMaterial `json:"verificationMaterial"`
+	MessageSignature     *MessageSignature    `json:"messageSignature,omitempty"`
+}
+
+// VerificationMaterial holds the key or certificate material of a bundle; exactly one of
+// PublicKey, X509CertificateChain or Certificate is expected to be set.
+type VerificationMaterial struct {
+	PublicKey            *PublicKeyIdentifier  `json:"publicKey,omitempty"`
+	X509CertificateChain *X509CertificateChain `json:"x509CertificateChain,omitempty"`
+	Certificate          *X509Certificate      `json:"certificate,omitempty"`
+}
+
+// PublicKeyIdentifier is a hint identifying a public key distributed out of band.
+type PublicKeyIdentifier struct {
+	Hint string `json:"hint,omitempty"`
+}
+
+// X509CertificateChain is a chain of DER-encoded certificates, leaf first.
+type X509CertificateChain struct {
+	Certificates []X509Certificate `json:"certificates"`
+}
+
+// X509Certificate is a DER-encoded certificate.
+type X509Certificate struct {
+	RawBytes []byte `json:"rawBytes"`
+}
+
+// MessageSignature is a signature over an artifact along with the artifact's digest.
+type MessageSignature struct {
+	MessageDigest *HashOutput `json:"messageDigest,omitempty"`
+	Signature     []byte      `json:"signature"`
+}
+
+// HashOutput is a digest along with the name of the algorithm that produced it.
+type HashOutput struct {
+	Algorithm string `json:"algorithm"`
+	Digest    []byte `json:"digest"`
+}
+
+// SignatureMaterial is the material extracted from a bundle's message signature section
+// that is required to verify the signature.
+type SignatureMaterial struct {
+	// Verifier verifies Signature over the artifact using the key from the bundle; for
+	// certificate-based bundles it is a *signature.CertificateVerifier
+	Verifier signature.Verifier
+	// Signature is the raw signature over the artifact
+	Signature []byte
+	// HashFunc is the hash function used to compute Digest
+	HashFunc crypto.Hash
+	// Digest is the artifact digest recorded in the bundle, if present
+	Digest []byte
+	// Certificate is the leaf signing certificate, or nil if the bundle references a public key
+	Certificate *x509.Certificate
+}
+
+var hashAlgorithms = map[string]crypto.Hash{
+	"SHA2_256": crypto.SHA256,
+	"SHA2_384": crypto.SHA384,
+	"SHA2_512": crypto.SHA512,
+}
+
+// ParseMessageSignature parses a JSON-encoded Sigstore bundle and extracts the material
+// needed to verify its message signature. No verification policy (certificate chain,
+// transparency log, timestamps) is applied.
+//
+// If the bundle references a public key by hint, that key must be supplied in pub. If the
+// bundle contains a certificate, pub may be nil; if it is not, it must match the certificate's key.
+func ParseMessageSignature(bundleJSON []byte, pub crypto.PublicKey) (*SignatureMaterial, error) {
+	var b Bundle
+	if err := json.Unmarshal(bundleJSON, &b); err != nil {
+		return nil, fmt.Errorf("unmarshalling bundle: %w", err)
+	}
+	return b.SignatureMaterial(pub)
+}
+
+// SignatureMaterial extracts the material needed to verify the bundle's message signature.
+// See ParseMessageSignature for how pub is used.
+func (b *Bundle) SignatureMaterial(pub crypto.PublicKey) (*SignatureMaterial, error) {
+	ms := b.MessageSignature
+	if ms == nil {
+		return nil, errors.New("bundle does not contain a message signature")
+	}
+	if len(ms.Signature) == 0 {
+		return nil, errors.New("bundle message signature is empty")
+	}
+
+	sm := &SignatureMaterial{
+		Signature: ms.Signature,
+		HashFunc:  crypto.SHA256,
+	}
+	if ms.MessageDigest != nil {
+		hf, ok := hashAlgorithms[ms.MessageDigest.Algorithm]
+		if !ok {
+			return nil, fmt.Errorf("unsupported message digest algorithm %q", ms.MessageDigest.Algorithm)
+		}
+		if len(ms.MessageDigest.Digest) != hf.Size() {
+			return nil, fmt.Errorf("message digest length %d does not match algorithm %s", len(ms.MessageDigest.Digest), ms.MessageDigest.Algorithm)
+		}
+		sm.HashFunc = hf
+		sm.Digest = ms.MessageDigest.Digest
+	}
+
+	key, cert, err := b.VerificationMaterial.publicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	sm.Certificate = cert
+
+	if cert != nil {
+		sm.Verifier, err = signature.LoadCertificateVerifier(cert, options.WithHash(sm.HashFunc))
+	} else {
+		sm.Verifier, err = signature.LoadVerifier(key, sm.HashFunc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading verifier: %w", err)
+	}
+	return sm, nil
+}
+
+func (vm *VerificationMaterial) publicKey(pub crypto.PublicKey) (crypto.PublicKey, *x509.Certificate, error) {
+	var rawCert []byte
+	set := 0
+	if vm.PublicKey != nil {
+		set++
+	}
+	if vm.X509CertificateChain != nil {
+		set++
+		if len(vm.X509CertificateChain.Certificates) == 0 {
+			return nil, nil, errors.New("bundle certificate chain is empty")
+		}
+		rawCert = vm.X509CertificateChain.Certificates[0].RawBytes
+	}
+	if vm.Certificate != nil {
+		set++
+		rawCert = vm.Certificate.RawBytes
+	}
+
+	switch set {
+	case 0:
+		return nil, nil, errors.New("bundle does not contain verification material")
+	case 1:
+	default:
+		return nil, nil, errors.New("bundle contains more than one kind of verification material")
+	}
+
+	if vm.PublicKey != nil {
+		if pub == nil {
+			return nil, nil, fmt.Errorf("bundle references public key %q which must be provided", vm.PublicKey.Hint)
+		}
+		return pub, nil, nil
+	}
+
+	cert, err := x509.ParseCertificate(rawCert)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing bundle certificate: %w", err)
+	}
+	if pub != nil {
+		if err := cryptoutils.EqualKeys(pub, cert.PublicKey); err != nil {
+			return nil, nil, fmt.Errorf("provided public key does not match bundle certificate: %w", err)
+		}
+	}
+	return cert.PublicKey, cert, nil
+}
@@ -0,0 +1,140 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/sigstore/test"
+)
+
+func TestParseMessageSignature(t *testing.T) {
+	rootCert, rootKey, _ := test.GenerateRootCa()
+	leafCert, leafKey, _ := test.GenerateLeafCert("subject@example.com", "oidc-issuer", rootCert, rootKey)
+
+	message := []byte("artifact contents")
+	digest := sha256.Sum256(message)
+	sig, err := ecdsa.SignASN1(rand.Reader, leafKey, digest[:])
+	if err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+
+	b64 := base64.StdEncoding.EncodeToString
+	certBundle := fmt.Sprintf(`{
+		"mediaType": "application/vnd.dev.sigstore.bundle+json;version=0.3",
+		"verificationMaterial": {"certificate": {"rawBytes": %q}},
+		"messageSignature": {"messageDigest": {"algorithm": "SHA2_256", "digest": %q}, "signature": %q}
+	}`, b64(leafCert.Raw), b64(digest[:]), b64(sig))
+
+	sm, err := ParseMessageSignature([]byte(certBundle), nil)
+	if err != nil {
+		t.Fatalf("unexpected error parsing bundle: %v", err)
+	}
+	if sm.Certificate == nil || !sm.Certificate.Equal(leafCert) {
+		t.Error("expected leaf certificate to be extracted")
+	}
+	if !bytes.Equal(sm.Digest, digest[:]) {
+		t.Error("expected message digest to be extracted")
+	}
+	if err := sm.Verifier.VerifySignature(bytes.NewReader(sm.Signature), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying extracted signature: %v", err)
+	}
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := ParseMessageSignature([]byte(certBundle), otherKey.Public()); err == nil {
+		t.Error("expected error for public key not matching certificate")
+	}
+
+	keyBundle := fmt.Sprintf(`{
+		"verificationMaterial": {"publicKey": {"hint": "my-key"}},
+		"messageSignature": {"signature": %q}
+	}`, b64(sig))
+	if _, err := ParseMessageSignature([]byte(keyBundle), nil); err == nil || !strings.Contains(err.Error(), "my-key") {
+		t.Errorf("expected error naming the missing public key, got %v", err)
+	}
+	sm, err = ParseMessageSignature([]byte(keyBundle), leafKey.Public())
+	if err != nil {
+		t.Fatalf("unexpected error parsing bundle with public key: %v", err)
+	}
+	if sm.Certificate != nil {
+		t.Error("expected no certificate for public key bundle")
+	}
+	if err := sm.Verifier.VerifySignature(bytes.NewReader(sm.Signature), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying extracted signature: %v", err)
+	}
+}
+
+func TestParseMessageSignatureErrors(t *testing.T) {
+	rootCert, _, _ := test.GenerateRootCa()
+	cert := base64.StdEncoding.EncodeToString(rootCert.Raw)
+
+	tests := []struct {
+		name   string
+		bundle string
+	}{
+		{
+			name:   "invalid JSON",
+			bundle: `{`,
+		},
+		{
+			name:   "no message signature",
+			bundle: fmt.Sprintf(`{"verificationMaterial": {"certificate": {"rawBytes": %q}}, "dsseEnvelope": {}}`, cert),
+		},
+		{
+			name:   "empty signature",
+			bundle: fmt.Sprintf(`{"verificationMaterial": {"certificate": {"rawBytes": %q}}, "messageSignature": {}}`, cert),
+		},
+		{
+			name:   "no verification material",
+			bundle: `{"messageSignature": {"signature": "c2ln"}}`,
+		},
+		{
+			name:   "multiple verification materials",
+			bundle: fmt.Sprintf(`{"verificationMaterial": {"publicKey": {}, "certificate": {"rawBytes": %q}}, "messageSignature": {"signature": "c2ln"}}`, cert),
+		},
+		{
+			name:   "empty certificate chain",
+			bundle: `{"verificationMaterial": {"x509CertificateChain": {"certificates": []}}, "messageSignature": {"signature": "c2ln"}}`,
+		},
+		{
+			name:   "malformed certificate",
+			bundle: `{"verificationMaterial": {"certificate": {"rawBytes": "Y2VydA=="}}, "messageSignature": {"signature": "c2ln"}}`,
+		},
+		{
+			name:   "unsupported digest algorithm",
+			bundle: fmt.Sprintf(`{"verificationMaterial": {"certificate": {"rawBytes": %q}}, "messageSignature": {"messageDigest": {"algorithm": "MD5", "digest": "ZGln"}, "signature": "c2ln"}}`, cert),
+		},
+		{
+			name:   "digest length mismatch",
+			bundle: fmt.Sprintf(`{"verificationMaterial": {"certificate": {"rawBytes": %q}}, "messageSignature": {"messageDigest": {"algorithm": "SHA2_256", "digest": "ZGln"}, "signature": "c2ln"}}`, cert),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseMessageSignature([]byte(tt.bundle), nil); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// CertificateVerifier is a signature.Verifier that verifies signatures using the public key
+// of an X.509 certificate. If a policy is given with options.WithCertificatePolicy, it is
+// invoked with the certificate once the signature has been verified.
+type CertificateVerifier struct {
+	Verifier
+	cert *x509.Certificate
+}
+
+// LoadCertificateVerifier returns a CertificateVerifier for the public key in cert. The
+// options are used to construct the underlying Verifier as in LoadVerifierWithOpts.
+//
+// No validation of the certificate itself (chain, validity period) is performed.
+func LoadCertificateVerifier(cert *x509.Certificate, opts ...LoadOption) (*CertificateVerifier, error) {
+	if cert == nil {
+		return nil, errors.New("certificate cannot be nil")
+	}
+	v, err := LoadVerifierWithOpts(cert.PublicKey, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &CertificateVerifier{
+		Verifier: v,
+		cert:     cert,
+	}, nil
+}
+
+// Certificate returns the certificate whose public key is used for verification
+func (c *CertificateVerifier) Certificate() *x509.Certificate {
+	return c.cert
+}
+
+// SignerIdentity describes the identity a certificate was issued to, as recorded in its subject
+// alternative names and, for Fulcio-issued certificates, its OIDC issuer extension
+type SignerIdentity struct {
+	Emails []string
+	URIs   []string
+	// OtherName is the UTF-8 OtherName SAN used by Fulcio for identities that are neither email
+	// addresses nor URIs (e.g. usernames), or empty if there is none
+	OtherName string
+	// OIDCIssuer is the issuer of the OIDC token the certificate was requested with, or empty if
+	// the certificate has no issuer extension
+	OIDCIssuer string
+}
+
+// CertificateIdentity extracts the SignerIdentity from cert. The OIDC issuer is read from the
+// Fulcio issuer extension (1.3.6.1.4.1.57264.1.8), falling back to the deprecated raw-valued
+// extension (1.3.6.1.4.1.57264.1.1); an error is returned only if an extension is malformed.
+func CertificateIdentity(cert *x509.Certificate) (SignerIdentity, error) {
+	if cert == nil {
+		return SignerIdentity{}, errors.New("certificate cannot be nil")
+	}
+	issuer, err := cryptoutils.GetOIDCIssuer(cert)
+	if err != nil {
+		return SignerIdentity{}, err
+	}
+	id := SignerIdentity{
+		Emails:     cert.EmailAddresses,
+		OIDCIssuer: issuer,
+	}
+	for _, uri := range cert.URIs {
+		id.URIs = append(id.URIs, uri.String())
+	}
+	// ignore error if there's no OtherName SAN
+	id.OtherName, _ = cryptoutils.UnmarshalOtherNameSAN(cert.Extensions)
+	return id, nil
+}
+
+// CertificateVerificationResult is returned by CertificateVerifier.Verify for a signature
+// that verified and was accepted by the certificate policy, if any
+type CertificateVerificationResult struct {
+	Certificate *x509.Certificate
+	Identity    SignerIdentity
+}
+
+// VerifySignature verifies the signature for the given message, and then applies the
+// certificate policy if one was specified with options.WithCertificatePolicy.
+//
+// This function returns nil if the verification succeeded, and an error message otherwise.
+func (c *CertificateVerifier) VerifySignature(signature, message io.Reader, opts ...VerifyOption) error {
+	if err := c.Verifier.VerifySignature(signature, message, opts...); err != nil {
+		return err
+	}
+	return c.applyPolicy(opts...)
+}
+
+// Verify behaves as VerifySignature, and on success also returns the identity of the signer
+// as recorded in the certificate, so that callers can log or check who signed.
+func (c *CertificateVerifier) Verify(signature, message io.Reader, opts ...VerifyOption) (*CertificateVerificationResult, error) {
+	if err := c.VerifySignature(signature, message, opts...); err != nil {
+		return nil, err
+	}
+	id, err := CertificateIdentity(c.cert)
+	if err != nil {
+		return nil, fmt.Errorf("extracting signer identity: %w", err)
+	}
+	return &CertificateVerificationResult{
+		Certificate: c.cert,
+		Identity:    id,
+	}, nil
+}
+
+func (c *CertificateVerifier) applyPolicy(opts ...VerifyOption) error {
+	var policy options.CertificatePolicy
+	for _, opt := range opts {
+		opt.ApplyCertificatePolicy(&policy)
+	}
+	if policy != nil {
+		if err := policy(c.cert); err != nil {
+			return fmt.Errorf("certificate rejected by policy: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+	"github.com/sigstore/sigstore/test"
+)
+
+func TestCertificateVerifierPolicy(t *testing.T) {
+	rootCert, rootKey, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafCert, leafKey, err := test.GenerateLeafCert("subject@example.com", "oidc-issuer", rootCert, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("sign me")
+	signer, err := LoadECDSASigner(leafKey, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := LoadCertificateVerifier(leafCert)
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+	if v.Certificate() != leafCert {
+		t.Error("expected verifier to return the certificate it was loaded with")
+	}
+
+	errWrongIdentity := errors.New("wrong identity")
+	policy := func(email string) options.CertificatePolicy {
+		return func(c *x509.Certificate) error {
+			if len(c.EmailAddresses) != 1 || c.EmailAddresses[0] != email {
+				return errWrongIdentity
+			}
+			return nil
+		}
+	}
+
+	if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying without policy: %v", err)
+	}
+	if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithCertificatePolicy(policy("subject@example.com"))); err != nil {
+		t.Errorf("unexpected error verifying with accepting policy: %v", err)
+	}
+	if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithCertificatePolicy(policy("other@example.com"))); !errors.Is(err, errWrongIdentity) {
+		t.Errorf("expected policy error, got %v", err)
+	}
+
+	called := false
+	badPolicy := options.WithCertificatePolicy(func(_ *x509.Certificate) error {
+		called = true
+		return nil
+	})
+	if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("tampered")), badPolicy); err == nil {
+		t.Error("expected error verifying tampered message")
+	}
+	if called {
+		t.Error("policy must not be invoked when the signature does not verify")
+	}
+
+	if _, err := LoadCertificateVerifier(nil); err == nil {
+		t.Error("expected error loading verifier from nil certificate")
+	}
+}
+
+func TestCertificateVerifierIdentity(t *testing.T) {
+	rootCert, rootKey, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	workflow, err := url.Parse("https://github.com/org/repo/.github/workflows/release.yml@refs/heads/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafCert, leafKey, err := test.GenerateLeafCertWithSubjectAlternateNames(nil, []string{"subject@example.com"}, nil, []*url.URL{workflow}, "https://accounts.example.com", rootCert, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("sign me")
+	signer, err := LoadECDSASigner(leafKey, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := LoadCertificateVerifier(leafCert)
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+
+	res, err := v.Verify(bytes.NewReader(sig), bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error verifying: %v", err)
+	}
+	want := SignerIdentity{
+		Emails:     []string{"subject@example.com"},
+		URIs:       []string{workflow.String()},
+		OIDCIssuer: "https://accounts.example.com",
+	}
+	if !reflect.DeepEqual(res.Identity, want) {
+		t.Errorf("expected identity %+v, got %+v", want, res.Identity)
+	}
+	if res.Certificate != leafCert {
+		t.Error("expected result to include the verifying certificate")
+	}
+
+	if _, err := v.Verify(bytes.NewReader(sig), bytes.NewReader([]byte("tampered"))); err == nil {
+		t.Error("expected error verifying tampered message")
+	}
+	reject := options.WithCertificatePolicy(func(_ *x509.Certificate) error { return errors.New("rejected") })
+	if res, err := v.Verify(bytes.NewReader(sig), bytes.NewReader(message), reject); err == nil || res != nil {
+		t.Error("expected no result when the policy rejects the certificate")
+	}
+}
+
+func TestCertificateIdentityIssuer(t *testing.T) {
+	rootCert, rootKey, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerV2, err := asn1.MarshalWithParams("https://issuer.example.com", "utf8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the current issuer extension takes precedence over the deprecated one
+	cert, _, err := test.GenerateLeafCert("subject@example.com", "https://deprecated.example.com", rootCert, rootKey,
+		pkix.Extension{Id: cryptoutils.OIDIssuerV2, Value: issuerV2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := CertificateIdentity(cert)
+	if err != nil {
+		t.Fatalf("unexpected error extracting identity: %v", err)
+	}
+	if id.OIDCIssuer != "https://issuer.example.com" {
+		t.Errorf("expected issuer from current extension, got %q", id.OIDCIssuer)
+	}
+
+	cert, _, err = test.GenerateLeafCert("subject@example.com", "https://deprecated.example.com", rootCert, rootKey,
+		pkix.Extension{Id: cryptoutils.OIDIssuerV2, Value: []byte("not DER")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CertificateIdentity(cert); err == nil {
+		t.Error("expected error extracting identity with malformed issuer extension")
+	}
+	if _, err := CertificateIdentity(nil); err == nil {
+		t.Error("expected error extracting identity from nil certificate")
+	}
+}
@@ -0,0 +1,492 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+var (
+	oidData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+	oidRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidEd25519         = asn1.ObjectIdentifier{1, 3, 101, 112}
+
+	tagContext0     = cbasn1.Tag(0).ContextSpecific().Constructed()
+	tagContext1     = cbasn1.Tag(1).ContextSpecific().Constructed()
+	tagSubjectKeyID = cbasn1.Tag(0).ContextSpecific()
+)
+
+// algorithms are the digest and signature algorithms used for a given key
+type algorithms struct {
+	hash         crypto.Hash
+	digestOID    asn1.ObjectIdentifier
+	signatureOID asn1.ObjectIdentifier
+	// nullParams is set for signature algorithms whose parameters are an explicit NULL
+	nullParams bool
+	// pure is set for signature algorithms that sign the signed attributes directly, rather than their digest
+	pure bool
+}
+
+func algorithmsForKey(pub crypto.PublicKey) (*algorithms, error) {
+	switch pk := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch pk.Curve {
+		case elliptic.P256():
+			return &algorithms{hash: crypto.SHA256, digestOID: oidSHA256, signatureOID: oidECDSAWithSHA256}, nil
+		case elliptic.P384():
+			return &algorithms{hash: crypto.SHA384, digestOID: oidSHA384, signatureOID: oidECDSAWithSHA384}, nil
+		case elliptic.P521():
+			return &algorithms{hash: crypto.SHA512, digestOID: oidSHA512, signatureOID: oidECDSAWithSHA512}, nil
+		}
+		return nil, fmt.Errorf("unsupported elliptic curve %s", pk.Curve.Params().Name)
+	case *rsa.PublicKey:
+		return &algorithms{hash: crypto.SHA256, digestOID: oidSHA256, signatureOID: oidRSAEncryption, nullParams: true}, nil
+	case ed25519.PublicKey:
+		// RFC 8419 requires SHA-512 as the message digest algorithm for Ed25519
+		return &algorithms{hash: crypto.SHA512, digestOID: oidSHA512, signatureOID: oidEd25519, pure: true}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// Sign returns a DER-encoded CMS ContentInfo holding a detached SignedData signature by s over
+// message. certs is the signer's certificate chain, starting with the certificate for the
+// signer's key, and is included in the SignedData.
+//
+// The digest and signature algorithms are selected from the key: SHA-256, SHA-384 or SHA-512
+// with ECDSA on P-256, P-384 or P-521; SHA-256 with RSA PKCS#1 v1.5; and SHA-512 with Ed25519.
+// RSA-PSS signers are not supported.
+func Sign(s signature.Signer, certs []*x509.Certificate, message io.Reader, opts ...signature.SignOption) ([]byte, error) {
+	if len(certs) == 0 || certs[0] == nil {
+		return nil, errors.New("the signer's certificate must be provided")
+	}
+	switch s.(type) {
+	case *signature.RSAPSSSigner, *signature.RSAPSSSignerVerifier:
+		return nil, errors.New("RSA-PSS signers are not supported")
+	}
+	pub, err := s.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("getting public key: %w", err)
+	}
+	if err := cryptoutils.EqualKeys(pub, certs[0].PublicKey); err != nil {
+		return nil, fmt.Errorf("signer does not match certificate: %w", err)
+	}
+	alg, err := algorithmsForKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := hashReader(alg.hash, message)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := marshalSignedAttributes(digest)
+	if err != nil {
+		return nil, err
+	}
+	toSign, err := signedAttributesForSigning(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	var sig []byte
+	if alg.pure {
+		sig, err = s.SignMessage(bytes.NewReader(toSign), opts...)
+	} else {
+		attrsDigest, herr := hashReader(alg.hash, bytes.NewReader(toSign))
+		if herr != nil {
+			return nil, herr
+		}
+		sig, err = s.SignMessage(nil, append(opts[:len(opts):len(opts)], options.WithDigest(attrsDigest), options.WithCryptoSignerOpts(alg.hash))...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { // ContentInfo
+		b.AddASN1ObjectIdentifier(oidSignedData)
+		b.AddASN1(tagContext0, func(b *cryptobyte.Builder) {
+			b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { // SignedData
+				b.AddASN1Int64(1)
+				b.AddASN1(cbasn1.SET, func(b *cryptobyte.Builder) {
+					addAlgorithmIdentifier(b, alg.digestOID, false)
+				})
+				b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { // EncapsulatedContentInfo, detached
+					b.AddASN1ObjectIdentifier(oidData)
+				})
+				b.AddASN1(tagContext0, func(b *cryptobyte.Builder) {
+					for _, c := range certs {
+						b.AddBytes(c.Raw)
+					}
+				})
+				b.AddASN1(cbasn1.SET, func(b *cryptobyte.Builder) {
+					b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { // SignerInfo
+						b.AddASN1Int64(1)
+						b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { // IssuerAndSerialNumber
+							b.AddBytes(certs[0].RawIssuer)
+							b.AddASN1BigInt(certs[0].SerialNumber)
+						})
+						addAlgorithmIdentifier(b, alg.digestOID, false)
+						b.AddASN1(tagContext0, func(b *cryptobyte.Builder) {
+							b.AddBytes(attrs)
+						})
+						addAlgorithmIdentifier(b, alg.signatureOID, alg.nullParams)
+						b.AddASN1OctetString(sig)
+					})
+				})
+			})
+		})
+	})
+	return b.Bytes()
+}
+
+// Verify verifies a DER-encoded CMS ContentInfo holding a detached SignedData signature over
+// message, as produced by Sign, and returns the signer's certificate. The SignedData must have
+// exactly one signer, whose certificate must be included in it. That certificate is verified
+// against opts, with the other included certificates added to a copy of opts.Intermediates.
+//
+// As with x509.Certificate.Verify, opts.KeyUsages defaults to server authentication, so it
+// must be set to verify code signing certificates.
+func Verify(der []byte, message io.Reader, opts x509.VerifyOptions) (*x509.Certificate, error) {
+	sd, err := ParseSignedData(der)
+	if err != nil {
+		return nil, err
+	}
+	if !sd.ContentType.Equal(oidData) {
+		return nil, fmt.Errorf("unexpected encapsulated content type %v", sd.ContentType)
+	}
+	if sd.Content != nil {
+		return nil, errors.New("only detached signatures are supported")
+	}
+	if sd.Issuer == nil {
+		return nil, errors.New("an IssuerAndSerialNumber signer identifier is required")
+	}
+
+	signer := sd.SignerCertificate()
+	if signer == nil {
+		return nil, errors.New("signer certificate not found in SignedData")
+	}
+
+	alg, err := algorithmsForKey(signer.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !sd.DigestAlgorithm.Equal(alg.digestOID) || !sd.SignatureAlgorithm.Equal(alg.signatureOID) {
+		return nil, fmt.Errorf("unexpected digest algorithm %v or signature algorithm %v for %T key", sd.DigestAlgorithm, sd.SignatureAlgorithm, signer.PublicKey)
+	}
+
+	if err := sd.CheckSignedAttributes(oidData, alg.hash, message); err != nil {
+		return nil, err
+	}
+	toVerify, err := sd.SignedBytes()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := signature.LoadVerifier(signer.PublicKey, alg.hash)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifier.VerifySignature(bytes.NewReader(sd.Signature), bytes.NewReader(toVerify)); err != nil {
+		return nil, err
+	}
+
+	// copy the pool so the caller's opts.Intermediates is not modified
+	intermediates := x509.NewCertPool()
+	if opts.Intermediates != nil {
+		intermediates = opts.Intermediates.Clone()
+	}
+	opts.Intermediates = intermediates
+	for _, c := range sd.Certificates {
+		if c != signer {
+			opts.Intermediates.AddCert(c)
+		}
+	}
+	if _, err := signer.Verify(opts); err != nil {
+		return nil, fmt.Errorf("verifying signer certificate: %w", err)
+	}
+	return signer, nil
+}
+
+func hashReader(hf crypto.Hash, r io.Reader) ([]byte, error) {
+	if r == nil {
+		return nil, errors.New("message cannot be nil")
+	}
+	h := hf.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func addAlgorithmIdentifier(b *cryptobyte.Builder, oid asn1.ObjectIdentifier, nullParams bool) {
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1ObjectIdentifier(oid)
+		if nullParams {
+			b.AddASN1NULL()
+		}
+	})
+}
+
+// marshalSignedAttributes returns the DER-encoded content-type and message-digest attributes,
+// without the enclosing SET header
+func marshalSignedAttributes(digest []byte) ([]byte, error) {
+	contentType, err := marshalAttribute(oidContentType, func(b *cryptobyte.Builder) {
+		b.AddASN1ObjectIdentifier(oidData)
+	})
+	if err != nil {
+		return nil, err
+	}
+	messageDigest, err := marshalAttribute(oidMessageDigest, func(b *cryptobyte.Builder) {
+		b.AddASN1OctetString(digest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// DER requires the elements of a SET OF to be sorted by their encoding
+	attrs := [][]byte{contentType, messageDigest}
+	sort.Slice(attrs, func(i, j int) bool { return bytes.Compare(attrs[i], attrs[j]) < 0 })
+	return bytes.Join(attrs, nil), nil
+}
+
+func marshalAttribute(oid asn1.ObjectIdentifier, value cryptobyte.BuilderContinuation) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1ObjectIdentifier(oid)
+		b.AddASN1(cbasn1.SET, value)
+	})
+	return b.Bytes()
+}
+
+// signedAttributesForSigning returns the encoding of the signed attributes that is signed,
+// which uses an explicit SET OF tag rather than the implicit [0] tag in the SignerInfo
+func signedAttributesForSigning(attrs []byte) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SET, func(b *cryptobyte.Builder) {
+		b.AddBytes(attrs)
+	})
+	return b.Bytes()
+}
+
+// CheckSignedAttributes checks that the content-type signed attribute is contentType and that
+// the message-digest signed attribute is the digest of content with hf
+func (sd *SignedData) CheckSignedAttributes(contentType asn1.ObjectIdentifier, hf crypto.Hash, content io.Reader) error {
+	var attrContentType asn1.ObjectIdentifier
+	var messageDigest []byte
+	input := cryptobyte.String(sd.SignedAttributes)
+	for !input.Empty() {
+		var attr, values cryptobyte.String
+		var oid asn1.ObjectIdentifier
+		if !input.ReadASN1(&attr, cbasn1.SEQUENCE) ||
+			!attr.ReadASN1ObjectIdentifier(&oid) ||
+			!attr.ReadASN1(&values, cbasn1.SET) ||
+			!attr.Empty() {
+			return errors.New("malformed signed attribute")
+		}
+		switch {
+		case oid.Equal(oidContentType):
+			if !values.ReadASN1ObjectIdentifier(&attrContentType) || !values.Empty() {
+				return errors.New("malformed content-type attribute")
+			}
+		case oid.Equal(oidMessageDigest):
+			var digest cryptobyte.String
+			if !values.ReadASN1(&digest, cbasn1.OCTET_STRING) || !values.Empty() {
+				return errors.New("malformed message-digest attribute")
+			}
+			messageDigest = digest
+		}
+	}
+	if !attrContentType.Equal(contentType) {
+		return fmt.Errorf("unexpected content type %v in signed attributes", attrContentType)
+	}
+	if messageDigest == nil {
+		return errors.New("missing message-digest attribute")
+	}
+	digest, err := hashReader(hf, content)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(digest, messageDigest) {
+		return errors.New("message digest does not match signed attributes")
+	}
+	return nil
+}
+
+// SignedBytes returns the encoding of the signed attributes over which the signature was
+// computed, which uses an explicit SET OF tag rather than the implicit [0] tag in the SignerInfo
+func (sd *SignedData) SignedBytes() ([]byte, error) {
+	return signedAttributesForSigning(sd.SignedAttributes)
+}
+
+// SignerCertificate returns the certificate in sd.Certificates identified by the SignerInfo,
+// or nil if it is not included
+func (sd *SignedData) SignerCertificate() *x509.Certificate {
+	for _, c := range sd.Certificates {
+		if sd.Issuer != nil && bytes.Equal(c.RawIssuer, sd.Issuer) && c.SerialNumber.Cmp(sd.SerialNumber) == 0 {
+			return c
+		}
+		if sd.SubjectKeyID != nil && bytes.Equal(c.SubjectKeyId, sd.SubjectKeyID) {
+			return c
+		}
+	}
+	return nil
+}
+
+// SignedData holds the fields of a CMS SignedData with a single signer that are needed to
+// verify it. It is returned by ParseSignedData, which checks only the structure; the
+// signature, signed attributes and certificates must be checked by the caller.
+type SignedData struct {
+	// ContentType is the type of the encapsulated content, e.g. id-data
+	ContentType asn1.ObjectIdentifier
+	// Content is the encapsulated content, or nil for a detached signature
+	Content []byte
+	// Certificates are the certificates included in the SignedData
+	Certificates []*x509.Certificate
+	// Issuer and SerialNumber identify the signer's certificate if the SignerInfo uses an
+	// IssuerAndSerialNumber; Issuer is the DER-encoded issuer name and is nil otherwise
+	Issuer       []byte
+	SerialNumber *big.Int
+	// SubjectKeyID identifies the signer's certificate if the SignerInfo uses a
+	// SubjectKeyIdentifier, and is nil otherwise
+	SubjectKeyID []byte
+	// DigestAlgorithm is the SignerInfo digest algorithm
+	DigestAlgorithm asn1.ObjectIdentifier
+	// SignedAttributes are the DER-encoded signed attributes, without the enclosing [0] header
+	SignedAttributes []byte
+	// SignatureAlgorithm is the SignerInfo signature algorithm
+	SignatureAlgorithm asn1.ObjectIdentifier
+	// Signature is the signature over the signed attributes
+	Signature []byte
+}
+
+// ParseSignedData parses a DER-encoded CMS ContentInfo holding a SignedData with exactly one
+// signer, which must have signed attributes. The signer may be identified by an
+// IssuerAndSerialNumber or a SubjectKeyIdentifier.
+func ParseSignedData(der []byte) (*SignedData, error) {
+	var contentInfo, content, sd, digestAlgs, encap, certs, signerInfos, signerInfo, digestAlg, attrs, sigAlg, sig cryptobyte.String
+	var contentType asn1.ObjectIdentifier
+	var version, signerVersion int64
+	var hasCerts bool
+	out := &SignedData{}
+
+	input := cryptobyte.String(der)
+	if !input.ReadASN1(&contentInfo, cbasn1.SEQUENCE) || !input.Empty() ||
+		!contentInfo.ReadASN1ObjectIdentifier(&contentType) ||
+		!contentInfo.ReadASN1(&content, tagContext0) || !contentInfo.Empty() ||
+		!content.ReadASN1(&sd, cbasn1.SEQUENCE) || !content.Empty() {
+		return nil, errors.New("malformed CMS ContentInfo")
+	}
+	if !contentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("unexpected CMS content type %v", contentType)
+	}
+
+	if !sd.ReadASN1Integer(&version) ||
+		!sd.ReadASN1(&digestAlgs, cbasn1.SET) ||
+		!sd.ReadASN1(&encap, cbasn1.SEQUENCE) ||
+		!encap.ReadASN1ObjectIdentifier(&out.ContentType) ||
+		!sd.ReadOptionalASN1(&certs, &hasCerts, tagContext0) ||
+		!sd.SkipOptionalASN1(tagContext1) ||
+		!sd.ReadASN1(&signerInfos, cbasn1.SET) || !sd.Empty() {
+		return nil, errors.New("malformed CMS SignedData")
+	}
+	if !encap.Empty() {
+		var eContentWrapper, eContent cryptobyte.String
+		if !encap.ReadASN1(&eContentWrapper, tagContext0) || !encap.Empty() ||
+			!eContentWrapper.ReadASN1(&eContent, cbasn1.OCTET_STRING) || !eContentWrapper.Empty() {
+			return nil, errors.New("malformed CMS EncapsulatedContentInfo")
+		}
+		out.Content = eContent
+	}
+	for !certs.Empty() {
+		var c cryptobyte.String
+		if !certs.ReadASN1Element(&c, cbasn1.SEQUENCE) {
+			// other certificate formats (e.g. attribute certificates) are not supported
+			return nil, errors.New("malformed certificate in SignedData")
+		}
+		cert, err := x509.ParseCertificate(c)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificate in SignedData: %w", err)
+		}
+		out.Certificates = append(out.Certificates, cert)
+	}
+
+	if !signerInfos.ReadASN1(&signerInfo, cbasn1.SEQUENCE) || !signerInfos.Empty() {
+		return nil, errors.New("exactly one SignerInfo is required")
+	}
+	if !signerInfo.ReadASN1Integer(&signerVersion) {
+		return nil, errors.New("malformed CMS SignerInfo")
+	}
+	switch {
+	case signerInfo.PeekASN1Tag(cbasn1.SEQUENCE):
+		var sid, issuer cryptobyte.String
+		out.SerialNumber = new(big.Int)
+		if signerVersion != 1 ||
+			!signerInfo.ReadASN1(&sid, cbasn1.SEQUENCE) ||
+			!sid.ReadASN1Element(&issuer, cbasn1.SEQUENCE) ||
+			!sid.ReadASN1Integer(out.SerialNumber) || !sid.Empty() {
+			return nil, errors.New("malformed SignerInfo IssuerAndSerialNumber")
+		}
+		out.Issuer = issuer
+	case signerInfo.PeekASN1Tag(tagSubjectKeyID):
+		var skid cryptobyte.String
+		if signerVersion != 3 || !signerInfo.ReadASN1(&skid, tagSubjectKeyID) {
+			return nil, errors.New("malformed SignerInfo SubjectKeyIdentifier")
+		}
+		out.SubjectKeyID = skid
+	default:
+		return nil, errors.New("unsupported SignerInfo signer identifier")
+	}
+	if !signerInfo.ReadASN1(&digestAlg, cbasn1.SEQUENCE) ||
+		!digestAlg.ReadASN1ObjectIdentifier(&out.DigestAlgorithm) ||
+		!signerInfo.ReadASN1(&attrs, tagContext0) ||
+		!signerInfo.ReadASN1(&sigAlg, cbasn1.SEQUENCE) ||
+		!sigAlg.ReadASN1ObjectIdentifier(&out.SignatureAlgorithm) ||
+		!signerInfo.ReadASN1(&sig, cbasn1.OCTET_STRING) {
+		return nil, errors.New("malformed CMS SignerInfo; signed attributes are required")
+	}
+	out.SignedAttributes = attrs
+	out.Signature = sig
+	return out, nil
+}
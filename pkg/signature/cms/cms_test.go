@@ -0,0 +1,177 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/test"
+)
+
+func selfSignedCert(t *testing.T, priv crypto.Signer) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signer"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, priv.Public(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestSignAndVerify(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	message := []byte("sign me")
+	opts := func(root *x509.Certificate) x509.VerifyOptions {
+		roots := x509.NewCertPool()
+		roots.AddCert(root)
+		return x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}}
+	}
+
+	for name, priv := range map[string]crypto.Signer{"ecdsa": ecKey, "rsa": rsaKey, "ed25519": edKey} {
+		t.Run(name, func(t *testing.T) {
+			cert := selfSignedCert(t, priv)
+			s, err := signature.LoadSigner(priv, crypto.SHA256)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			der, err := Sign(s, []*x509.Certificate{cert}, bytes.NewReader(message))
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			got, err := Verify(der, bytes.NewReader(message), opts(cert))
+			if err != nil {
+				t.Fatalf("unexpected error verifying: %v", err)
+			}
+			if !got.Equal(cert) {
+				t.Error("expected the signer certificate to be returned")
+			}
+
+			if _, err := Verify(der, bytes.NewReader([]byte("tampered")), opts(cert)); err == nil {
+				t.Error("expected error verifying a different message")
+			}
+			otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			other := selfSignedCert(t, otherKey)
+			if _, err := Verify(der, bytes.NewReader(message), opts(other)); err == nil {
+				t.Error("expected error verifying against an untrusted root")
+			}
+		})
+	}
+}
+
+func TestSignWithChain(t *testing.T) {
+	rootCert, rootKey, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subCert, subKey, err := test.GenerateSubordinateCa(rootCert, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafCert, leafKey, err := test.GenerateLeafCert("subject@example.com", "oidc-issuer", subCert, subKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := signature.LoadECDSASignerVerifier(leafKey, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("sign me")
+	der, err := Sign(s, []*x509.Certificate{leafCert, subCert}, bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(rootCert)
+	intermediates := x509.NewCertPool()
+	if _, err := Verify(der, bytes.NewReader(message), x509.VerifyOptions{Roots: roots, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}}); err != nil {
+		t.Fatalf("unexpected error verifying: %v", err)
+	}
+	if !intermediates.Equal(x509.NewCertPool()) {
+		t.Error("Verify added certificates to the caller's intermediates pool")
+	}
+
+	if _, err := Sign(s, []*x509.Certificate{subCert}, bytes.NewReader(message)); err == nil {
+		t.Error("expected error signing with a certificate for a different key")
+	}
+}
+
+func TestParseSignedData(t *testing.T) {
+	priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	cert := selfSignedCert(t, priv)
+	s, err := signature.LoadSigner(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("sign me")
+	der, err := Sign(s, []*x509.Certificate{cert}, bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+
+	sd, err := ParseSignedData(der)
+	if err != nil {
+		t.Fatalf("unexpected error parsing: %v", err)
+	}
+	if !sd.ContentType.Equal(oidData) || sd.Content != nil {
+		t.Errorf("expected detached id-data content, got %v with %d bytes", sd.ContentType, len(sd.Content))
+	}
+	if c := sd.SignerCertificate(); c == nil || !c.Equal(cert) {
+		t.Error("expected the signer certificate to be identified")
+	}
+	if !sd.DigestAlgorithm.Equal(oidSHA256) || !sd.SignatureAlgorithm.Equal(oidECDSAWithSHA256) {
+		t.Errorf("unexpected algorithms %v, %v", sd.DigestAlgorithm, sd.SignatureAlgorithm)
+	}
+	if err := sd.CheckSignedAttributes(oidData, crypto.SHA256, bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error checking signed attributes: %v", err)
+	}
+	if err := sd.CheckSignedAttributes(oidData, crypto.SHA256, bytes.NewReader([]byte("other"))); err == nil {
+		t.Error("expected error checking signed attributes against a different message")
+	}
+
+	if _, err := ParseSignedData(der[:len(der)-1]); err == nil {
+		t.Error("expected error parsing truncated SignedData")
+	}
+}
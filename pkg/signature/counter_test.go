@@ -0,0 +1,77 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestCounterSigner(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+
+	counters := []uint64{5, 6, 6}
+	cs, err := NewCounterSigner(sv, func() (uint64, error) {
+		c := counters[0]
+		counters = counters[1:]
+		return c, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating counter signer: %v", err)
+	}
+
+	message := []byte("sign me")
+	counter, sig, err := cs.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error signing message: %v", err)
+	}
+	if counter != 5 {
+		t.Errorf("expected counter 5, got %d", counter)
+	}
+	if err := VerifyWithCounter(sv, bytes.NewReader(sig), bytes.NewReader(message), counter, 4); err != nil {
+		t.Errorf("unexpected error verifying signature: %v", err)
+	}
+	if err := VerifyWithCounter(sv, bytes.NewReader(sig), bytes.NewReader(message), counter, 5); !errors.Is(err, ErrCounterNotIncreasing) {
+		t.Errorf("expected ErrCounterNotIncreasing for replayed counter, got %v", err)
+	}
+	if err := VerifyWithCounter(sv, bytes.NewReader(sig), bytes.NewReader(message), counter+1, 4); err == nil {
+		t.Error("expected error verifying with a different counter")
+	}
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err == nil {
+		t.Error("expected error verifying without the counter")
+	}
+
+	// a plain signature over a message that starts with a valid counter is not a counter signature
+	plain, err := sv.SignMessage(bytes.NewReader(append(binary.BigEndian.AppendUint64(nil, 7), message...)))
+	if err != nil {
+		t.Fatalf("unexpected error signing message: %v", err)
+	}
+	if err := VerifyWithCounter(sv, bytes.NewReader(plain), bytes.NewReader(message), 7, 5); err == nil {
+		t.Error("expected error verifying a plain signature as a counter signature")
+	}
+
+	if _, _, err := cs.SignMessage(bytes.NewReader(message)); err != nil {
+		t.Fatalf("unexpected error signing with increasing counter: %v", err)
+	}
+	if _, _, err := cs.SignMessage(bytes.NewReader(message)); !errors.Is(err, ErrCounterNotIncreasing) {
+		t.Errorf("expected ErrCounterNotIncreasing for repeated counter, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrDeprecatedAlgorithm is returned in strict deprecation mode when a key or hash function that
+// is slated for removal is requested
+var ErrDeprecatedAlgorithm = errors.New("algorithm is deprecated")
+
+// MinNonDeprecatedRSAKeySize is the smallest RSA modulus size, in bits, that is not deprecated
+const MinNonDeprecatedRSAKeySize = 2048
+
+// DeprecationNotice describes a deprecated algorithm used by a Signer or Verifier
+type DeprecationNotice struct {
+	// Algorithm names the deprecated key type, size or hash function, e.g. "SHA-1" or "RSA-1024"
+	Algorithm string
+	// Reason is a short, human-readable explanation of why the algorithm is deprecated
+	Reason string
+}
+
+// String returns a human-readable description of the notice
+func (n DeprecationNotice) String() string {
+	return fmt.Sprintf("%s is deprecated: %s", n.Algorithm, n.Reason)
+}
+
+// DeprecationHandler receives deprecation notices. It is called synchronously from the constructor
+// of the Signer or Verifier that uses the deprecated algorithm, so it should not block.
+type DeprecationHandler func(DeprecationNotice)
+
+var (
+	deprecationHandler atomic.Pointer[DeprecationHandler]
+	deprecationStrict  atomic.Bool
+)
+
+// SetDeprecationHandler sets the function that receives deprecation notices. By default, and when
+// h is nil, notices are discarded; callers that want warnings, e.g. written to a logger, must
+// install a handler.
+func SetDeprecationHandler(h DeprecationHandler) {
+	deprecationHandler.Store(&h)
+}
+
+// DeprecationStrict reports whether deprecated algorithms are rejected rather than warned about
+func DeprecationStrict() bool {
+	return deprecationStrict.Load()
+}
+
+// SetDeprecationStrict enables or disables strict deprecation mode. When enabled, loading a Signer
+// or Verifier for a deprecated key or hash function fails with an error wrapping
+// ErrDeprecatedAlgorithm instead of emitting a notice.
+func SetDeprecationStrict(enabled bool) {
+	deprecationStrict.Store(enabled)
+}
+
+// ClassifyDeprecation returns a notice for each deprecated property of publicKey and hashFunc:
+// RSA keys smaller than MinNonDeprecatedRSAKeySize bits, and SHA-1 as the digest for RSA or
+// ECDSA signatures. It returns nil if nothing is deprecated.
+func ClassifyDeprecation(publicKey crypto.PublicKey, hashFunc crypto.Hash) []DeprecationNotice {
+	var notices []DeprecationNotice
+	switch pk := publicKey.(type) {
+	case *rsa.PublicKey:
+		if pk != nil && pk.N != nil && pk.N.BitLen() < MinNonDeprecatedRSAKeySize {
+			notices = append(notices, DeprecationNotice{
+				Algorithm: fmt.Sprintf("RSA-%d", pk.N.BitLen()),
+				Reason:    fmt.Sprintf("RSA keys should be at least %d bits", MinNonDeprecatedRSAKeySize),
+			})
+		}
+	case *ecdsa.PublicKey:
+	default:
+		// the hash function is only meaningful for RSA and ECDSA keys
+		return notices
+	}
+	if hashFunc == crypto.SHA1 {
+		notices = append(notices, DeprecationNotice{
+			Algorithm: "SHA-1",
+			Reason:    "SHA-1 is not collision resistant; use SHA-256 or stronger",
+		})
+	}
+	return notices
+}
+
+// deprecationCheck emits a notice for each deprecated property of publicKey and hashFunc, or
+// returns an error wrapping ErrDeprecatedAlgorithm in strict mode. It is called once from each
+// Signer and Verifier constructor with the configured hash function, so every instance warns at
+// most once; a hash function overridden per call with WithCryptoSignerOpts is not checked.
+func deprecationCheck(publicKey crypto.PublicKey, hashFunc crypto.Hash) error {
+	notices := ClassifyDeprecation(publicKey, hashFunc)
+	if len(notices) == 0 {
+		return nil
+	}
+	if DeprecationStrict() {
+		return fmt.Errorf("%w: %s", ErrDeprecatedAlgorithm, notices[0].Algorithm)
+	}
+	if h := deprecationHandler.Load(); h != nil && *h != nil {
+		for _, n := range notices {
+			(*h)(n)
+		}
+	}
+	return nil
+}
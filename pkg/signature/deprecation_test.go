@@ -0,0 +1,132 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+)
+
+func TestClassifyDeprecation(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsa2048, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsa1024, _ := rsa.GenerateKey(rand.Reader, 1024)
+	edPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	for _, tt := range []struct {
+		name string
+		pub  crypto.PublicKey
+		hash crypto.Hash
+		want []string
+	}{
+		{"ecdsa p256 sha256", &p256.PublicKey, crypto.SHA256, nil},
+		{"ecdsa p256 sha1", &p256.PublicKey, crypto.SHA1, []string{"SHA-1"}},
+		{"rsa 2048 sha256", &rsa2048.PublicKey, crypto.SHA256, nil},
+		{"rsa 1024 sha256", &rsa1024.PublicKey, crypto.SHA256, []string{"RSA-1024"}},
+		{"rsa 1024 sha1", &rsa1024.PublicKey, crypto.SHA1, []string{"RSA-1024", "SHA-1"}},
+		{"ed25519", edPub, crypto.SHA1, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			notices := ClassifyDeprecation(tt.pub, tt.hash)
+			if len(notices) != len(tt.want) {
+				t.Fatalf("got %v, want %v", notices, tt.want)
+			}
+			for i, n := range notices {
+				if n.Algorithm != tt.want[i] {
+					t.Errorf("notice %d: got %q, want %q", i, n.Algorithm, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeprecationDefaultHandler(t *testing.T) {
+	// without a handler, notices are discarded and loading succeeds
+	deprecationHandler.Store(nil)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := LoadUnsafeVerifier(&p256.PublicKey); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	SetDeprecationHandler(nil)
+	if _, err := LoadUnsafeVerifier(&p256.PublicKey); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDeprecationWarnings(t *testing.T) {
+	var notices []DeprecationNotice
+	SetDeprecationHandler(func(n DeprecationNotice) { notices = append(notices, n) })
+	t.Cleanup(func() { SetDeprecationHandler(nil) })
+
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := LoadECDSAVerifier(&p256.PublicKey, crypto.SHA256); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notices) != 0 {
+		t.Fatalf("expected no notices for SHA-256, got %v", notices)
+	}
+
+	v, err := LoadUnsafeVerifier(&p256.PublicKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notices) != 1 || notices[0].Algorithm != "SHA-1" {
+		t.Fatalf("expected one SHA-1 notice, got %v", notices)
+	}
+	// using the instance does not warn again
+	if _, err := v.PublicKey(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notices) != 1 {
+		t.Fatalf("expected the notice to be emitted once, got %v", notices)
+	}
+
+	rsa1024, _ := rsa.GenerateKey(rand.Reader, 1024)
+	if _, err := LoadRSAPKCS1v15Signer(rsa1024, crypto.SHA256); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notices) != 2 || notices[1].Algorithm != "RSA-1024" {
+		t.Fatalf("expected an RSA-1024 notice, got %v", notices)
+	}
+
+	SetDeprecationHandler(nil)
+	if _, err := LoadUnsafeVerifier(&rsa1024.PublicKey); err != nil {
+		t.Fatalf("unexpected error with nil handler: %v", err)
+	}
+}
+
+func TestDeprecationStrict(t *testing.T) {
+	SetDeprecationStrict(true)
+	t.Cleanup(func() { SetDeprecationStrict(false) })
+
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsa1024, _ := rsa.GenerateKey(rand.Reader, 1024)
+	if _, err := LoadRSAPSSVerifier(&rsa1024.PublicKey, crypto.SHA256, nil); !errors.Is(err, ErrDeprecatedAlgorithm) {
+		t.Errorf("expected ErrDeprecatedAlgorithm, got %v", err)
+	}
+	if _, err := LoadUnsafeVerifier(&p256.PublicKey); !errors.Is(err, ErrDeprecatedAlgorithm) {
+		t.Errorf("expected ErrDeprecatedAlgorithm, got %v", err)
+	}
+	if _, err := LoadECDSAVerifier(&p256.PublicKey, crypto.SHA256); err != nil {
+		t.Errorf("unexpected error for non-deprecated algorithm: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DigestSet is a set of digests of the same content computed with different hash functions,
+// allowing a single signature to attest to multiple content addresses.
+type DigestSet map[crypto.Hash][]byte
+
+var digestSetNames = map[crypto.Hash]string{
+	crypto.SHA256: "sha256",
+	crypto.SHA384: "sha384",
+	crypto.SHA512: "sha512",
+}
+
+// MarshalCanonical returns the deterministic serialization of the digest set that is signed:
+// a JSON object mapping the lowercase algorithm name (e.g. "sha256") to the hex-encoded digest,
+// with keys in sorted order and no insignificant whitespace.
+func (d DigestSet) MarshalCanonical() ([]byte, error) {
+	if len(d) == 0 {
+		return nil, errors.New("digest set cannot be empty")
+	}
+	m := make(map[string]string, len(d))
+	for hf, digest := range d {
+		name, ok := digestSetNames[hf]
+		if !ok {
+			return nil, fmt.Errorf("unsupported hash algorithm in digest set: %q", hf.String())
+		}
+		if len(digest) != hf.Size() {
+			return nil, fmt.Errorf("unexpected length of %s digest: %d", name, len(digest))
+		}
+		m[name] = hex.EncodeToString(digest)
+	}
+	// encoding/json sorts map keys, which makes the output deterministic
+	return json.Marshal(m)
+}
+
+// SignDigestSet signs the canonical serialization of digests (see DigestSet.MarshalCanonical).
+func SignDigestSet(s Signer, digests DigestSet, opts ...SignOption) ([]byte, error) {
+	payload, err := digests.MarshalCanonical()
+	if err != nil {
+		return nil, err
+	}
+	return s.SignMessage(bytes.NewReader(payload), opts...)
+}
+
+// VerifyDigestSet verifies a signature created by SignDigestSet over the same set of digests.
+func VerifyDigestSet(v Verifier, sig []byte, digests DigestSet, opts ...VerifyOption) error {
+	payload, err := digests.MarshalCanonical()
+	if err != nil {
+		return err
+	}
+	return v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(payload), opts...)
+}
@@ -0,0 +1,67 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestDigestSet(t *testing.T) {
+	content := []byte("content")
+	sha256Digest := sha256.Sum256(content)
+	sha512Digest := sha512.Sum512(content)
+	digests := DigestSet{
+		crypto.SHA512: sha512Digest[:],
+		crypto.SHA256: sha256Digest[:],
+	}
+
+	canonical, err := digests.MarshalCanonical()
+	if err != nil {
+		t.Fatalf("unexpected error marshalling digest set: %v", err)
+	}
+	expected := `{"sha256":"ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73","sha512":"b2d1d285b5199c85f988d03649c37e44fd3dde01e5d69c50fef90651962f48110e9340b60d49a479c4c0b53f5f07d690686dd87d2481937a512e8b85ee7c617f"}`
+	if string(canonical) != expected {
+		t.Errorf("unexpected canonical serialization:\ngot:  %s\nwant: %s", canonical, expected)
+	}
+
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	sig, err := SignDigestSet(sv, digests)
+	if err != nil {
+		t.Fatalf("unexpected error signing digest set: %v", err)
+	}
+	if err := VerifyDigestSet(sv, sig, digests); err != nil {
+		t.Errorf("unexpected error verifying digest set: %v", err)
+	}
+	if err := VerifyDigestSet(sv, sig, DigestSet{crypto.SHA256: sha256Digest[:]}); err == nil {
+		t.Error("expected error verifying a subset of the signed digests")
+	}
+
+	for name, ds := range map[string]DigestSet{
+		"empty":       {},
+		"unsupported": {crypto.SHA1: make([]byte, crypto.SHA1.Size())},
+		"bad length":  {crypto.SHA256: sha512Digest[:]},
+	} {
+		if _, err := ds.MarshalCanonical(); err == nil {
+			t.Errorf("%s: expected error marshalling digest set", name)
+		}
+	}
+}
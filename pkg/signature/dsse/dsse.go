@@ -21,7 +21,9 @@ import (
 	"crypto"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/secure-systems-lab/go-securesystemslib/dsse"
 	"github.com/sigstore/sigstore/pkg/signature"
@@ -51,22 +53,48 @@ func (w *wrappedSigner) SignMessage(r io.Reader, opts ...signature.SignOption) (
 	if err != nil {
 		return nil, err
 	}
-	pae := dsse.PAE(w.payloadType, p)
-	sig, err := w.s.SignMessage(bytes.NewReader(pae), opts...)
-	if err != nil {
+	var env bytes.Buffer
+	if err := SignEnvelopeTo(&env, w.s, w.payloadType, p, opts...); err != nil {
 		return nil, err
 	}
+	return env.Bytes(), nil
+}
 
-	env := dsse.Envelope{
-		PayloadType: w.payloadType,
-		Payload:     base64.StdEncoding.EncodeToString(p),
-		Signatures: []dsse.Signature{
-			{
-				Sig: base64.StdEncoding.EncodeToString(sig),
-			},
-		},
+// SignEnvelopeTo writes the DSSE envelope for payload signed by s to w, encoded exactly as by
+// the SignMessage method of WrapSigner(s, payloadType). The pre-authentication encoding and the
+// base64 encoding of payload are streamed rather than built as copies of it, so a large payload
+// is only held in memory once. Nothing is written to w if signing fails.
+func SignEnvelopeTo(w io.Writer, s signature.Signer, payloadType string, payload []byte, opts ...signature.SignOption) error {
+	pae := io.MultiReader(
+		strings.NewReader(fmt.Sprintf("DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))),
+		bytes.NewReader(payload),
+	)
+	sig, err := s.SignMessage(pae, opts...)
+	if err != nil {
+		return err
+	}
+
+	// the fields are written in the order, and with the encoding, that json.Marshal uses for dsse.Envelope
+	encodedType, err := json.Marshal(payloadType)
+	if err != nil {
+		return err
+	}
+	signatures, err := json.Marshal([]dsse.Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}})
+	if err != nil {
+		return err
 	}
-	return json.Marshal(env)
+	if _, err := io.WriteString(w, `{"payloadType":`+string(encodedType)+`,"payload":"`); err != nil {
+		return err
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	if _, err := enc.Write(payload); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `","signatures":`+string(signatures)+`}`)
+	return err
 }
 
 // WrapVerifier returns a signature.Verifier that uses the DSSE encoding format
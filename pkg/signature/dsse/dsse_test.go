@@ -182,3 +182,38 @@ func TestInvalidSignature(t *testing.T) {
 		t.Fatalf("Did not fail verification on bogus signature")
 	}
 }
+
+func TestSignEnvelopeTo(t *testing.T) {
+	sv, _, err := signature.NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	payloadType := "application/vnd.in-toto+json; note=<escaped>"
+	payload := bytes.Repeat([]byte(`{"large":"payload"}`), 10000)
+
+	var buf bytes.Buffer
+	if err := SignEnvelopeTo(&buf, sv, payloadType, payload); err != nil {
+		t.Fatalf("unexpected error signing to writer: %v", err)
+	}
+
+	// Ed25519 signatures are deterministic, so the envelope must match one assembled in memory
+	sig, err := sv.SignMessage(bytes.NewReader(dsse.PAE(payloadType, payload)))
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	want, err := json.Marshal(dsse.Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []dsse.Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error marshalling envelope: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Error("SignEnvelopeTo output does not match the in-memory envelope")
+	}
+
+	if err := WrapVerifier(sv).VerifySignature(bytes.NewReader(buf.Bytes()), nil); err != nil {
+		t.Errorf("unexpected error verifying envelope: %v", err)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"io"
 	"math/big"
 
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
@@ -46,7 +47,45 @@ var ecdsaSupportedVerifyHashFuncs = []crypto.Hash{
 	crypto.SHA1,
 }
 
+// selectHashForCurve returns the hash function paired with curve by FIPS 186-5: SHA256 for
+// P-256, SHA384 for P-384 and SHA512 for P-521.
+func selectHashForCurve(curve elliptic.Curve) (crypto.Hash, error) {
+	switch curve {
+	case elliptic.P256():
+		return crypto.SHA256, nil
+	case elliptic.P384():
+		return crypto.SHA384, nil
+	case elliptic.P521():
+		return crypto.SHA512, nil
+	}
+	if curve == nil || curve.Params() == nil {
+		return crypto.Hash(0), errors.New("no default hash function for unknown elliptic curve")
+	}
+	return crypto.Hash(0), fmt.Errorf("no default hash function for elliptic curve %s", curve.Params().Name)
+}
+
+// checkHashForCurve returns an error if hashFunc produces digests shorter than those of the hash
+// function paired with curve. Curves without a paired hash function are not checked, nor are
+// hash functions that ECDSA does not support at all, which are rejected when the digest is
+// computed.
+func checkHashForCurve(curve elliptic.Curve, hashFunc crypto.Hash) error {
+	want, err := selectHashForCurve(curve)
+	if err != nil || !isSupportedAlg(hashFunc, ecdsaSupportedVerifyHashFuncs) {
+		return nil
+	}
+	if hashFunc.Size() < want.Size() {
+		return fmt.Errorf("hash function %v is too weak for ECDSA %s keys, which require %v or a longer digest", hashFunc, curve.Params().Name, want)
+	}
+	return nil
+}
+
 // ECDSASigner is a signature.Signer that uses an Elliptic Curve DSA algorithm
+//
+// The hash function may produce a digest longer than the order of the curve, e.g. SHA-512 with
+// P-256. As required by FIPS 186-5 (section 6.4.1) and SEC 1, the digest is then truncated to its
+// leftmost bits, as many as the bit length of the curve order, before signing; ECDSAVerifier
+// truncates in the same way, so such signatures interoperate with other conforming
+// implementations. The full digest is still what is passed in, e.g. with options.WithDigest.
 type ECDSASigner struct {
 	hashFunc crypto.Hash
 	priv     *ecdsa.PrivateKey
@@ -54,16 +93,32 @@ type ECDSASigner struct {
 
 // LoadECDSASigner calculates signatures using the specified private key and hash algorithm.
 //
-// hf must not be crypto.Hash(0).
+// If hf is crypto.Hash(0), the hash function paired with the key's curve is used: SHA256 for
+// P-256, SHA384 for P-384 and SHA512 for P-521. A hash function chosen explicitly is used as
+// given, even if it is weaker than the one paired with the curve.
 func LoadECDSASigner(priv *ecdsa.PrivateKey, hf crypto.Hash) (*ECDSASigner, error) {
 	if priv == nil {
 		return nil, errors.New("invalid ECDSA private key specified")
 	}
 
+	if hf == crypto.Hash(0) {
+		var err error
+		if hf, err = selectHashForCurve(priv.Curve); err != nil {
+			return nil, err
+		}
+	}
+
 	if !isSupportedAlg(hf, ecdsaSupportedHashFuncs) {
 		return nil, errors.New("invalid hash function specified")
 	}
 
+	if err := fipsCheck(&priv.PublicKey, hf); err != nil {
+		return nil, err
+	}
+	if err := deprecationCheck(&priv.PublicKey, hf); err != nil {
+		return nil, err
+	}
+
 	return &ECDSASigner{
 		priv:     priv,
 		hashFunc: hf,
@@ -78,17 +133,46 @@ func LoadECDSASigner(priv *ecdsa.PrivateKey, hf crypto.Hash) (*ECDSASigner, erro
 //
 // - WithRand()
 //
+// - WithDeterministicSignature()
+//
 // - WithDigest()
 //
 // - WithCryptoSignerOpts()
 //
+// With WithDeterministicSignature, the nonce is derived from the key and digest as described in
+// RFC 6979 rather than drawn from the source of entropy, so the same key and message always
+// produce the same signature. This requires the P-224, P-256, P-384 or P-521 curve, and Go 1.24
+// or later.
+//
+// A hash function given with WithCryptoSignerOpts that differs from the one the ECDSASigner was
+// created with must produce digests at least as long as the one paired with the key's curve (see
+// LoadECDSASigner), as a shorter digest would weaken the signature.
+//
 // All other options are ignored if specified.
 func (e ECDSASigner) SignMessage(message io.Reader, opts ...SignOption) ([]byte, error) {
-	digest, _, err := ComputeDigestForSigning(message, e.hashFunc, ecdsaSupportedHashFuncs, opts...)
+	var signerOpts crypto.SignerOpts
+	for _, opt := range opts {
+		opt.ApplyCryptoSignerOpts(&signerOpts)
+	}
+	if signerOpts != nil && signerOpts.HashFunc() != e.hashFunc {
+		if err := checkHashForCurve(e.priv.Curve, signerOpts.HashFunc()); err != nil {
+			return nil, err
+		}
+	}
+
+	digest, hashedWith, err := ComputeDigestForSigning(message, e.hashFunc, ecdsaSupportedHashFuncs, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	deterministic := false
+	for _, opt := range opts {
+		opt.ApplyDeterministicSignature(&deterministic)
+	}
+	if deterministic {
+		return signECDSADeterministic(e.priv, digest, hashedWith)
+	}
+
 	rand := selectRandFromOpts(opts...)
 
 	return ecdsa.SignASN1(rand, e.priv, digest)
@@ -116,6 +200,7 @@ func (e ECDSASigner) PublicKey(_ ...PublicKeyOption) (crypto.PublicKey, error) {
 //
 // If opts are specified, the hash function in opts.Hash should be the one used to compute
 // digest. If opts are not specified, the value provided when the signer was created will be used instead.
+// As for SignMessage, a hash function weaker than the one paired with the key's curve is rejected.
 func (e ECDSASigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
 	ecdsaOpts := []SignOption{options.WithDigest(digest), options.WithRand(rand)}
 	if opts != nil {
@@ -125,7 +210,8 @@ func (e ECDSASigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts)
 	return e.SignMessage(nil, ecdsaOpts...)
 }
 
-// ECDSAVerifier is a signature.Verifier that uses an Elliptic Curve DSA algorithm
+// ECDSAVerifier is a signature.Verifier that uses an Elliptic Curve DSA algorithm. Digests longer
+// than the curve order are truncated to their leftmost bits, as described for ECDSASigner.
 type ECDSAVerifier struct {
 	publicKey *ecdsa.PublicKey
 	hashFunc  crypto.Hash
@@ -134,16 +220,31 @@ type ECDSAVerifier struct {
 // LoadECDSAVerifier returns a Verifier that verifies signatures using the specified
 // ECDSA public key and hash algorithm.
 //
-// hf must not be crypto.Hash(0).
+// If hashFunc is crypto.Hash(0), the hash function paired with the key's curve is used, as
+// described for LoadECDSASigner.
 func LoadECDSAVerifier(pub *ecdsa.PublicKey, hashFunc crypto.Hash) (*ECDSAVerifier, error) {
 	if pub == nil {
 		return nil, errors.New("invalid ECDSA public key specified")
 	}
 
+	if hashFunc == crypto.Hash(0) {
+		var err error
+		if hashFunc, err = selectHashForCurve(pub.Curve); err != nil {
+			return nil, err
+		}
+	}
+
 	if !isSupportedAlg(hashFunc, ecdsaSupportedHashFuncs) {
 		return nil, errors.New("invalid hash function specified")
 	}
 
+	if err := fipsCheck(pub, hashFunc); err != nil {
+		return nil, err
+	}
+	if err := deprecationCheck(pub, hashFunc); err != nil {
+		return nil, err
+	}
+
 	return &ECDSAVerifier{
 		publicKey: pub,
 		hashFunc:  hashFunc,
@@ -167,16 +268,32 @@ func (e ECDSAVerifier) PublicKey(_ ...PublicKeyOption) (crypto.PublicKey, error)
 //
 // - WithDigest()
 //
+// A hash function given with WithCryptoSignerOpts must be at least as strong as the one paired
+// with the key's curve, as for ECDSASigner.SignMessage, except that SHA-1 is accepted if
+// WithAllowSHA1(true) is also given.
+//
 // All other options are ignored if specified.
 func (e ECDSAVerifier) VerifySignature(signature, message io.Reader, opts ...VerifyOption) error {
 	if e.publicKey == nil {
 		return errors.New("no public key set for ECDSAVerifier")
 	}
 
-	digest, _, err := ComputeDigestForVerifying(message, e.hashFunc, ecdsaSupportedVerifyHashFuncs, opts...)
+	digest, hashedWith, err := ComputeDigestForVerifying(message, e.hashFunc, ecdsaSupportedVerifyHashFuncs, opts...)
 	if err != nil {
 		return err
 	}
+	if hashedWith != e.hashFunc {
+		var allowSHA1 *bool
+		for _, opt := range opts {
+			opt.ApplyAllowSHA1(&allowSHA1)
+		}
+		// legacy SHA-1 signatures can still be verified if explicitly allowed
+		if hashedWith != crypto.SHA1 || allowSHA1 == nil || !*allowSHA1 {
+			if err := checkHashForCurve(e.publicKey.Curve, hashedWith); err != nil {
+				return err
+			}
+		}
+	}
 
 	if signature == nil {
 		return errors.New("nil signature passed to VerifySignature")
@@ -200,12 +317,11 @@ func (e ECDSAVerifier) VerifySignature(signature, message io.Reader, opts ...Ver
 			return errors.New("invalid signature when validating ASN.1 encoded signature")
 		}
 	} else {
-		// deal with IEEE P1363 encoding of signatures
-		if len(sigBytes) == 0 || len(sigBytes) > 132 || len(sigBytes)%2 != 0 {
-			return errors.New("ecdsa: Invalid IEEE_P1363 encoded bytes")
+		// deal with IEEE P1363 encoding of signatures, either padded to the curve size or not
+		r, s, err := cryptoutils.UnmarshalECDSASignatureRaw(e.publicKey.Curve, sigBytes)
+		if err != nil {
+			return fmt.Errorf("ecdsa: invalid IEEE_P1363 encoded bytes: %w", err)
 		}
-		r := new(big.Int).SetBytes(sigBytes[:len(sigBytes)/2])
-		s := new(big.Int).SetBytes(sigBytes[len(sigBytes)/2:])
 		if !ecdsa.Verify(e.publicKey, digest, r, s) {
 			return errors.New("invalid signature when validating IEEE_P1363 encoded signature")
 		}
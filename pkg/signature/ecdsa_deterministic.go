@@ -0,0 +1,29 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.24
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+)
+
+// signECDSADeterministic signs digest, computed with hashFunc, with an RFC 6979 nonce
+func signECDSADeterministic(priv *ecdsa.PrivateKey, digest []byte, hashFunc crypto.Hash) ([]byte, error) {
+	// from Go 1.24, signing without a source of entropy follows RFC 6979
+	return priv.Sign(nil, digest, hashFunc)
+}
@@ -0,0 +1,29 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !go1.24
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"errors"
+)
+
+// signECDSADeterministic is not supported before Go 1.24, which added RFC 6979 nonces to crypto/ecdsa
+func signECDSADeterministic(_ *ecdsa.PrivateKey, _ []byte, _ crypto.Hash) ([]byte, error) {
+	return nil, errors.New("deterministic ECDSA signatures require Go 1.24 or later")
+}
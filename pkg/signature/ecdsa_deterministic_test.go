@@ -0,0 +1,145 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.24
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestECDSADeterministicSignature(t *testing.T) {
+	message := []byte("reproducible build output")
+	for _, tt := range []struct {
+		curve elliptic.Curve
+		hash  crypto.Hash
+	}{
+		{elliptic.P256(), crypto.SHA256},
+		{elliptic.P384(), crypto.SHA384},
+		{elliptic.P521(), crypto.SHA512},
+		{elliptic.P256(), crypto.SHA512},
+	} {
+		t.Run(tt.curve.Params().Name+" "+tt.hash.String(), func(t *testing.T) {
+			priv, err := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			if err != nil {
+				t.Fatalf("unexpected error generating key: %v", err)
+			}
+			sv, err := LoadECDSASignerVerifier(priv, tt.hash)
+			if err != nil {
+				t.Fatalf("unexpected error loading signer/verifier: %v", err)
+			}
+
+			first, err := sv.SignMessage(bytes.NewReader(message), options.WithDeterministicSignature())
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			for i := 0; i < 3; i++ {
+				// the source of entropy must not affect a deterministic signature
+				sig, err := sv.SignMessage(bytes.NewReader(message), options.WithRand(rand.Reader), options.WithDeterministicSignature())
+				if err != nil {
+					t.Fatalf("unexpected error signing: %v", err)
+				}
+				if !bytes.Equal(sig, first) {
+					t.Fatal("deterministic signatures over the same message differ")
+				}
+			}
+
+			h := tt.hash.New()
+			h.Write(message)
+			digest := h.Sum(nil)
+			if !ecdsa.VerifyASN1(&priv.PublicKey, digest, first) {
+				t.Error("deterministic signature does not verify with crypto/ecdsa")
+			}
+			if err := sv.VerifySignature(bytes.NewReader(first), bytes.NewReader(message)); err != nil {
+				t.Errorf("unexpected error verifying: %v", err)
+			}
+			sig, err := sv.SignMessage(nil, options.WithDigest(digest), options.WithDeterministicSignature())
+			if err != nil {
+				t.Fatalf("unexpected error signing digest: %v", err)
+			}
+			if !bytes.Equal(sig, first) {
+				t.Error("deterministic signature over the precomputed digest differs")
+			}
+
+			other, err := sv.SignMessage(bytes.NewReader([]byte("another message")), options.WithDeterministicSignature())
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			if bytes.Equal(other, first) {
+				t.Error("deterministic signatures over different messages are equal")
+			}
+		})
+	}
+}
+
+// TestECDSADeterministicSignatureVector checks the P-256, SHA-256 "sample" vector of RFC 6979, section A.2.5
+func TestECDSADeterministicSignatureVector(t *testing.T) {
+	d, err := hex.DecodeString("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdhPriv, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the uncompressed point is 0x04 || X || Y
+	point := ecdhPriv.PublicKey().Bytes()
+	priv := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(point[1:33]),
+			Y:     new(big.Int).SetBytes(point[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}
+
+	sv, err := LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error loading signer/verifier: %v", err)
+	}
+	sig, err := sv.SignMessage(bytes.NewReader([]byte("sample")), options.WithDeterministicSignature())
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	digest := sha256.Sum256([]byte("sample"))
+	raw, err := hex.DecodeString("EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716" +
+		"F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := cryptoutils.ECDSASignatureRawToDER(elliptic.P256(), raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, want) {
+		t.Errorf("signature does not match RFC 6979 vector: got %x, want %x", sig, want)
+	}
+	if !ecdsa.VerifyASN1(&priv.PublicKey, digest[:], sig) {
+		t.Error("signature does not verify with crypto/ecdsa")
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"testing"
 
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
 // Generated with:
@@ -141,3 +142,176 @@ func TestECDSALoadVerifierInvalidCurve(t *testing.T) {
 		t.Fatalf("expected error verifying signature with invalid curve, got %v", err)
 	}
 }
+
+func TestECDSAVerifierRawSignatureShortComponent(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error generating key: %v", err)
+	}
+	v, err := LoadECDSAVerifier(&priv.PublicKey, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+	msg := []byte("sign me")
+	digest := sha256.Sum256(msg)
+
+	// sign until R or S has a leading zero byte, which happens about once every 128 signatures
+	for i := 0; i < 10000; i++ {
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			t.Fatalf("unexpected error signing: %v", err)
+		}
+		if len(r.Bytes()) == 32 && len(s.Bytes()) == 32 {
+			continue
+		}
+		raw, err := cryptoutils.MarshalECDSASignatureRaw(elliptic.P256(), r, s)
+		if err != nil {
+			t.Fatalf("unexpected error encoding signature: %v", err)
+		}
+		if len(raw) != 64 {
+			t.Fatalf("expected fixed-length 64 byte signature, got %d", len(raw))
+		}
+		if err := v.VerifySignature(bytes.NewReader(raw), bytes.NewReader(msg)); err != nil {
+			t.Fatalf("unexpected error verifying padded signature: %v", err)
+		}
+		return
+	}
+	t.Fatal("did not produce a signature with a short component")
+}
+
+func TestECDSADigestTruncation(t *testing.T) {
+	message := []byte("hash larger than the curve")
+	for _, tt := range []struct {
+		curve elliptic.Curve
+		hash  crypto.Hash
+	}{
+		{elliptic.P256(), crypto.SHA512},
+		{elliptic.P256(), crypto.SHA384},
+		{elliptic.P384(), crypto.SHA512},
+		// the digest is shorter than the curve order, so nothing is truncated
+		{elliptic.P521(), crypto.SHA512},
+	} {
+		t.Run(fmt.Sprintf("%s with %s", tt.curve.Params().Name, tt.hash), func(t *testing.T) {
+			priv, err := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			if err != nil {
+				t.Fatalf("unexpected error generating key: %v", err)
+			}
+			sv, err := LoadECDSASignerVerifier(priv, tt.hash)
+			if err != nil {
+				t.Fatalf("unexpected error loading signer/verifier: %v", err)
+			}
+
+			h := tt.hash.New()
+			h.Write(message)
+			digest := h.Sum(nil)
+			orderBytes := (tt.curve.Params().N.BitLen() + 7) / 8
+			truncated := digest
+			if len(truncated) > orderBytes {
+				truncated = truncated[:orderBytes]
+			}
+
+			sig, err := sv.SignMessage(bytes.NewReader(message))
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+				t.Fatalf("unexpected error verifying: %v", err)
+			}
+			// an implementation that truncates the digest itself must accept the signature
+			if !ecdsa.VerifyASN1(&priv.PublicKey, truncated, sig) {
+				t.Error("signature does not verify over the leftmost bits of the digest")
+			}
+			if len(digest) > orderBytes && ecdsa.VerifyASN1(&priv.PublicKey, digest[len(digest)-orderBytes:], sig) {
+				t.Error("signature unexpectedly verifies over the rightmost bits of the digest")
+			}
+
+			// and a signature made by such an implementation must be accepted
+			externalSig, err := ecdsa.SignASN1(rand.Reader, priv, truncated)
+			if err != nil {
+				t.Fatalf("unexpected error signing truncated digest: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(externalSig), bytes.NewReader(message)); err != nil {
+				t.Errorf("unexpected error verifying signature over truncated digest: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(externalSig), nil, options.WithDigest(digest)); err != nil {
+				t.Errorf("unexpected error verifying signature with precomputed digest: %v", err)
+			}
+		})
+	}
+}
+
+func TestECDSADefaultHashForCurve(t *testing.T) {
+	message := []byte("sign me with the default hash")
+	for _, tt := range []struct {
+		curve    elliptic.Curve
+		wantHash crypto.Hash
+		weaker   []crypto.Hash
+		stronger []crypto.Hash
+	}{
+		{elliptic.P256(), crypto.SHA256, []crypto.Hash{crypto.SHA1, crypto.SHA224}, []crypto.Hash{crypto.SHA384, crypto.SHA512}},
+		{elliptic.P384(), crypto.SHA384, []crypto.Hash{crypto.SHA1, crypto.SHA224, crypto.SHA256}, []crypto.Hash{crypto.SHA512}},
+		{elliptic.P521(), crypto.SHA512, []crypto.Hash{crypto.SHA1, crypto.SHA224, crypto.SHA256, crypto.SHA384}, nil},
+	} {
+		t.Run(tt.curve.Params().Name, func(t *testing.T) {
+			priv, err := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			if err != nil {
+				t.Fatalf("unexpected error generating key: %v", err)
+			}
+			sv, err := LoadECDSASignerVerifier(priv, crypto.Hash(0))
+			if err != nil {
+				t.Fatalf("unexpected error loading signer/verifier: %v", err)
+			}
+			if sv.ECDSASigner.hashFunc != tt.wantHash || sv.ECDSAVerifier.hashFunc != tt.wantHash {
+				t.Fatalf("expected default hash %v, got %v and %v", tt.wantHash, sv.ECDSASigner.hashFunc, sv.ECDSAVerifier.hashFunc)
+			}
+			sig, err := sv.SignMessage(bytes.NewReader(message))
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			// a verifier loaded separately with the same default must accept the signature
+			v, err := LoadVerifier(priv.Public(), crypto.Hash(0))
+			if err != nil {
+				t.Fatalf("unexpected error loading verifier: %v", err)
+			}
+			if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+				t.Errorf("unexpected error verifying: %v", err)
+			}
+
+			for _, hf := range tt.weaker {
+				if _, err := sv.SignMessage(bytes.NewReader(message), options.WithCryptoSignerOpts(hf)); err == nil || !strings.Contains(err.Error(), "too weak") {
+					t.Errorf("expected error signing with %v, got %v", hf, err)
+				}
+				digest := hf.New()
+				digest.Write(message)
+				if _, err := sv.Sign(rand.Reader, digest.Sum(nil), hf); err == nil || !strings.Contains(err.Error(), "too weak") {
+					t.Errorf("expected error signing a digest with %v, got %v", hf, err)
+				}
+				if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithCryptoSignerOpts(hf)); err == nil || !strings.Contains(err.Error(), "too weak") {
+					t.Errorf("expected error verifying with %v, got %v", hf, err)
+				}
+			}
+			for _, hf := range tt.stronger {
+				sig, err := sv.SignMessage(bytes.NewReader(message), options.WithCryptoSignerOpts(hf))
+				if err != nil {
+					t.Fatalf("unexpected error signing with %v: %v", hf, err)
+				}
+				if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithCryptoSignerOpts(hf)); err != nil {
+					t.Errorf("unexpected error verifying with %v: %v", hf, err)
+				}
+			}
+		})
+	}
+
+	// a hash function chosen explicitly when loading is still honored
+	priv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error generating key: %v", err)
+	}
+	sv, err := LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error loading signer/verifier: %v", err)
+	}
+	if _, err := sv.SignMessage(bytes.NewReader(message), options.WithCryptoSignerOpts(crypto.SHA256)); err != nil {
+		t.Errorf("unexpected error signing with the configured hash: %v", err)
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
 var ed25519SupportedHashFuncs = []crypto.Hash{
@@ -45,6 +47,10 @@ func LoadED25519Signer(priv ed25519.PrivateKey) (*ED25519Signer, error) {
 		return nil, errors.New("invalid size for ED25519 key")
 	}
 
+	if err := fipsCheck(priv.Public(), crypto.Hash(0)); err != nil {
+		return nil, err
+	}
+
 	return &ED25519Signer{
 		priv: priv,
 	}, nil
@@ -54,8 +60,20 @@ func LoadED25519Signer(priv ed25519.PrivateKey) (*ED25519Signer, error) {
 // supported as ED25519 performs a two pass hash over the message during the
 // signing process.
 //
-// All options are ignored.
-func (e ED25519Signer) SignMessage(message io.Reader, _ ...SignOption) ([]byte, error) {
+// All options other than WithDomainSeparation, WithDecompressor and WithProgress are ignored.
+func (e ED25519Signer) SignMessage(message io.Reader, opts ...SignOption) ([]byte, error) {
+	var label []byte
+	var decompressor options.Decompressor
+	var progress options.ProgressFunc
+	for _, opt := range opts {
+		opt.ApplyDomainSeparation(&label)
+		opt.ApplyDecompressor(&decompressor)
+		opt.ApplyProgress(&progress)
+	}
+	message, err := transformMessage(message, label, decompressor, progress, 0)
+	if err != nil {
+		return nil, err
+	}
 	messageBytes, _, err := ComputeDigestForSigning(message, crypto.Hash(0), ed25519SupportedHashFuncs)
 	if err != nil {
 		return nil, err
@@ -93,6 +111,7 @@ func (e ED25519Signer) Sign(_ io.Reader, message []byte, _ crypto.SignerOpts) ([
 // ED25519Verifier is a signature.Verifier that uses the Ed25519 public-key signature system
 type ED25519Verifier struct {
 	publicKey ed25519.PublicKey
+	strict    bool
 }
 
 // LoadED25519Verifier returns a Verifier that verifies signatures using the specified ED25519 public key.
@@ -101,11 +120,29 @@ func LoadED25519Verifier(pub ed25519.PublicKey) (*ED25519Verifier, error) {
 		return nil, errors.New("invalid ED25519 public key specified")
 	}
 
+	if err := fipsCheck(pub, crypto.Hash(0)); err != nil {
+		return nil, err
+	}
+
 	return &ED25519Verifier{
 		publicKey: pub,
 	}, nil
 }
 
+// LoadStrictED25519Verifier returns a Verifier like LoadED25519Verifier that additionally rejects
+// signatures that are not in the single canonical encoding, for applications where signature
+// malleability matters: S must be fully reduced, and R and the public key must be canonically
+// encoded points that are not of small order. Such signatures fail with a
+// *NonCanonicalSignatureError before the signature equation is checked.
+func LoadStrictED25519Verifier(pub ed25519.PublicKey) (*ED25519Verifier, error) {
+	v, err := LoadED25519Verifier(pub)
+	if err != nil {
+		return nil, err
+	}
+	v.strict = true
+	return v, nil
+}
+
 // PublicKey returns the public key that is used to verify signatures by
 // this verifier. As this value is held in memory, all options provided in arguments
 // to this method are ignored.
@@ -117,8 +154,22 @@ func (e *ED25519Verifier) PublicKey(_ ...PublicKeyOption) (crypto.PublicKey, err
 //
 // This function returns nil if the verification succeeded, and an error message otherwise.
 //
-// All options are ignored if specified.
-func (e *ED25519Verifier) VerifySignature(signature, message io.Reader, _ ...VerifyOption) error {
+// All options other than WithDomainSeparation, WithDecompressor, WithProgress and WithMaxMessageSize are ignored if specified.
+func (e *ED25519Verifier) VerifySignature(signature, message io.Reader, opts ...VerifyOption) error {
+	var label []byte
+	var decompressor options.Decompressor
+	var progress options.ProgressFunc
+	var maxSize int64
+	for _, opt := range opts {
+		opt.ApplyDomainSeparation(&label)
+		opt.ApplyDecompressor(&decompressor)
+		opt.ApplyProgress(&progress)
+		opt.ApplyMaxMessageSize(&maxSize)
+	}
+	message, err := transformMessage(message, label, decompressor, progress, maxSize)
+	if err != nil {
+		return err
+	}
 	messageBytes, _, err := ComputeDigestForVerifying(message, crypto.Hash(0), ed25519SupportedHashFuncs)
 	if err != nil {
 		return err
@@ -133,6 +184,11 @@ func (e *ED25519Verifier) VerifySignature(signature, message io.Reader, _ ...Ver
 		return fmt.Errorf("reading signature: %w", err)
 	}
 
+	if e.strict {
+		if err := checkED25519Strict(e.publicKey, sigBytes); err != nil {
+			return err
+		}
+	}
 	if !ed25519.Verify(e.publicKey, messageBytes, sigBytes) {
 		return errors.New("failed to verify signature")
 	}
@@ -41,6 +41,10 @@ func LoadED25519phSigner(priv ed25519.PrivateKey) (*ED25519phSigner, error) {
 		return nil, errors.New("invalid ED25519 private key specified")
 	}
 
+	if err := fipsCheck(priv.Public(), crypto.SHA512); err != nil {
+		return nil, err
+	}
+
 	return &ED25519phSigner{
 		priv: priv,
 	}, nil
@@ -100,6 +104,7 @@ func (e ED25519phSigner) Sign(_ io.Reader, digest []byte, _ crypto.SignerOpts) (
 // ED25519phVerifier is a signature.Verifier that uses the Ed25519 public-key signature system
 type ED25519phVerifier struct {
 	publicKey ed25519.PublicKey
+	strict    bool
 }
 
 // LoadED25519phVerifier returns a Verifier that verifies signatures using the
@@ -109,6 +114,10 @@ func LoadED25519phVerifier(pub ed25519.PublicKey) (*ED25519phVerifier, error) {
 		return nil, errors.New("invalid ED25519 public key specified")
 	}
 
+	if err := fipsCheck(pub, crypto.SHA512); err != nil {
+		return nil, err
+	}
+
 	return &ED25519phVerifier{
 		publicKey: pub,
 	}, nil
@@ -147,6 +156,11 @@ func (e *ED25519phVerifier) VerifySignature(signature, message io.Reader, opts .
 		return fmt.Errorf("reading signature: %w", err)
 	}
 
+	if e.strict {
+		if err := checkED25519Strict(e.publicKey, sigBytes); err != nil {
+			return err
+		}
+	}
 	if err := ed25519.VerifyWithOptions(e.publicKey, digest, sigBytes, &ed25519.Options{Hash: crypto.SHA512}); err != nil {
 		return fmt.Errorf("failed to verify signature: %w", err)
 	}
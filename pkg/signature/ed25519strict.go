@@ -0,0 +1,115 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// NonCanonicalSignatureError is returned by a strict Ed25519 verifier when a signature or
+// public key is rejected by the strict encoding rules, independently of whether the signature
+// would otherwise verify
+type NonCanonicalSignatureError struct {
+	Reason string
+}
+
+func (e *NonCanonicalSignatureError) Error() string {
+	return fmt.Sprintf("non-canonical ed25519 signature: %s", e.Reason)
+}
+
+var (
+	// ed25519FieldPrime is p = 2^255 - 19
+	ed25519FieldPrime = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// ed25519GroupOrder is L = 2^252 + 27742317777372353535851937790883648493
+	ed25519GroupOrder, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+	// ed25519SmallOrderPoints are the canonical encodings of the eight points of small order
+	// (the torsion subgroup); non-canonical encodings of them are rejected separately
+	ed25519SmallOrderPoints = func() [][]byte {
+		var points [][]byte
+		for _, h := range []string{
+			"0100000000000000000000000000000000000000000000000000000000000000", // identity
+			"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // order 2
+			"0000000000000000000000000000000000000000000000000000000000000000", // order 4
+			"0000000000000000000000000000000000000000000000000000000000000080", // order 4
+			"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05", // order 8
+			"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc85", // order 8
+			"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a", // order 8
+			"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac03fa", // order 8
+		} {
+			p, _ := hex.DecodeString(h)
+			points = append(points, p)
+		}
+		return points
+	}()
+)
+
+// checkED25519Strict applies the strict Ed25519 encoding rules to a public key and signature:
+// the public key A and the signature's R must be canonical encodings of points that are not of
+// small order, and S must be fully reduced modulo the group order. Together with the cofactorless
+// verification equation used by crypto/ed25519 this leaves no malleable or ambiguous encodings,
+// so that a valid signature has exactly one accepted byte representation.
+func checkED25519Strict(pub ed25519.PublicKey, sig []byte) error {
+	if len(pub) != ed25519.PublicKeySize {
+		return &NonCanonicalSignatureError{Reason: fmt.Sprintf("invalid public key length %d", len(pub))}
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return &NonCanonicalSignatureError{Reason: fmt.Sprintf("invalid signature length %d", len(sig))}
+	}
+	if err := checkED25519Point(pub); err != nil {
+		return &NonCanonicalSignatureError{Reason: "public key " + err.Error()}
+	}
+	if err := checkED25519Point(sig[:32]); err != nil {
+		return &NonCanonicalSignatureError{Reason: "R " + err.Error()}
+	}
+	if littleEndianInt(sig[32:]).Cmp(ed25519GroupOrder) >= 0 {
+		return &NonCanonicalSignatureError{Reason: "S is not reduced modulo the group order"}
+	}
+	return nil
+}
+
+// checkED25519Point rejects non-canonical encodings and encodings of small-order points
+func checkED25519Point(enc []byte) error {
+	y := littleEndianInt(enc)
+	sign := y.Bit(255)
+	y.SetBit(y, 255, 0)
+	if y.Cmp(ed25519FieldPrime) >= 0 {
+		return errors.New("y coordinate is not reduced modulo p")
+	}
+	// x is zero when y is 1 or p-1, and zero has no negative encoding
+	if sign == 1 && (y.Cmp(big.NewInt(1)) == 0 || y.Cmp(new(big.Int).Sub(ed25519FieldPrime, big.NewInt(1))) == 0) {
+		return errors.New("has the sign bit set for x = 0")
+	}
+	for _, p := range ed25519SmallOrderPoints {
+		if bytes.Equal(enc, p) {
+			return errors.New("is a point of small order")
+		}
+	}
+	return nil
+}
+
+func littleEndianInt(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
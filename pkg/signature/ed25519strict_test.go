@@ -0,0 +1,157 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestStrictED25519Verifier(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("sign me")
+	sig := ed25519.Sign(priv, message)
+
+	strict, err := LoadStrictED25519Verifier(pub)
+	if err != nil {
+		t.Fatalf("unexpected error loading strict verifier: %v", err)
+	}
+	if err := strict.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying canonical signature: %v", err)
+	}
+	viaOpts, err := LoadVerifierWithOpts(pub, options.WithStrictED25519())
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier with options: %v", err)
+	}
+	if err := viaOpts.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying canonical signature: %v", err)
+	}
+
+	// S + L verifies the same equation but is a second encoding of the signature
+	s := littleEndianInt(sig[32:])
+	s.Add(s, ed25519GroupOrder)
+	malleated := append(bytes.Clone(sig[:32]), leBytes(s)...)
+	var nonCanonical *NonCanonicalSignatureError
+	if err := viaOpts.VerifySignature(bytes.NewReader(malleated), bytes.NewReader(message)); !errors.As(err, &nonCanonical) {
+		t.Errorf("expected *NonCanonicalSignatureError for unreduced S, got %v", err)
+	}
+}
+
+func TestStrictED25519Vectors(t *testing.T) {
+	const (
+		identity          = "0100000000000000000000000000000000000000000000000000000000000000"
+		identityUnreduced = "eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f" // y = p + 1
+		identityNegativeX = "0100000000000000000000000000000000000000000000000000000000000080" // x = -0
+		orderEight        = "26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05"
+		zeroScalar        = "0000000000000000000000000000000000000000000000000000000000000000"
+		unreducedZero     = "edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f" // y = p
+	)
+	message := []byte("any message")
+	for _, tt := range []struct {
+		name     string
+		pub, sig string
+		// whether crypto/ed25519 accepts the signature without the strict checks
+		stdlibAccepts bool
+	}{
+		// with A and R the identity and S = 0, the verification equation holds for every message
+		{"small-order public key and R", identity, identity + zeroScalar, true},
+		{"non-canonical public key", identityUnreduced, identity + zeroScalar, true},
+		{"public key with negative zero x", identityNegativeX, identity + zeroScalar, false},
+		{"order-8 public key", orderEight, identity + zeroScalar, false},
+		{"non-canonical R", identity, identityUnreduced + zeroScalar, false},
+		{"R equal to p", identity, unreducedZero + zeroScalar, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := ed25519.PublicKey(mustDecodeHex(t, tt.pub))
+			sig := mustDecodeHex(t, tt.sig)
+			if tt.stdlibAccepts {
+				v, err := LoadED25519Verifier(pub)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+					t.Fatalf("expected default verifier to accept vector, got %v", err)
+				}
+			}
+			for name, load := range map[string][]LoadOption{
+				"ed25519":   {options.WithStrictED25519()},
+				"ed25519ph": {options.WithStrictED25519(), options.WithED25519ph()},
+			} {
+				v, err := LoadVerifierWithOpts(pub, load...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var nonCanonical *NonCanonicalSignatureError
+				if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); !errors.As(err, &nonCanonical) {
+					t.Errorf("%s: expected *NonCanonicalSignatureError, got %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStrictED25519phVerifier(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := LoadED25519phSignerVerifier(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("sign me")
+	sig, err := sv.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := LoadVerifierWithOpts(pub, options.WithED25519ph(), options.WithStrictED25519(), options.WithHash(crypto.SHA512))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying canonical ed25519ph signature: %v", err)
+	}
+}
+
+// leBytes returns the 32-byte little-endian encoding of n
+func leBytes(n *big.Int) []byte {
+	be := n.FillBytes(make([]byte, 32))
+	le := make([]byte, 32)
+	for i := range be {
+		le[31-i] = be[i]
+	}
+	return le
+}
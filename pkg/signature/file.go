@@ -0,0 +1,199 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+)
+
+// FileError is returned by SignFile and VerifyFile when an input file cannot be opened, read or
+// parsed, as distinct from a failure to sign or verify
+type FileError struct {
+	// Role describes the file: "message", "signature", "key" or "certificate"
+	Role string
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s file %s: %v", e.Role, e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// VerificationError is returned by VerifyFile when the signature does not verify
+type VerificationError struct {
+	Err error
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("signature verification failed: %v", e.Err)
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// SignFile signs the contents of the file at msgPath with the PEM-encoded private key at keyPath,
+// decrypting it with pf if needed. The message is streamed from disk rather than read into memory.
+//
+// SHA256 is used to compute the digest unless a hash function is given with
+// options.WithCryptoSignerOpts; if the value passed there is an *rsa.PSSOptions, an RSA key
+// signs using RSASSA-PSS. Errors reading either file are returned as a *FileError.
+func SignFile(msgPath, keyPath string, pf cryptoutils.PassFunc, opts ...SignOption) ([]byte, error) {
+	var signerOpts crypto.SignerOpts = crypto.SHA256
+	for _, o := range opts {
+		o.ApplyCryptoSignerOpts(&signerOpts)
+	}
+
+	keyBytes, err := os.ReadFile(filepath.Clean(keyPath))
+	if err != nil {
+		return nil, &FileError{Role: "key", Path: keyPath, Err: err}
+	}
+	priv, err := cryptoutils.UnmarshalPEMToPrivateKey(keyBytes, pf)
+	if err != nil {
+		return nil, &FileError{Role: "key", Path: keyPath, Err: err}
+	}
+	s, err := LoadSignerWithOpts(priv, loadOptsForSignerOpts(signerOpts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := openFile("message", msgPath)
+	if err != nil {
+		return nil, err
+	}
+	defer msg.Close()
+
+	sig, err := s.SignMessage(msg, opts...)
+	if msg.err != nil {
+		return nil, &FileError{Role: "message", Path: msgPath, Err: msg.err}
+	}
+	return sig, err
+}
+
+// VerifyFile verifies the detached signature at sigPath over the contents of the file at msgPath,
+// using the PEM-encoded public key or X.509 certificate at keyOrCertPath. The message is streamed
+// from disk rather than read into memory. The signature file may contain either the raw signature
+// or its base64 encoding.
+//
+// SHA256 is used to compute the digest unless a hash function is given with
+// options.WithCryptoSignerOpts; if the value passed there is an *rsa.PSSOptions, an RSA key is
+// verified using RSASSA-PSS. If a certificate is given, no validation of the certificate itself
+// is performed beyond any policy given with options.WithCertificatePolicy.
+//
+// Errors reading or parsing any of the files are returned as a *FileError, and a signature that
+// does not verify is reported as a *VerificationError.
+func VerifyFile(sigPath, msgPath, keyOrCertPath string, opts ...VerifyOption) error {
+	var signerOpts crypto.SignerOpts = crypto.SHA256
+	for _, o := range opts {
+		o.ApplyCryptoSignerOpts(&signerOpts)
+	}
+
+	v, err := loadVerifierFromFile(keyOrCertPath, loadOptsForSignerOpts(signerOpts))
+	if err != nil {
+		return err
+	}
+
+	sigBytes, err := os.ReadFile(filepath.Clean(sigPath))
+	if err != nil {
+		return &FileError{Role: "signature", Path: sigPath, Err: err}
+	}
+	sig := decodeSignature(sigBytes)
+
+	msg, err := openFile("message", msgPath)
+	if err != nil {
+		return err
+	}
+	defer msg.Close()
+
+	err = v.VerifySignature(bytes.NewReader(sig), msg, opts...)
+	if msg.err != nil {
+		return &FileError{Role: "message", Path: msgPath, Err: msg.err}
+	}
+	if err != nil {
+		return &VerificationError{Err: err}
+	}
+	return nil
+}
+
+func loadVerifierFromFile(path string, loadOpts []LoadOption) (Verifier, error) {
+	fileBytes, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, &FileError{Role: "key", Path: path, Err: err}
+	}
+	if block, _ := pem.Decode(fileBytes); block != nil && block.Type == string(cryptoutils.CertificatePEMType) {
+		certs, err := cryptoutils.UnmarshalCertificatesFromPEM(fileBytes)
+		if err != nil {
+			return nil, &FileError{Role: "certificate", Path: path, Err: err}
+		}
+		return LoadCertificateVerifier(certs[0], loadOpts...)
+	}
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey(fileBytes)
+	if err != nil {
+		return nil, &FileError{Role: "key", Path: path, Err: err}
+	}
+	return LoadVerifierWithOpts(pub, loadOpts...)
+}
+
+// decodeSignature returns the base64-decoded contents of a signature file, or the contents
+// unchanged if they are not valid base64
+func decodeSignature(b []byte) []byte {
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(b))); err == nil && len(decoded) > 0 {
+		return decoded
+	}
+	return b
+}
+
+// trackedFile is an open file that records the first read error other than io.EOF, so that I/O
+// failures can be told apart from signing and verification failures. The file is not embedded
+// so that io.Copy cannot bypass Read through (*os.File).WriteTo.
+type trackedFile struct {
+	f   *os.File
+	err error
+}
+
+func openFile(role, path string) (*trackedFile, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, &FileError{Role: role, Path: path, Err: err}
+	}
+	return &trackedFile{f: f}, nil
+}
+
+func (t *trackedFile) Read(p []byte) (int, error) {
+	n, err := t.f.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && t.err == nil {
+		t.err = err
+	}
+	return n, err
+}
+
+func (t *trackedFile) Close() error {
+	return t.f.Close()
+}
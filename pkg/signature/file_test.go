@@ -0,0 +1,120 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+)
+
+func writeTestFile(t *testing.T, dir, name string, contents []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, contents, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSignAndVerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPEM, err := cryptoutils.MarshalPrivateKeyToPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM, err := cryptoutils.MarshalPublicKeyToPEM(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, priv.Public(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(certDER)
+	certPEM, err := cryptoutils.MarshalCertificateToPEM(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyPath := writeTestFile(t, dir, "key.pem", privPEM)
+	pubPath := writeTestFile(t, dir, "key.pub", pubPEM)
+	certPath := writeTestFile(t, dir, "cert.pem", certPEM)
+	msgPath := writeTestFile(t, dir, "artifact", []byte("release artifact contents"))
+	otherPath := writeTestFile(t, dir, "other", []byte("something else"))
+
+	sig, err := SignFile(msgPath, keyPath, cryptoutils.SkipPassword)
+	if err != nil {
+		t.Fatalf("unexpected error signing file: %v", err)
+	}
+	rawSigPath := writeTestFile(t, dir, "artifact.sig", sig)
+	b64SigPath := writeTestFile(t, dir, "artifact.sig.b64", []byte(base64.StdEncoding.EncodeToString(sig)+"\n"))
+
+	for _, sigPath := range []string{rawSigPath, b64SigPath} {
+		for _, verifierPath := range []string{pubPath, certPath} {
+			if err := VerifyFile(sigPath, msgPath, verifierPath); err != nil {
+				t.Errorf("unexpected error verifying %s with %s: %v", sigPath, verifierPath, err)
+			}
+		}
+	}
+
+	var verificationErr *VerificationError
+	if err := VerifyFile(rawSigPath, otherPath, pubPath); !errors.As(err, &verificationErr) {
+		t.Errorf("expected *VerificationError for other message, got %v", err)
+	}
+
+	var fileErr *FileError
+	missing := filepath.Join(dir, "missing")
+	for _, tc := range []struct {
+		role                      string
+		sigPath, msgPath, keyPath string
+	}{
+		{"signature", missing, msgPath, pubPath},
+		{"message", rawSigPath, missing, pubPath},
+		{"key", rawSigPath, msgPath, missing},
+		{"key", rawSigPath, msgPath, msgPath},
+	} {
+		err := VerifyFile(tc.sigPath, tc.msgPath, tc.keyPath)
+		if !errors.As(err, &fileErr) || fileErr.Role != tc.role {
+			t.Errorf("expected *FileError for %s, got %v", tc.role, err)
+		}
+	}
+	if _, err := SignFile(missing, keyPath, cryptoutils.SkipPassword); !errors.As(err, &fileErr) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected *FileError wrapping fs.ErrNotExist signing missing file, got %v", err)
+	}
+}
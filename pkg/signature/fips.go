@@ -0,0 +1,139 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrNotFIPSApproved is returned when FIPS mode is enabled and a key, curve or hash function
+// that is not in the approved set is requested
+var ErrNotFIPSApproved = errors.New("algorithm is not FIPS approved")
+
+// MinFIPSRSAKeySize is the smallest RSA modulus size, in bits, accepted in FIPS mode
+const MinFIPSRSAKeySize = 2048
+
+// fipsApprovedHashFuncs are the hash functions accepted in FIPS mode (FIPS 180-4 SHA-2)
+var fipsApprovedHashFuncs = []crypto.Hash{
+	crypto.SHA224,
+	crypto.SHA256,
+	crypto.SHA384,
+	crypto.SHA512,
+}
+
+// fipsApprovedCurves are the elliptic curves accepted for ECDSA in FIPS mode (FIPS 186-4)
+var fipsApprovedCurves = []elliptic.Curve{
+	elliptic.P224(),
+	elliptic.P256(),
+	elliptic.P384(),
+	elliptic.P521(),
+}
+
+var fipsMode atomic.Bool
+
+// FIPSMode reports whether only FIPS-approved algorithms are accepted. It is enabled by default
+// when built with the "fips" build tag.
+func FIPSMode() bool {
+	return fipsMode.Load()
+}
+
+// SetFIPSMode enables or disables FIPS mode. When enabled, loading a Signer or Verifier for a
+// key that is not FIPS approved, or computing a digest with a hash function that is not FIPS
+// approved, fails with an error wrapping ErrNotFIPSApproved.
+//
+// The approved set is ECDSA over P-224, P-256, P-384 or P-521 and RSA (PKCS #1 v1.5 or PSS) with
+// a modulus of at least MinFIPSRSAKeySize bits, each with SHA-224, SHA-256, SHA-384 or SHA-512.
+// Ed25519 and Ed25519ph are not accepted, nor is SHA-1, including for verification.
+func SetFIPSMode(enabled bool) {
+	fipsMode.Store(enabled)
+}
+
+// IsFIPSApprovedHash reports whether hashFunc is in the FIPS-approved set, regardless of whether
+// FIPS mode is enabled
+func IsFIPSApprovedHash(hashFunc crypto.Hash) bool {
+	return isSupportedAlg(hashFunc, fipsApprovedHashFuncs)
+}
+
+// CheckFIPSApproved returns an error wrapping ErrNotFIPSApproved if the key type, size or curve of
+// publicKey, or hashFunc, is not in the FIPS-approved set, regardless of whether FIPS mode is enabled
+func CheckFIPSApproved(publicKey crypto.PublicKey, hashFunc crypto.Hash) error {
+	switch pk := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if pk == nil || pk.Curve == nil {
+			return errors.New("invalid ECDSA public key specified")
+		}
+		approved := false
+		for _, c := range fipsApprovedCurves {
+			if pk.Curve == c {
+				approved = true
+				break
+			}
+		}
+		if !approved {
+			return fmt.Errorf("%w: ECDSA curve %s", ErrNotFIPSApproved, pk.Params().Name)
+		}
+	case *rsa.PublicKey:
+		if pk == nil || pk.N == nil {
+			return errors.New("invalid RSA public key specified")
+		}
+		if pk.N.BitLen() < MinFIPSRSAKeySize {
+			return fmt.Errorf("%w: %d-bit RSA key", ErrNotFIPSApproved, pk.N.BitLen())
+		}
+	case ed25519.PublicKey:
+		return fmt.Errorf("%w: ED25519", ErrNotFIPSApproved)
+	default:
+		return fmt.Errorf("%w: key type %T", ErrNotFIPSApproved, publicKey)
+	}
+	return checkFIPSHash(hashFunc)
+}
+
+func checkFIPSHash(hashFunc crypto.Hash) error {
+	if !IsFIPSApprovedHash(hashFunc) {
+		return fmt.Errorf("%w: hash function %v", ErrNotFIPSApproved, hashFunc)
+	}
+	return nil
+}
+
+// FIPSApprovedAlgorithms returns the algorithms from the AlgorithmRegistry constants that are
+// accepted in FIPS mode
+func FIPSApprovedAlgorithms() []Algorithm {
+	return []Algorithm{
+		AlgorithmECDSAP256SHA256,
+		AlgorithmECDSAP384SHA384,
+		AlgorithmECDSAP521SHA512,
+		AlgorithmRSAPKCS1v15SHA256,
+		AlgorithmRSAPKCS1v15SHA384,
+		AlgorithmRSAPKCS1v15SHA512,
+		AlgorithmRSAPSSSHA256,
+		AlgorithmRSAPSSSHA384,
+		AlgorithmRSAPSSSHA512,
+	}
+}
+
+// fipsCheck applies CheckFIPSApproved if FIPS mode is enabled
+func fipsCheck(publicKey crypto.PublicKey, hashFunc crypto.Hash) error {
+	if !FIPSMode() {
+		return nil
+	}
+	return CheckFIPSApproved(publicKey, hashFunc)
+}
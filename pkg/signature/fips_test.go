@@ -0,0 +1,123 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestCheckFIPSApproved(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsa2048, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsa1024, _ := rsa.GenerateKey(rand.Reader, 1024)
+	edPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	for _, tt := range []struct {
+		name     string
+		pub      crypto.PublicKey
+		hash     crypto.Hash
+		approved bool
+	}{
+		{"ecdsa p256 sha256", &p256.PublicKey, crypto.SHA256, true},
+		{"ecdsa p256 sha1", &p256.PublicKey, crypto.SHA1, false},
+		{"rsa 2048 sha384", &rsa2048.PublicKey, crypto.SHA384, true},
+		{"rsa 1024 sha256", &rsa1024.PublicKey, crypto.SHA256, false},
+		{"ed25519", edPub, crypto.SHA512, false},
+		{"unknown key type", "not a key", crypto.SHA256, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckFIPSApproved(tt.pub, tt.hash)
+			if tt.approved && err != nil {
+				t.Errorf("expected approved, got %v", err)
+			}
+			if !tt.approved && !errors.Is(err, ErrNotFIPSApproved) {
+				t.Errorf("expected ErrNotFIPSApproved, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckFIPSApprovedInvalidKey(t *testing.T) {
+	defer SetFIPSMode(FIPSMode())
+	SetFIPSMode(true)
+
+	for name, pub := range map[string]crypto.PublicKey{
+		"nil ecdsa":        (*ecdsa.PublicKey)(nil),
+		"zero-value ecdsa": &ecdsa.PublicKey{},
+		"nil rsa":          (*rsa.PublicKey)(nil),
+		"zero-value rsa":   &rsa.PublicKey{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := CheckFIPSApproved(pub, crypto.SHA256); err == nil {
+				t.Error("expected error for invalid key")
+			}
+			if _, err := LoadUnsafeVerifier(pub); err == nil {
+				t.Error("expected error loading verifier for invalid key")
+			}
+			if _, err := LoadVerifier(pub, crypto.SHA256); err == nil {
+				t.Error("expected error loading verifier for invalid key")
+			}
+		})
+	}
+}
+
+func TestFIPSMode(t *testing.T) {
+	defer SetFIPSMode(FIPSMode())
+	SetFIPSMode(true)
+
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	sv, err := LoadECDSASignerVerifier(p256, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error loading approved signer: %v", err)
+	}
+	sig, err := sv.SignMessage(bytes.NewReader([]byte("msg")))
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("msg"))); err != nil {
+		t.Errorf("unexpected error verifying: %v", err)
+	}
+	err = sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("msg")), options.WithCryptoSignerOpts(crypto.SHA1))
+	if !errors.Is(err, ErrNotFIPSApproved) {
+		t.Errorf("expected ErrNotFIPSApproved verifying with SHA-1, got %v", err)
+	}
+
+	_, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := LoadSigner(edPriv, crypto.SHA256); !errors.Is(err, ErrNotFIPSApproved) {
+		t.Errorf("expected ErrNotFIPSApproved loading ED25519 signer, got %v", err)
+	}
+	if _, err := LoadVerifier(edPriv.Public(), crypto.SHA256); !errors.Is(err, ErrNotFIPSApproved) {
+		t.Errorf("expected ErrNotFIPSApproved loading ED25519 verifier, got %v", err)
+	}
+	if _, err := LoadUnsafeVerifier(&p256.PublicKey); !errors.Is(err, ErrNotFIPSApproved) {
+		t.Errorf("expected ErrNotFIPSApproved loading SHA-1 verifier, got %v", err)
+	}
+
+	SetFIPSMode(false)
+	if _, err := LoadSigner(edPriv, crypto.SHA256); err != nil {
+		t.Errorf("unexpected error loading ED25519 signer outside FIPS mode: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// HashedRekord is a Rekor "hashedrekord" (v0.0.1) entry body. It marshals to the JSON expected
+// by Rekor's entry creation API; submitting it is left to the caller.
+type HashedRekord struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Spec       HashedRekordSpec `json:"spec"`
+}
+
+// HashedRekordSpec is the spec of a HashedRekord entry
+type HashedRekordSpec struct {
+	Data      HashedRekordData      `json:"data"`
+	Signature HashedRekordSignature `json:"signature"`
+}
+
+// HashedRekordData identifies the signed artifact by its digest
+type HashedRekordData struct {
+	Hash HashedRekordHash `json:"hash"`
+}
+
+// HashedRekordHash is a hex-encoded digest and the name of the algorithm that produced it
+type HashedRekordHash struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// HashedRekordSignature holds the signature and the PEM-encoded public key or certificate that verifies it.
+// Both are base64-encoded when marshaled.
+type HashedRekordSignature struct {
+	Content   []byte                `json:"content"`
+	PublicKey HashedRekordPublicKey `json:"publicKey"`
+}
+
+// HashedRekordPublicKey holds a PEM-encoded public key or certificate
+type HashedRekordPublicKey struct {
+	Content []byte `json:"content"`
+}
+
+// hashedRekordAlgorithms maps the hash functions supported by hashedrekord to their names in the entry
+var hashedRekordAlgorithms = map[crypto.Hash]string{
+	crypto.SHA256: "sha256",
+	crypto.SHA384: "sha384",
+	crypto.SHA512: "sha512",
+}
+
+// NewHashedRekord assembles a hashedrekord entry body for sig, a signature over an artifact
+// whose hashFunc digest is digest. keyOrCertPEM is the PEM-encoded public key or certificate
+// that verifies sig.
+func NewHashedRekord(sig, digest []byte, hashFunc crypto.Hash, keyOrCertPEM []byte) (*HashedRekord, error) {
+	algorithm, ok := hashedRekordAlgorithms[hashFunc]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm for hashedrekord: %q", hashFunc.String())
+	}
+	if len(digest) != hashFunc.Size() {
+		return nil, fmt.Errorf("digest length %d does not match %s", len(digest), hashFunc.String())
+	}
+	if len(sig) == 0 {
+		return nil, errors.New("signature cannot be empty")
+	}
+	if block, _ := pem.Decode(keyOrCertPEM); block == nil {
+		return nil, errors.New("public key or certificate must be PEM-encoded")
+	}
+	return &HashedRekord{
+		APIVersion: "0.0.1",
+		Kind:       "hashedrekord",
+		Spec: HashedRekordSpec{
+			Data: HashedRekordData{
+				Hash: HashedRekordHash{
+					Algorithm: algorithm,
+					Value:     hex.EncodeToString(digest),
+				},
+			},
+			Signature: HashedRekordSignature{
+				Content: sig,
+				PublicKey: HashedRekordPublicKey{
+					Content: keyOrCertPEM,
+				},
+			},
+		},
+	}, nil
+}
+
+// SignHashedRekord hashes message with hashFunc, signs the digest with sv and returns the
+// resulting hashedrekord entry body, using sv's public key as the verification material.
+func SignHashedRekord(sv SignerVerifier, hashFunc crypto.Hash, message io.Reader, opts ...SignOption) (*HashedRekord, error) {
+	if _, ok := hashedRekordAlgorithms[hashFunc]; !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm for hashedrekord: %q", hashFunc.String())
+	}
+	digest, err := hashMessage(message, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+	signOpts := append(opts[:len(opts):len(opts)], options.WithDigest(digest), options.WithCryptoSignerOpts(hashFunc))
+	sig, err := sv.SignMessage(bytes.NewReader(digest), signOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("signing digest: %w", err)
+	}
+
+	pubOpts := make([]PublicKeyOption, 0, len(opts))
+	for _, o := range opts {
+		pubOpts = append(pubOpts, o)
+	}
+	pub, err := sv.PublicKey(pubOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("getting public key: %w", err)
+	}
+	pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling public key: %w", err)
+	}
+	return NewHashedRekord(sig, digest, hashFunc, pemBytes)
+}
@@ -0,0 +1,91 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+)
+
+func TestSignHashedRekord(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	message := []byte("sign me")
+	entry, err := SignHashedRekord(sv, crypto.SHA256, bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error creating entry: %v", err)
+	}
+
+	digest := sha256.Sum256(message)
+	if entry.Spec.Data.Hash.Algorithm != "sha256" || entry.Spec.Data.Hash.Value != hex.EncodeToString(digest[:]) {
+		t.Errorf("unexpected hash in entry: %+v", entry.Spec.Data.Hash)
+	}
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey(entry.Spec.Signature.PublicKey.Content)
+	if err != nil {
+		t.Fatalf("unexpected error parsing public key: %v", err)
+	}
+	v, err := LoadVerifier(pub, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+	if err := v.VerifySignature(bytes.NewReader(entry.Spec.Signature.Content), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying signature: %v", err)
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("unexpected error marshaling entry: %v", err)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(b, &body); err != nil {
+		t.Fatalf("unexpected error unmarshaling entry: %v", err)
+	}
+	if body["apiVersion"] != "0.0.1" || body["kind"] != "hashedrekord" {
+		t.Errorf("unexpected entry type: %s", b)
+	}
+}
+
+func TestNewHashedRekordErrors(t *testing.T) {
+	digest := sha256.Sum256([]byte("message"))
+	keyPEM := []byte("-----BEGIN PUBLIC KEY-----\nAA==\n-----END PUBLIC KEY-----\n")
+	tests := []struct {
+		name     string
+		sig      []byte
+		digest   []byte
+		hashFunc crypto.Hash
+		key      []byte
+	}{
+		{"unsupported hash", []byte("sig"), digest[:20], crypto.SHA1, keyPEM},
+		{"digest length", []byte("sig"), digest[:20], crypto.SHA256, keyPEM},
+		{"empty signature", nil, digest[:], crypto.SHA256, keyPEM},
+		{"not PEM", []byte("sig"), digest[:], crypto.SHA256, []byte("key")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewHashedRekord(tt.sig, tt.digest, tt.hashFunc, tt.key); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// ErrHashStateUnsupported is returned when the intermediate state of a hash function cannot be serialized
+var ErrHashStateUnsupported = errors.New("hash function does not support state serialization")
+
+const hashStateHeader = "sigstore hash state v1\n"
+
+// ResumableHasher computes a message digest incrementally, and can checkpoint its progress with
+// MarshalBinary so that hashing a very large message can be resumed with UnmarshalResumableHasher
+// after an interruption. It implements io.Writer.
+type ResumableHasher struct {
+	hashFunc crypto.Hash
+	h        hash.Hash
+	n        uint64
+}
+
+// NewResumableHasher returns a ResumableHasher for hashFunc. ErrHashStateUnsupported is returned
+// if hashFunc's implementation does not implement encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler (the standard library SHA-2 implementations do).
+func NewResumableHasher(hashFunc crypto.Hash) (*ResumableHasher, error) {
+	if !hashFunc.Available() {
+		return nil, fmt.Errorf("unsupported hash algorithm: %q", hashFunc.String())
+	}
+	h := hashFunc.New()
+	if _, ok := h.(encoding.BinaryMarshaler); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrHashStateUnsupported, hashFunc.String())
+	}
+	if _, ok := h.(encoding.BinaryUnmarshaler); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrHashStateUnsupported, hashFunc.String())
+	}
+	return &ResumableHasher{hashFunc: hashFunc, h: h}, nil
+}
+
+// Write adds p to the message being hashed
+func (r *ResumableHasher) Write(p []byte) (int, error) {
+	n, err := r.h.Write(p)
+	r.n += uint64(n)
+	return n, err
+}
+
+// HashFunc returns the hash function used by the hasher
+func (r *ResumableHasher) HashFunc() crypto.Hash {
+	return r.hashFunc
+}
+
+// BytesHashed returns the number of message bytes written so far, i.e. the offset in the message at
+// which to continue writing after resuming from a checkpoint
+func (r *ResumableHasher) BytesHashed() uint64 {
+	return r.n
+}
+
+// Sum returns the digest of the message written so far
+func (r *ResumableHasher) Sum() []byte {
+	return r.h.Sum(nil)
+}
+
+// MarshalBinary returns an opaque checkpoint of the hasher's state, which includes the hash
+// function and the number of bytes hashed
+func (r *ResumableHasher) MarshalBinary() ([]byte, error) {
+	state, err := r.h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling hash state: %w", err)
+	}
+	b := append([]byte(hashStateHeader), binary.BigEndian.AppendUint32(nil, uint32(r.hashFunc))...)
+	b = binary.BigEndian.AppendUint64(b, r.n)
+	return append(b, state...), nil
+}
+
+// UnmarshalResumableHasher restores a ResumableHasher from a checkpoint created by MarshalBinary
+func UnmarshalResumableHasher(data []byte) (*ResumableHasher, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(hashStateHeader))
+	if !ok || len(rest) < 12 {
+		return nil, errors.New("invalid hash state")
+	}
+	r, err := NewResumableHasher(crypto.Hash(binary.BigEndian.Uint32(rest)))
+	if err != nil {
+		return nil, err
+	}
+	r.n = binary.BigEndian.Uint64(rest[4:])
+	if err := r.h.(encoding.BinaryUnmarshaler).UnmarshalBinary(rest[12:]); err != nil {
+		return nil, fmt.Errorf("unmarshaling hash state: %w", err)
+	}
+	return r, nil
+}
+
+// SignDigest signs the digest of the message written to r with s
+func (r *ResumableHasher) SignDigest(s Signer, opts ...SignOption) ([]byte, error) {
+	digest := r.Sum()
+	opts = append(opts[:len(opts):len(opts)], options.WithDigest(digest), options.WithCryptoSignerOpts(r.hashFunc))
+	return s.SignMessage(bytes.NewReader(digest), opts...)
+}
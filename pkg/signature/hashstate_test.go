@@ -0,0 +1,98 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	_ "crypto/sha512" // register SHA-512 for crypto.Hash lookups
+	"errors"
+	"testing"
+
+	_ "golang.org/x/crypto/sha3" // register SHA3 for crypto.Hash lookups
+)
+
+func TestResumableHasher(t *testing.T) {
+	message := bytes.Repeat([]byte("0123456789"), 1000)
+	split := 4321
+
+	r, err := NewResumableHasher(crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error creating hasher: %v", err)
+	}
+	if _, err := r.Write(message[:split]); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error marshaling state: %v", err)
+	}
+
+	resumed, err := UnmarshalResumableHasher(checkpoint)
+	if err != nil {
+		t.Fatalf("unexpected error unmarshaling state: %v", err)
+	}
+	if resumed.HashFunc() != crypto.SHA256 {
+		t.Errorf("unexpected hash function %v", resumed.HashFunc())
+	}
+	if resumed.BytesHashed() != uint64(split) {
+		t.Fatalf("expected %d bytes hashed, got %d", split, resumed.BytesHashed())
+	}
+	if _, err := resumed.Write(message[split:]); err != nil {
+		t.Fatal(err)
+	}
+	want := sha256.Sum256(message)
+	if !bytes.Equal(resumed.Sum(), want[:]) {
+		t.Error("resumed digest does not match digest of whole message")
+	}
+
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	sig, err := resumed.SignDigest(sv)
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying signature: %v", err)
+	}
+}
+
+func TestResumableHasherErrors(t *testing.T) {
+	if _, err := NewResumableHasher(crypto.SHA3_256); !errors.Is(err, ErrHashStateUnsupported) {
+		t.Errorf("expected ErrHashStateUnsupported, got %v", err)
+	}
+	if _, err := NewResumableHasher(crypto.BLAKE2b_256); err == nil {
+		t.Error("expected error for unavailable hash function")
+	}
+
+	r, err := NewResumableHasher(crypto.SHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnmarshalResumableHasher(checkpoint[:len(checkpoint)-1]); err == nil {
+		t.Error("expected error for truncated state")
+	}
+	if _, err := UnmarshalResumableHasher([]byte("garbage")); err == nil {
+		t.Error("expected error for invalid state")
+	}
+}
@@ -0,0 +1,57 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"fmt"
+)
+
+// WeakHashError is returned when verifying a signature whose digest was computed with a hash
+// function weaker than allowed by options.WithMinimumHash or options.WithAllowSHA1
+type WeakHashError struct {
+	// Hash is the hash function the digest was computed with
+	Hash crypto.Hash
+	// Minimum is the minimum hash function required, or crypto.Hash(0) if SHA-1 was rejected
+	// by options.WithAllowSHA1(false) alone
+	Minimum crypto.Hash
+}
+
+func (e *WeakHashError) Error() string {
+	if e.Minimum == crypto.Hash(0) {
+		return fmt.Sprintf("hash function %v is not allowed", e.Hash)
+	}
+	return fmt.Sprintf("hash function %v is weaker than the required minimum of %v", e.Hash, e.Minimum)
+}
+
+// checkHashStrength returns a *WeakHashError if hashedWith produces digests shorter than minimum,
+// unless it is SHA-1 and allowSHA1 is set to true. If allowSHA1 is set to false, SHA-1 is rejected
+// even without a minimum. Messages that are not hashed (crypto.Hash(0)) are not checked.
+func checkHashStrength(hashedWith, minimum crypto.Hash, allowSHA1 *bool) error {
+	if hashedWith == crypto.Hash(0) {
+		return nil
+	}
+	if hashedWith == crypto.SHA1 && allowSHA1 != nil {
+		if *allowSHA1 {
+			return nil
+		}
+		return &WeakHashError{Hash: hashedWith, Minimum: minimum}
+	}
+	if minimum != crypto.Hash(0) && hashedWith.Size() < minimum.Size() {
+		return &WeakHashError{Hash: hashedWith, Minimum: minimum}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" // nolint:gosec
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestMinimumHash(t *testing.T) {
+	message := []byte("signed with a weak hash")
+	sha1Digest := sha1.Sum(message) // nolint:gosec
+	sha256Digest := sha256.Sum256(message)
+
+	_, ecdsaPriv, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, rsaPriv, err := NewDefaultRSAPKCS1v15SignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaV, err := LoadECDSAVerifier(&ecdsaPriv.PublicKey, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1v15V, err := LoadRSAPKCS1v15Verifier(&rsaPriv.PublicKey, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pssV, err := LoadRSAPSSVerifier(&rsaPriv.PublicKey, crypto.SHA256, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(f func(digest []byte, hf crypto.Hash) ([]byte, error), digest []byte, hf crypto.Hash) []byte {
+		t.Helper()
+		sig, err := f(digest, hf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	ecdsaSign := func(digest []byte, _ crypto.Hash) ([]byte, error) {
+		return ecdsa.SignASN1(rand.Reader, ecdsaPriv, digest)
+	}
+	pkcs1v15Sign := func(digest []byte, hf crypto.Hash) ([]byte, error) {
+		return rsa.SignPKCS1v15(rand.Reader, rsaPriv, hf, digest)
+	}
+	pssSign := func(digest []byte, hf crypto.Hash) ([]byte, error) {
+		return rsa.SignPSS(rand.Reader, rsaPriv, hf, digest, nil)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		verifier  Verifier
+		sha1Sig   []byte
+		sha256Sig []byte
+	}{
+		{"ecdsa", ecdsaV, sign(ecdsaSign, sha1Digest[:], crypto.SHA1), sign(ecdsaSign, sha256Digest[:], crypto.SHA256)},
+		{"rsa pkcs1v15", pkcs1v15V, sign(pkcs1v15Sign, sha1Digest[:], crypto.SHA1), sign(pkcs1v15Sign, sha256Digest[:], crypto.SHA256)},
+		{"rsa pss", pssV, sign(pssSign, sha1Digest[:], crypto.SHA1), sign(pssSign, sha256Digest[:], crypto.SHA256)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			verifySHA1 := func(opts ...VerifyOption) error {
+				opts = append(opts, options.WithCryptoSignerOpts(crypto.SHA1))
+				return tc.verifier.VerifySignature(bytes.NewReader(tc.sha1Sig), bytes.NewReader(message), opts...)
+			}
+
+			// nothing is enforced without a policy, for compatibility, except that ECDSA rejects a
+			// per-call hash weaker than the one paired with the key's curve
+			if err := verifySHA1(); (err != nil) != (tc.name == "ecdsa") {
+				t.Errorf("unexpected result verifying SHA-1 signature without a policy: %v", err)
+			}
+			if err := verifySHA1(options.WithAllowSHA1(true)); err != nil {
+				t.Errorf("unexpected error verifying SHA-1 signature when it is explicitly allowed: %v", err)
+			}
+
+			var weak *WeakHashError
+			if err := verifySHA1(options.WithMinimumHash(crypto.SHA256)); !errors.As(err, &weak) {
+				t.Errorf("expected *WeakHashError for SHA-1 below a SHA-256 minimum, got %v", err)
+			} else if weak.Hash != crypto.SHA1 || weak.Minimum != crypto.SHA256 {
+				t.Errorf("unexpected error fields: %+v", weak)
+			}
+			if err := verifySHA1(options.WithAllowSHA1(false)); !errors.As(err, &weak) {
+				t.Errorf("expected *WeakHashError for SHA-1 when it is disallowed, got %v", err)
+			}
+			if err := verifySHA1(options.WithMinimumHash(crypto.SHA256), options.WithAllowSHA1(true)); err != nil {
+				t.Errorf("unexpected error verifying SHA-1 signature when it is explicitly allowed: %v", err)
+			}
+
+			if err := tc.verifier.VerifySignature(bytes.NewReader(tc.sha256Sig), bytes.NewReader(message), options.WithMinimumHash(crypto.SHA256), options.WithAllowSHA1(false)); err != nil {
+				t.Errorf("unexpected error verifying SHA-256 signature: %v", err)
+			}
+			if err := tc.verifier.VerifySignature(bytes.NewReader(tc.sha256Sig), bytes.NewReader(message), options.WithMinimumHash(crypto.SHA384)); !errors.As(err, &weak) {
+				t.Errorf("expected *WeakHashError for SHA-256 below a SHA-384 minimum, got %v", err)
+			}
+		})
+	}
+
+	t.Run("ed25519ph", func(t *testing.T) {
+		sv, _, err := NewDefaultED25519phSignerVerifier()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := sv.SignMessage(bytes.NewReader(message))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithMinimumHash(crypto.SHA512), options.WithAllowSHA1(false)); err != nil {
+			t.Errorf("unexpected error verifying SHA-512 prehashed signature: %v", err)
+		}
+	})
+}
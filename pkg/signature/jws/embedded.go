@@ -0,0 +1,141 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// ErrEmbeddedKeyNotTrusted is returned by VerifyEmbedded when no trust callback is given, so
+// that a key supplied by the token itself is never accepted implicitly
+var ErrEmbeddedKeyNotTrusted = errors.New("embedded JWS key is not trusted")
+
+// EmbeddedKey is the verification material carried in the "jwk" and "x5c" headers of a JWS
+type EmbeddedKey struct {
+	// PublicKey is the key from the "jwk" header or, if there is none, from the first
+	// certificate of the "x5c" header
+	PublicKey crypto.PublicKey
+	// Certificates is the chain from the "x5c" header, leaf first, or nil if there is none.
+	// The chain is parsed but not validated; that is left to the trust callback.
+	Certificates []*x509.Certificate
+}
+
+// EmbeddedKeyTrustFunc decides whether the key embedded in a JWS is trusted, returning nil to
+// accept it. It is only called for tokens whose signature verifies with that key.
+type EmbeddedKeyTrustFunc func(*EmbeddedKey) error
+
+// VerifyEmbedded verifies the JWS compact serialization token with the public key embedded in
+// its "jwk" header, or in the leaf certificate of its "x5c" header, and then passes the embedded
+// key to trust to decide whether it is acceptable. On success it returns the payload and the
+// embedded key. If both headers are present the "jwk" key must match the leaf certificate.
+//
+// A token can name any key it likes, so verifying against the embedded key alone proves nothing;
+// trust must establish that the key is acceptable, e.g. by checking it against a pinned
+// fingerprint or validating the certificate chain. If trust is nil the token is rejected with
+// ErrEmbeddedKeyNotTrusted.
+func VerifyEmbedded(token string, trust EmbeddedKeyTrustFunc, opts ...signature.VerifyOption) ([]byte, *EmbeddedKey, error) {
+	if trust == nil {
+		return nil, nil, ErrEmbeddedKeyNotTrusted
+	}
+	t, err := parse(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := t.embeddedKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	v, err := verifierForAlgorithm(t.header.Algorithm, key.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := t.verify(v, opts...); err != nil {
+		return nil, nil, err
+	}
+	if err := trust(key); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrEmbeddedKeyNotTrusted, err)
+	}
+	return t.payload, key, nil
+}
+
+func (t *parsedToken) embeddedKey() (*EmbeddedKey, error) {
+	key := &EmbeddedKey{}
+	for i, enc := range t.header.X5C {
+		// x5c uses standard base64 with padding, unlike the rest of the JWS
+		der, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x5c certificate %d: %w", i, err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("parsing x5c certificate %d: %w", i, err)
+		}
+		key.Certificates = append(key.Certificates, cert)
+	}
+	if len(t.header.JWK) > 0 {
+		var jwk jose.JSONWebKey
+		if err := jwk.UnmarshalJSON(t.header.JWK); err != nil {
+			return nil, fmt.Errorf("parsing jwk header: %w", err)
+		}
+		if !jwk.IsPublic() {
+			return nil, errors.New("jwk header must contain a public key")
+		}
+		key.PublicKey = jwk.Key
+	}
+	switch {
+	case key.PublicKey == nil && len(key.Certificates) == 0:
+		return nil, errors.New("JWS header has no embedded jwk or x5c key")
+	case key.PublicKey == nil:
+		key.PublicKey = key.Certificates[0].PublicKey
+	case len(key.Certificates) > 0:
+		if err := cryptoutils.EqualKeys(key.PublicKey, key.Certificates[0].PublicKey); err != nil {
+			return nil, fmt.Errorf("jwk header does not match x5c leaf certificate: %w", err)
+		}
+	}
+	return key, nil
+}
+
+// verifierForAlgorithm returns a verifier for pub that implements the JWS algorithm alg
+func verifierForAlgorithm(alg string, pub crypto.PublicKey) (signature.Verifier, error) {
+	hf, ok := algorithmHashes[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported JWS algorithm %q", alg)
+	}
+	switch pk := pub.(type) {
+	case *ecdsa.PublicKey:
+		return signature.LoadECDSAVerifier(pk, hf)
+	case *rsa.PublicKey:
+		switch alg {
+		case AlgorithmPS256, AlgorithmPS384, AlgorithmPS512:
+			return signature.LoadRSAPSSVerifier(pk, hf, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return signature.LoadRSAPKCS1v15Verifier(pk, hf)
+	case ed25519.PublicKey:
+		return signature.LoadED25519Verifier(pk)
+	}
+	return nil, fmt.Errorf("unsupported embedded key type %T", pub)
+}
@@ -0,0 +1,167 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/test"
+)
+
+func signWithJose(t *testing.T, alg jose.SignatureAlgorithm, key interface{}, opts *jose.SignerOptions, payload []byte) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, opts)
+	if err != nil {
+		t.Fatalf("unexpected error creating go-jose signer: %v", err)
+	}
+	obj, err := signer.Sign(payload)
+	if err != nil {
+		t.Fatalf("unexpected error signing with go-jose: %v", err)
+	}
+	token, err := obj.CompactSerialize()
+	if err != nil {
+		t.Fatalf("unexpected error serializing: %v", err)
+	}
+	return token
+}
+
+func TestVerifyEmbeddedJWK(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	payload := []byte(`{"hello":"world"}`)
+
+	for _, tt := range []struct {
+		name string
+		alg  jose.SignatureAlgorithm
+		key  crypto.Signer
+	}{
+		{"ES256", jose.ES256, ecKey},
+		{"RS256", jose.RS256, rsaKey},
+		{"PS256", jose.PS256, rsaKey},
+		{"EdDSA", jose.EdDSA, edKey},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signWithJose(t, tt.alg, tt.key, &jose.SignerOptions{EmbedJWK: true}, payload)
+
+			var seen crypto.PublicKey
+			got, key, err := VerifyEmbedded(token, func(k *EmbeddedKey) error {
+				seen = k.PublicKey
+				return cryptoutils.EqualKeys(k.PublicKey, tt.key.Public())
+			})
+			if err != nil {
+				t.Fatalf("unexpected error verifying: %v", err)
+			}
+			if string(got) != string(payload) {
+				t.Errorf("expected payload %q, got %q", payload, got)
+			}
+			if err := cryptoutils.EqualKeys(key.PublicKey, tt.key.Public()); err != nil || seen == nil {
+				t.Errorf("expected embedded key to be returned and passed to the trust callback: %v", err)
+			}
+
+			// untrusted unless a callback accepts the key
+			if _, _, err := VerifyEmbedded(token, nil); !errors.Is(err, ErrEmbeddedKeyNotTrusted) {
+				t.Errorf("expected ErrEmbeddedKeyNotTrusted without a callback, got %v", err)
+			}
+			errUnknown := errors.New("unknown key")
+			if _, _, err := VerifyEmbedded(token, func(*EmbeddedKey) error { return errUnknown }); !errors.Is(err, ErrEmbeddedKeyNotTrusted) || !errors.Is(err, errUnknown) {
+				t.Errorf("expected rejected key to be reported, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyEmbeddedX5C(t *testing.T) {
+	rootCert, rootKey, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafCert, leafKey, err := test.GenerateLeafCert("subject@example.com", "oidc-issuer", rootCert, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x5c := []string{base64.StdEncoding.EncodeToString(leafCert.Raw), base64.StdEncoding.EncodeToString(rootCert.Raw)}
+	payload := []byte("payload")
+	roots := x509.NewCertPool()
+	roots.AddCert(rootCert)
+	chainTrust := func(k *EmbeddedKey) error {
+		intermediates := x509.NewCertPool()
+		for _, c := range k.Certificates[1:] {
+			intermediates.AddCert(c)
+		}
+		_, err := k.Certificates[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   k.Certificates[0].NotBefore,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		return err
+	}
+
+	token := signWithJose(t, jose.ES256, leafKey, (&jose.SignerOptions{}).WithHeader("x5c", x5c), payload)
+	_, key, err := VerifyEmbedded(token, chainTrust)
+	if err != nil {
+		t.Fatalf("unexpected error verifying x5c token: %v", err)
+	}
+	if len(key.Certificates) != 2 || !key.Certificates[0].Equal(leafCert) {
+		t.Errorf("expected x5c chain to be returned")
+	}
+
+	// jwk and x5c together must describe the same key
+	token = signWithJose(t, jose.ES256, leafKey, (&jose.SignerOptions{EmbedJWK: true}).WithHeader("x5c", x5c), payload)
+	if _, _, err := VerifyEmbedded(token, chainTrust); err != nil {
+		t.Errorf("unexpected error verifying token with jwk and x5c: %v", err)
+	}
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	token = signWithJose(t, jose.ES256, otherKey, (&jose.SignerOptions{EmbedJWK: true}).WithHeader("x5c", x5c), payload)
+	if _, _, err := VerifyEmbedded(token, chainTrust); err == nil {
+		t.Error("expected error when jwk does not match the x5c leaf certificate")
+	}
+	// a signature by another key does not verify against the x5c leaf
+	token = signWithJose(t, jose.ES256, otherKey, (&jose.SignerOptions{}).WithHeader("x5c", x5c), payload)
+	if _, _, err := VerifyEmbedded(token, func(*EmbeddedKey) error { return nil }); err == nil {
+		t.Error("expected error verifying signature from a key other than the leaf certificate's")
+	}
+}
+
+func TestVerifyEmbeddedInvalid(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	acceptAll := func(*EmbeddedKey) error { return nil }
+
+	token := signWithJose(t, jose.ES256, ecKey, nil, []byte("payload"))
+	if _, _, err := VerifyEmbedded(token, acceptAll); err == nil {
+		t.Error("expected error verifying token without an embedded key")
+	}
+	token = signWithJose(t, jose.ES256, ecKey, (&jose.SignerOptions{}).WithHeader("x5c", []string{"not base64!"}), []byte("payload"))
+	if _, _, err := VerifyEmbedded(token, acceptAll); err == nil {
+		t.Error("expected error verifying token with malformed x5c")
+	}
+	token = signWithJose(t, jose.ES256, ecKey, (&jose.SignerOptions{}).WithHeader("jwk", jose.JSONWebKey{Key: ecKey, Algorithm: "ES256"}), []byte("payload"))
+	if _, _, err := VerifyEmbedded(token, acceptAll); err == nil {
+		t.Error("expected error verifying token with a private jwk")
+	}
+}
@@ -0,0 +1,304 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jws
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// Algorithm names from RFC 7518 and RFC 8037
+const (
+	AlgorithmES256 = "ES256"
+	AlgorithmES384 = "ES384"
+	AlgorithmES512 = "ES512"
+	AlgorithmRS256 = "RS256"
+	AlgorithmRS384 = "RS384"
+	AlgorithmRS512 = "RS512"
+	AlgorithmPS256 = "PS256"
+	AlgorithmPS384 = "PS384"
+	AlgorithmPS512 = "PS512"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var algorithmHashes = map[string]crypto.Hash{
+	AlgorithmES256: crypto.SHA256,
+	AlgorithmES384: crypto.SHA384,
+	AlgorithmES512: crypto.SHA512,
+	AlgorithmRS256: crypto.SHA256,
+	AlgorithmRS384: crypto.SHA384,
+	AlgorithmRS512: crypto.SHA512,
+	AlgorithmPS256: crypto.SHA256,
+	AlgorithmPS384: crypto.SHA384,
+	AlgorithmPS512: crypto.SHA512,
+	AlgorithmEdDSA: crypto.Hash(0),
+}
+
+type header struct {
+	Algorithm string          `json:"alg"`
+	Type      string          `json:"typ,omitempty"`
+	JWK       json.RawMessage `json:"jwk,omitempty"`
+	X5C       []string        `json:"x5c,omitempty"`
+}
+
+// Sign returns the JWS compact serialization of payload signed by s. The "alg" header is
+// derived from the signer's public key: ES256, ES384 or ES512 for ECDSA keys on P-256, P-384
+// or P-521, PS256 for RSA-PSS signers, RS256 for other RSA signers and EdDSA for Ed25519 keys.
+// The signing options are passed to s, and must not specify a digest or hash function.
+//
+// RFC 7518 requires the PSS salt length to equal the hash size, so RSA-PSS signers should be
+// loaded with rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}.
+func Sign(s signature.Signer, payload []byte, opts ...signature.SignOption) (string, error) {
+	var b strings.Builder
+	if err := SignTo(&b, s, payload, opts...); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// SignTo writes the JWS compact serialization of payload signed by s to w, exactly as returned
+// by Sign. The base64url encoding of payload is streamed into w and, for every algorithm but
+// EdDSA, into the hash of the signing input, so no encoded copy of a large payload is held in
+// memory. Nothing is written to w if signing fails.
+func SignTo(w io.Writer, s signature.Signer, payload []byte, opts ...signature.SignOption) error {
+	pub, err := s.PublicKey()
+	if err != nil {
+		return fmt.Errorf("getting public key: %w", err)
+	}
+	alg, err := signingAlgorithm(s, pub)
+	if err != nil {
+		return err
+	}
+
+	h, err := json.Marshal(header{Algorithm: alg})
+	if err != nil {
+		return err
+	}
+	encodedHeader := base64.RawURLEncoding.EncodeToString(h)
+	writeSigningInput := func(dst io.Writer) error {
+		if _, err := io.WriteString(dst, encodedHeader+"."); err != nil {
+			return err
+		}
+		enc := base64.NewEncoder(base64.RawURLEncoding, dst)
+		if _, err := enc.Write(payload); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	var sig []byte
+	if hf := algorithmHashes[alg]; hf == crypto.Hash(0) {
+		// EdDSA signs the signing input itself rather than a digest of it
+		var signingInput bytes.Buffer
+		if err := writeSigningInput(&signingInput); err != nil {
+			return err
+		}
+		sig, err = s.SignMessage(&signingInput, opts...)
+	} else {
+		hasher := hf.New()
+		if err := writeSigningInput(hasher); err != nil {
+			return err
+		}
+		sig, err = s.SignMessage(nil, append(opts, options.WithDigest(hasher.Sum(nil)), options.WithCryptoSignerOpts(hf))...)
+	}
+	if err != nil {
+		return err
+	}
+
+	if ecPub, ok := pub.(*ecdsa.PublicKey); ok {
+		// JWS uses the fixed-length R||S encoding rather than DER
+		r, ss, err := cryptoutils.UnmarshalECDSASignature(sig)
+		if err != nil {
+			return err
+		}
+		if sig, err = cryptoutils.MarshalECDSASignatureRaw(ecPub.Curve, r, ss); err != nil {
+			return err
+		}
+	}
+	if err := writeSigningInput(w); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "."+base64.RawURLEncoding.EncodeToString(sig))
+	return err
+}
+
+// Verify verifies the JWS compact serialization token with v and returns its payload.
+// Tokens with the "none" algorithm, or an algorithm that does not match the verifier's
+// public key, are rejected.
+func Verify(v signature.Verifier, token string, opts ...signature.VerifyOption) ([]byte, error) {
+	t, err := parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.verify(v, opts...); err != nil {
+		return nil, err
+	}
+	return t.payload, nil
+}
+
+// parsedToken is a decoded JWS compact serialization
+type parsedToken struct {
+	header       header
+	payload      []byte
+	signature    []byte
+	signingInput string
+}
+
+func parse(token string) (*parsedToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid JWS compact serialization: expected three parts")
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWS header: %w", err)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWS payload: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWS signature: %w", err)
+	}
+
+	h := header{}
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return nil, fmt.Errorf("parsing JWS header: %w", err)
+	}
+	if strings.EqualFold(h.Algorithm, "none") {
+		return nil, errors.New("unsigned JWS (alg \"none\") is not accepted")
+	}
+	return &parsedToken{
+		header:       h,
+		payload:      payload,
+		signature:    sig,
+		signingInput: parts[0] + "." + parts[1],
+	}, nil
+}
+
+func (t *parsedToken) verify(v signature.Verifier, opts ...signature.VerifyOption) error {
+	pub, err := v.PublicKey()
+	if err != nil {
+		return fmt.Errorf("getting public key: %w", err)
+	}
+	allowed, err := verifierAlgorithms(v, pub)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(allowed, t.header.Algorithm) {
+		return fmt.Errorf("JWS algorithm %q does not match the verification key (expected one of %v)", t.header.Algorithm, allowed)
+	}
+
+	sig := t.signature
+	if ecPub, ok := pub.(*ecdsa.PublicKey); ok {
+		if len(sig) != 2*((ecPub.Curve.Params().BitSize+7)/8) {
+			return errors.New("invalid JWS signature length")
+		}
+		r, s, err := cryptoutils.UnmarshalECDSASignatureRaw(ecPub.Curve, sig)
+		if err != nil {
+			return err
+		}
+		if sig, err = cryptoutils.MarshalECDSASignature(r, s); err != nil {
+			return err
+		}
+	}
+
+	hf := algorithmHashes[t.header.Algorithm]
+	if hf == crypto.Hash(0) {
+		return v.VerifySignature(bytes.NewReader(sig), strings.NewReader(t.signingInput), opts...)
+	}
+	digest := hashSigningInput(hf, t.signingInput)
+	return v.VerifySignature(bytes.NewReader(sig), nil, append(opts, options.WithDigest(digest), options.WithCryptoSignerOpts(hf))...)
+}
+
+func hashSigningInput(hf crypto.Hash, signingInput string) []byte {
+	h := hf.New()
+	h.Write([]byte(signingInput))
+	return h.Sum(nil)
+}
+
+func signingAlgorithm(s signature.Signer, pub crypto.PublicKey) (string, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		switch s.(type) {
+		case *signature.RSAPSSSigner, *signature.RSAPSSSignerVerifier:
+			return AlgorithmPS256, nil
+		}
+		return AlgorithmRS256, nil
+	default:
+		algs, err := allowedAlgorithms(pub)
+		if err != nil {
+			return "", err
+		}
+		return algs[0], nil
+	}
+}
+
+// verifierAlgorithms returns the JWS algorithms that v can verify. For RSA keys, the algorithm
+// family is chosen by the type of v, so that e.g. a PS256 token is not accepted by an RSA
+// PKCS#1 v1.5 verifier; verifiers of other types (e.g. KMS verifiers) are offered both families
+// and must reject the padding they do not implement.
+func verifierAlgorithms(v signature.Verifier, pub crypto.PublicKey) ([]string, error) {
+	switch v.(type) {
+	case *signature.RSAPKCS1v15Verifier, *signature.RSAPKCS1v15SignerVerifier:
+		if _, ok := pub.(*rsa.PublicKey); ok {
+			return []string{AlgorithmRS256, AlgorithmRS384, AlgorithmRS512}, nil
+		}
+	case *signature.RSAPSSVerifier, *signature.RSAPSSSignerVerifier:
+		if _, ok := pub.(*rsa.PublicKey); ok {
+			return []string{AlgorithmPS256, AlgorithmPS384, AlgorithmPS512}, nil
+		}
+	case *signature.ED25519phVerifier, *signature.ED25519phSignerVerifier:
+		return nil, errors.New("Ed25519ph verifiers cannot verify EdDSA JWS signatures, which sign the message directly")
+	}
+	return allowedAlgorithms(pub)
+}
+
+func allowedAlgorithms(pub crypto.PublicKey) ([]string, error) {
+	switch pk := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch pk.Curve {
+		case elliptic.P256():
+			return []string{AlgorithmES256}, nil
+		case elliptic.P384():
+			return []string{AlgorithmES384}, nil
+		case elliptic.P521():
+			return []string{AlgorithmES512}, nil
+		}
+		return nil, fmt.Errorf("unsupported elliptic curve %s", pk.Curve.Params().Name)
+	case *rsa.PublicKey:
+		return []string{AlgorithmRS256, AlgorithmRS384, AlgorithmRS512, AlgorithmPS256, AlgorithmPS384, AlgorithmPS512}, nil
+	case ed25519.PublicKey:
+		return []string{AlgorithmEdDSA}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", pub)
+}
@@ -0,0 +1,195 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jws
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	ecP256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecP384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	ecP256SV, _ := signature.LoadECDSASignerVerifier(ecP256, crypto.SHA256)
+	ecP384SV, _ := signature.LoadECDSASignerVerifier(ecP384, crypto.SHA384)
+	rsaSV, _ := signature.LoadRSAPKCS1v15SignerVerifier(rsaKey, crypto.SHA256)
+	pssSV, _ := signature.LoadRSAPSSSignerVerifier(rsaKey, crypto.SHA256, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	edSV, _ := signature.LoadED25519SignerVerifier(edKey)
+
+	tests := []struct {
+		name    string
+		sv      signature.SignerVerifier
+		wantAlg jose.SignatureAlgorithm
+		pub     crypto.PublicKey
+	}{
+		{name: "ES256", sv: ecP256SV, wantAlg: jose.ES256, pub: &ecP256.PublicKey},
+		{name: "ES384", sv: ecP384SV, wantAlg: jose.ES384, pub: &ecP384.PublicKey},
+		{name: "RS256", sv: rsaSV, wantAlg: jose.RS256, pub: &rsaKey.PublicKey},
+		{name: "PS256", sv: pssSV, wantAlg: jose.PS256, pub: &rsaKey.PublicKey},
+		{name: "EdDSA", sv: edSV, wantAlg: jose.EdDSA, pub: edKey.Public()},
+	}
+	payload := []byte(`{"sub":"foo"}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Sign(tt.sv, payload)
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+
+			got, err := Verify(tt.sv, token)
+			if err != nil {
+				t.Fatalf("unexpected error verifying: %v", err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Errorf("expected payload %q, got %q", payload, got)
+			}
+
+			// cross-check with an independent JWS implementation
+			parsed, err := jose.ParseSigned(token)
+			if err != nil {
+				t.Fatalf("go-jose failed to parse token: %v", err)
+			}
+			if alg := parsed.Signatures[0].Header.Algorithm; alg != string(tt.wantAlg) {
+				t.Errorf("expected alg %s, got %s", tt.wantAlg, alg)
+			}
+			if _, err := parsed.Verify(tt.pub); err != nil {
+				t.Errorf("go-jose failed to verify token: %v", err)
+			}
+
+			parts := strings.Split(token, ".")
+			tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"bar"}`)) + "." + parts[2]
+			if _, err := Verify(tt.sv, tampered); err == nil {
+				t.Error("expected error verifying tampered token")
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsAlgorithm(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecSV, _ := signature.LoadECDSASignerVerifier(ecKey, crypto.SHA256)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaSV, _ := signature.LoadRSAPKCS1v15SignerVerifier(rsaKey, crypto.SHA256)
+
+	token, err := Sign(ecSV, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+
+	noneToken := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+	if _, err := Verify(ecSV, noneToken); err == nil || !strings.Contains(err.Error(), "none") {
+		t.Errorf("expected alg none to be rejected, got %v", err)
+	}
+
+	if _, err := Verify(rsaSV, token); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected alg/key mismatch to be rejected, got %v", err)
+	}
+
+	rs256Token := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256"}`)) + "." + parts[1] + "." + parts[2]
+	if _, err := Verify(ecSV, rs256Token); err == nil {
+		t.Error("expected RS256 header with an ECDSA key to be rejected")
+	}
+
+	// an RSA key does not make every RSA algorithm acceptable to every RSA verifier
+	pssSV, _ := signature.LoadRSAPSSSignerVerifier(rsaKey, crypto.SHA256, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	rsToken, err := Sign(rsaSV, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	psToken, err := Sign(pssSV, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(pssSV, rsToken); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected RS256 token to be rejected by an RSA-PSS verifier, got %v", err)
+	}
+	if _, err := Verify(rsaSV, psToken); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected PS256 token to be rejected by an RSA PKCS#1 v1.5 verifier, got %v", err)
+	}
+	rsaParts := strings.Split(rsToken, ".")
+	relabeled := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"PS256"}`)) + "." + rsaParts[1] + "." + rsaParts[2]
+	if _, err := Verify(rsaSV, relabeled); err == nil {
+		t.Error("expected PKCS#1 v1.5 signature relabeled as PS256 to be rejected")
+	}
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edSV, _ := signature.LoadED25519SignerVerifier(edKey)
+	edphSV, _ := signature.LoadED25519phSignerVerifier(edKey)
+	edToken, err := Sign(edSV, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(edphSV, edToken); err == nil {
+		t.Error("expected EdDSA token to be rejected by an Ed25519ph verifier")
+	}
+
+	if _, err := Verify(ecSV, "a.b"); err == nil {
+		t.Error("expected malformed token to be rejected")
+	}
+}
+
+func TestSignTo(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaSV, _ := signature.LoadRSAPKCS1v15SignerVerifier(rsaKey, crypto.SHA256)
+	edSV, _ := signature.LoadED25519SignerVerifier(edKey)
+	ecSV, _, _ := signature.NewDefaultECDSASignerVerifier()
+
+	payload := bytes.Repeat([]byte("large payload "), 10000)
+	// RS256 and EdDSA signatures are deterministic, so the output must match Sign exactly
+	for name, sv := range map[string]signature.SignerVerifier{"RS256": rsaSV, "EdDSA": edSV} {
+		t.Run(name, func(t *testing.T) {
+			want, err := Sign(sv, payload)
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := SignTo(&buf, sv, payload); err != nil {
+				t.Fatalf("unexpected error signing to writer: %v", err)
+			}
+			if buf.String() != want {
+				t.Error("SignTo output does not match Sign")
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := SignTo(&buf, ecSV, payload); err != nil {
+		t.Fatalf("unexpected error signing to writer: %v", err)
+	}
+	got, err := Verify(ecSV, buf.String())
+	if err != nil {
+		t.Fatalf("unexpected error verifying: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Error("unexpected payload")
+	}
+}
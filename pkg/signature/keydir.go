@@ -0,0 +1,202 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+)
+
+// LoadKeySetVerifierFromDir returns a KeySetVerifier for the PEM-encoded public keys in the files
+// with a .pem extension directly inside dir. Each key is loaded with LoadVerifierWithOpts and the
+// given opts, and the verifiers are tried in lexical order of file name as described for
+// LoadKeySetVerifier.
+//
+// Files that cannot be read or parsed are skipped, and reported to the handler given with
+// options.WithSkippedFileHandler (if any); an error is returned only if dir cannot be listed or
+// none of its files yields a verifier.
+func LoadKeySetVerifierFromDir(dir string, concurrency int, opts ...LoadOption) (*KeySetVerifier, error) {
+	verifiers, err := loadKeyDir(dir, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return LoadKeySetVerifier(verifiers, concurrency)
+}
+
+// loadKeyDir loads a verifier from each .pem file in dir, reporting and skipping any that fail
+func loadKeyDir(dir string, opts ...LoadOption) ([]Verifier, error) {
+	var skipped func(error)
+	for _, opt := range opts {
+		opt.ApplySkippedFileHandler(&skipped)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading key directory: %w", err)
+	}
+	// os.ReadDir returns entries sorted by file name
+	var verifiers []Verifier
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".pem") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		v, err := loadKeyFile(path, opts...)
+		if err != nil {
+			if skipped != nil {
+				skipped(err)
+			}
+			continue
+		}
+		verifiers = append(verifiers, v)
+	}
+	if len(verifiers) == 0 {
+		return nil, fmt.Errorf("no usable public keys found in %s", dir)
+	}
+	return verifiers, nil
+}
+
+func loadKeyFile(path string, opts ...LoadOption) (Verifier, error) {
+	pemBytes, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, &FileError{Role: "key", Path: path, Err: err}
+	}
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey(pemBytes)
+	if err != nil {
+		return nil, &FileError{Role: "key", Path: path, Err: err}
+	}
+	v, err := LoadVerifierWithOpts(pub, opts...)
+	if err != nil {
+		return nil, &FileError{Role: "key", Path: path, Err: err}
+	}
+	return v, nil
+}
+
+// DirKeySetVerifier is a signature.Verifier backed by a directory of PEM-encoded public keys, as
+// loaded by LoadKeySetVerifierFromDir, that is reloaded in the background on a fixed interval so
+// that keys added to or removed from the directory take effect without a restart. The directory
+// is polled rather than watched, so changes are picked up within one interval.
+//
+// Each reload replaces the key set atomically; if a reload fails (e.g. the directory is missing
+// or contains no usable keys), the last successfully loaded key set continues to be used. Reloads
+// are serialized, so a slower reload never replaces the key set stored by a later one. It is safe
+// for concurrent use.
+type DirKeySetVerifier struct {
+	dir         string
+	concurrency int
+	opts        []LoadOption
+
+	current atomic.Pointer[KeySetVerifier]
+	// reloadMu is held across loading and storing a key set
+	reloadMu sync.Mutex
+	mu       sync.Mutex
+	lastErr  error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDirKeySetVerifier loads the key set from dir, returning an error if that fails, and then
+// reloads it every interval until ctx is done or Close is called. The concurrency and opts are
+// used as for LoadKeySetVerifierFromDir.
+func NewDirKeySetVerifier(ctx context.Context, dir string, interval time.Duration, concurrency int, opts ...LoadOption) (*DirKeySetVerifier, error) {
+	if interval <= 0 {
+		return nil, errors.New("reload interval must be positive")
+	}
+	ks, err := LoadKeySetVerifierFromDir(dir, concurrency, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("loading initial key set: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	d := &DirKeySetVerifier{
+		dir:         dir,
+		concurrency: concurrency,
+		opts:        opts,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	d.current.Store(ks)
+	go d.run(ctx, interval)
+	return d, nil
+}
+
+// Current returns the most recently loaded key set
+func (d *DirKeySetVerifier) Current() *KeySetVerifier {
+	return d.current.Load()
+}
+
+// LastError returns the error from the most recent reload, or nil if it succeeded
+func (d *DirKeySetVerifier) LastError() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.lastErr
+}
+
+// Reload loads the key set from the directory immediately. On failure the current key set is
+// kept and the error is returned.
+func (d *DirKeySetVerifier) Reload() error {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+	ks, err := LoadKeySetVerifierFromDir(d.dir, d.concurrency, d.opts...)
+	if err == nil {
+		d.current.Store(ks)
+	}
+	d.mu.Lock()
+	d.lastErr = err
+	d.mu.Unlock()
+	return err
+}
+
+// Close stops the background reload
+func (d *DirKeySetVerifier) Close() {
+	d.cancel()
+	<-d.done
+}
+
+// PublicKey is not supported, as a key set does not have a single public key
+func (d *DirKeySetVerifier) PublicKey(_ ...PublicKeyOption) (crypto.PublicKey, error) {
+	return nil, errors.New("not supported for key sets")
+}
+
+// VerifySignature verifies the signature for the given message against the current key set.
+// See KeySetVerifier.VerifySignature for details.
+func (d *DirKeySetVerifier) VerifySignature(signature, message io.Reader, opts ...VerifyOption) error {
+	return d.Current().VerifySignature(signature, message, opts...)
+}
+
+func (d *DirKeySetVerifier) run(ctx context.Context, interval time.Duration) {
+	defer close(d.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = d.Reload()
+		}
+	}
+}
@@ -0,0 +1,195 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func writeKeyFile(t *testing.T, path string, sv SignerVerifier) {
+	t.Helper()
+	pub, err := sv.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pemBytes, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadKeySetVerifierFromDir(t *testing.T) {
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ecdsa signer/verifier: %v", err)
+	}
+	ed25519SV, _, err := NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519 signer/verifier: %v", err)
+	}
+	otherSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ecdsa signer/verifier: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeKeyFile(t, filepath.Join(dir, "a.pem"), ecdsaSV)
+	writeKeyFile(t, filepath.Join(dir, "b.PEM"), ed25519SV)
+	// not loaded: wrong extension, malformed, or in a subdirectory
+	writeKeyFile(t, filepath.Join(dir, "c.pub"), otherSV)
+	if err := os.WriteFile(filepath.Join(dir, "bad.pem"), []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.pem"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	var skipped []error
+	verifiers, err := loadKeyDir(dir, options.WithSkippedFileHandler(func(err error) { skipped = append(skipped, err) }))
+	if err != nil {
+		t.Fatalf("unexpected error loading key directory: %v", err)
+	}
+	if len(verifiers) != 2 {
+		t.Fatalf("expected 2 verifiers, got %d", len(verifiers))
+	}
+	var fileErr *FileError
+	if len(skipped) != 1 || !errors.As(skipped[0], &fileErr) || fileErr.Path != filepath.Join(dir, "bad.pem") {
+		t.Errorf("expected bad.pem to be reported as skipped, got %v", skipped)
+	}
+
+	ks, err := LoadKeySetVerifierFromDir(dir, 1)
+	if err != nil {
+		t.Fatalf("unexpected error loading key set: %v", err)
+	}
+	message := []byte("sign me")
+	for name, sv := range map[string]SignerVerifier{"ecdsa": ecdsaSV, "ed25519": ed25519SV} {
+		sig, err := sv.SignMessage(bytes.NewReader(message))
+		if err != nil {
+			t.Fatalf("unexpected error signing with %s: %v", name, err)
+		}
+		if err := ks.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+			t.Errorf("unexpected error verifying %s signature: %v", name, err)
+		}
+	}
+	sig, err := otherSV.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	if err := ks.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err == nil {
+		t.Error("expected error verifying signature from key not in directory")
+	}
+
+	if _, err := LoadKeySetVerifierFromDir(t.TempDir(), 1); err == nil {
+		t.Error("expected error loading empty directory")
+	}
+	if _, err := LoadKeySetVerifierFromDir(filepath.Join(dir, "missing"), 1); err == nil {
+		t.Error("expected error loading missing directory")
+	}
+}
+
+func TestDirKeySetVerifier(t *testing.T) {
+	first, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	second, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeKeyFile(t, filepath.Join(dir, "first.pem"), first)
+
+	if _, err := NewDirKeySetVerifier(context.Background(), dir, 0, 1); err == nil {
+		t.Error("expected error for non-positive interval")
+	}
+	d, err := NewDirKeySetVerifier(context.Background(), dir, time.Hour, 1)
+	if err != nil {
+		t.Fatalf("unexpected error creating verifier: %v", err)
+	}
+	defer d.Close()
+
+	message := []byte("sign me")
+	firstSig, err := first.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondSig, err := second.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.VerifySignature(bytes.NewReader(firstSig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying with initial key set: %v", err)
+	}
+	if err := d.VerifySignature(bytes.NewReader(secondSig), bytes.NewReader(message)); err == nil {
+		t.Error("expected error verifying signature from key not yet added")
+	}
+
+	// a key added to the directory is picked up on reload
+	writeKeyFile(t, filepath.Join(dir, "second.pem"), second)
+	if err := d.Reload(); err != nil {
+		t.Fatalf("unexpected error reloading: %v", err)
+	}
+	if err := d.VerifySignature(bytes.NewReader(secondSig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying with added key: %v", err)
+	}
+
+	// concurrent reloads are serialized
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.Reload(); err != nil {
+				t.Errorf("unexpected error reloading concurrently: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := d.VerifySignature(bytes.NewReader(secondSig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying after concurrent reloads: %v", err)
+	}
+
+	// a failed reload keeps the previous key set
+	if err := os.Remove(filepath.Join(dir, "first.pem")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "second.pem")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Reload(); err == nil {
+		t.Fatal("expected error reloading empty directory")
+	}
+	if d.LastError() == nil {
+		t.Error("expected LastError to report failed reload")
+	}
+	if err := d.VerifySignature(bytes.NewReader(firstSig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying after failed reload: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// KeyResolver returns the public key with the given id, e.g. by fetching it from a key registry
+type KeyResolver func(keyID string) (crypto.PublicKey, error)
+
+// KeyResolutionError is returned by ResolvingVerifier when the key for a signature could not be
+// obtained, as opposed to the signature failing to verify with it
+type KeyResolutionError struct {
+	KeyID string
+	Err   error
+}
+
+func (e *KeyResolutionError) Error() string {
+	return fmt.Sprintf("resolving key %q: %v", e.KeyID, e.Err)
+}
+
+func (e *KeyResolutionError) Unwrap() error {
+	return e.Err
+}
+
+// ResolvingVerifier is a signature.Verifier that fetches the key for each signature on demand by
+// its key id, so that a large or changing set of keys does not have to be loaded up front.
+// Successfully resolved keys are cached for the lifetime of the ResolvingVerifier; failures are
+// not cached, so a key that is not yet available can be resolved later.
+type ResolvingVerifier struct {
+	resolve  KeyResolver
+	loadOpts []LoadOption
+
+	mu        sync.Mutex
+	verifiers map[string]Verifier
+}
+
+// LoadResolvingVerifier returns a ResolvingVerifier that obtains keys with resolve. The options
+// are passed to LoadVerifierWithOpts for each resolved key.
+func LoadResolvingVerifier(resolve KeyResolver, opts ...LoadOption) (*ResolvingVerifier, error) {
+	if resolve == nil {
+		return nil, errors.New("key resolver must be provided")
+	}
+	return &ResolvingVerifier{
+		resolve:   resolve,
+		loadOpts:  opts,
+		verifiers: map[string]Verifier{},
+	}, nil
+}
+
+// PublicKey is not supported, as the key depends on the signature being verified; use VerifierFor
+func (r *ResolvingVerifier) PublicKey(_ ...PublicKeyOption) (crypto.PublicKey, error) {
+	return nil, errors.New("not supported for resolving verifiers")
+}
+
+// VerifySignature verifies the signature for the given message with the key whose id is given
+// with options.WithKeyID, resolving it if it is not cached. If the key cannot be resolved or
+// loaded, a *KeyResolutionError is returned.
+func (r *ResolvingVerifier) VerifySignature(signature, message io.Reader, opts ...VerifyOption) error {
+	var keyID string
+	for _, opt := range opts {
+		opt.ApplyKeyID(&keyID)
+	}
+	if keyID == "" {
+		return errors.New("a key id must be given with options.WithKeyID")
+	}
+	v, err := r.VerifierFor(keyID)
+	if err != nil {
+		return err
+	}
+	return v.VerifySignature(signature, message, opts...)
+}
+
+// VerifierFor returns the Verifier for the key with the given id, resolving and caching it if
+// necessary. If the key cannot be resolved or loaded, a *KeyResolutionError is returned.
+func (r *ResolvingVerifier) VerifierFor(keyID string) (Verifier, error) {
+	r.mu.Lock()
+	v, ok := r.verifiers[keyID]
+	r.mu.Unlock()
+	if ok {
+		return v, nil
+	}
+
+	// the resolver may be slow, so it is called without holding the lock; concurrent misses for
+	// the same key may each resolve it, and the first result to be stored wins
+	pub, err := r.resolve(keyID)
+	if err != nil {
+		return nil, &KeyResolutionError{KeyID: keyID, Err: err}
+	}
+	if pub == nil {
+		return nil, &KeyResolutionError{KeyID: keyID, Err: errors.New("resolver returned no key")}
+	}
+	v, err = LoadVerifierWithOpts(pub, r.loadOpts...)
+	if err != nil {
+		return nil, &KeyResolutionError{KeyID: keyID, Err: err}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cached, ok := r.verifiers[keyID]; ok {
+		return cached, nil
+	}
+	r.verifiers[keyID] = v
+	return v, nil
+}
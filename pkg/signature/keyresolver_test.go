@@ -0,0 +1,99 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestResolvingVerifier(t *testing.T) {
+	message := []byte("sign me")
+	registry := map[string]SignerVerifier{}
+	sigs := map[string][]byte{}
+	for _, kid := range []string{"key-1", "key-2"} {
+		sv, _, err := NewDefaultECDSASignerVerifier()
+		if err != nil {
+			t.Fatalf("unexpected error creating signer/verifier: %v", err)
+		}
+		registry[kid] = sv
+		sigs[kid], _ = sv.SignMessage(bytes.NewReader(message))
+	}
+
+	calls := map[string]int{}
+	errUnavailable := errors.New("registry unavailable")
+	resolve := func(keyID string) (crypto.PublicKey, error) {
+		calls[keyID]++
+		if keyID == "flaky" && calls[keyID] == 1 {
+			return nil, errUnavailable
+		}
+		if keyID == "flaky" {
+			keyID = "key-1"
+		}
+		sv, ok := registry[keyID]
+		if !ok {
+			return nil, errors.New("no such key")
+		}
+		return sv.PublicKey()
+	}
+	v, err := LoadResolvingVerifier(resolve)
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+
+	for kid, sig := range sigs {
+		for i := 0; i < 2; i++ {
+			if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithKeyID(kid)); err != nil {
+				t.Errorf("%s: unexpected error verifying: %v", kid, err)
+			}
+		}
+		if calls[kid] != 1 {
+			t.Errorf("%s: expected the key to be resolved once, got %d", kid, calls[kid])
+		}
+	}
+
+	// a signature by a different key is a verification failure, not a resolution failure
+	err = v.VerifySignature(bytes.NewReader(sigs["key-2"]), bytes.NewReader(message), options.WithKeyID("key-1"))
+	var resErr *KeyResolutionError
+	if err == nil || errors.As(err, &resErr) {
+		t.Errorf("expected a verification error, got %v", err)
+	}
+
+	err = v.VerifySignature(bytes.NewReader(sigs["key-1"]), bytes.NewReader(message), options.WithKeyID("unknown"))
+	if !errors.As(err, &resErr) || resErr.KeyID != "unknown" {
+		t.Errorf("expected *KeyResolutionError for unknown key, got %v", err)
+	}
+
+	// resolution failures are not cached
+	err = v.VerifySignature(bytes.NewReader(sigs["key-1"]), bytes.NewReader(message), options.WithKeyID("flaky"))
+	if !errors.Is(err, errUnavailable) || !errors.As(err, &resErr) {
+		t.Errorf("expected *KeyResolutionError wrapping the resolver error, got %v", err)
+	}
+	if err := v.VerifySignature(bytes.NewReader(sigs["key-1"]), bytes.NewReader(message), options.WithKeyID("flaky")); err != nil {
+		t.Errorf("unexpected error after the resolver recovered: %v", err)
+	}
+
+	if err := v.VerifySignature(bytes.NewReader(sigs["key-1"]), bytes.NewReader(message)); err == nil {
+		t.Error("expected error without a key id")
+	}
+	if _, err := LoadResolvingVerifier(nil); err == nil {
+		t.Error("expected error for nil resolver")
+	}
+}
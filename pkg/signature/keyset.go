@@ -0,0 +1,156 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// KeySetVerifier is a signature.Verifier that accepts a signature if it verifies with any
+// one of a set of Verifiers, e.g. the keys of a JWKS or a key rotation set.
+type KeySetVerifier struct {
+	verifiers   []Verifier
+	concurrency int
+}
+
+// LoadKeySetVerifier returns a KeySetVerifier for the given verifiers. Up to concurrency
+// verifiers are tried in parallel; values less than 2 try them sequentially, in order.
+func LoadKeySetVerifier(verifiers []Verifier, concurrency int) (*KeySetVerifier, error) {
+	if len(verifiers) == 0 {
+		return nil, errors.New("key set must contain at least one verifier")
+	}
+	for _, v := range verifiers {
+		if v == nil {
+			return nil, errors.New("key set must not contain a nil verifier")
+		}
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &KeySetVerifier{
+		verifiers:   verifiers,
+		concurrency: concurrency,
+	}, nil
+}
+
+// PublicKey is not supported, as a key set does not have a single public key
+func (k *KeySetVerifier) PublicKey(_ ...PublicKeyOption) (crypto.PublicKey, error) {
+	return nil, errors.New("not supported for key sets")
+}
+
+// VerifySignature verifies the signature for the given message against each key in the set,
+// returning nil as soon as one succeeds. See MatchingVerifier for details.
+func (k *KeySetVerifier) VerifySignature(signature, message io.Reader, opts ...VerifyOption) error {
+	_, err := k.MatchingVerifier(signature, message, opts...)
+	return err
+}
+
+// MatchingVerifier verifies the signature for the given message against each key in the set
+// and returns the first Verifier that succeeds. Once a match is found, the context passed to
+// the remaining verifiers is cancelled and they are not started.
+//
+// The signature and message are read into memory so they can be verified more than once;
+// a limit given with options.WithMaxMessageSize is enforced while the message is buffered.
+// The context given with options.WithContext bounds the whole operation; all other options
+// are passed to each verifier.
+func (k *KeySetVerifier) MatchingVerifier(signature, message io.Reader, opts ...VerifyOption) (Verifier, error) {
+	if signature == nil {
+		return nil, errors.New("nil signature passed to VerifySignature")
+	}
+	if message == nil {
+		return nil, errors.New("message cannot be nil")
+	}
+	sigBytes, err := io.ReadAll(signature)
+	if err != nil {
+		return nil, fmt.Errorf("reading signature: %w", err)
+	}
+	ctx := context.Background()
+	var decompressor options.Decompressor
+	var maxSize int64
+	for _, opt := range opts {
+		opt.ApplyContext(&ctx)
+		opt.ApplyDecompressor(&decompressor)
+		opt.ApplyMaxMessageSize(&maxSize)
+	}
+	// with a decompressor the limit is enforced by each verifier on the decompressed content
+	if decompressor == nil && maxSize > 0 {
+		message = &maxSizeReader{r: message, limit: maxSize, remaining: maxSize}
+	}
+	msgBytes, err := io.ReadAll(message)
+	if err != nil {
+		return nil, fmt.Errorf("reading message: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	verifyOpts := append(append([]VerifyOption{}, opts...), options.WithContext(ctx))
+
+	type result struct {
+		verifier Verifier
+		err      error
+	}
+	jobs := make(chan Verifier)
+	// buffered so that workers never block once we have stopped reading
+	results := make(chan result, len(k.verifiers))
+
+	go func() {
+		defer close(jobs)
+		for _, v := range k.verifiers {
+			select {
+			case jobs <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(k.concurrency, len(k.verifiers)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range jobs {
+				results <- result{
+					verifier: v,
+					err:      v.VerifySignature(bytes.NewReader(sigBytes), bytes.NewReader(msgBytes), verifyOpts...),
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var errs []error
+	for r := range results {
+		if r.err == nil {
+			return r.verifier, nil
+		}
+		errs = append(errs, r.err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("signature did not verify with any key in the set: %w", errors.Join(errs...))
+}
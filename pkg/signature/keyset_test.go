@@ -0,0 +1,81 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestKeySetVerifier(t *testing.T) {
+	const numKeys = 64
+	verifiers := make([]Verifier, 0, numKeys)
+	var signer Signer
+	for i := 0; i < numKeys; i++ {
+		sv, _, err := NewDefaultECDSASignerVerifier()
+		if err != nil {
+			t.Fatalf("unexpected error creating signer/verifier: %v", err)
+		}
+		verifiers = append(verifiers, sv.ECDSAVerifier)
+		signer = sv
+	}
+
+	message := []byte("sign me")
+	// only the last key in the set matches
+	sig, err := signer.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error signing message: %v", err)
+	}
+
+	for _, concurrency := range []int{0, 1, 8, numKeys * 2} {
+		ks, err := LoadKeySetVerifier(verifiers, concurrency)
+		if err != nil {
+			t.Fatalf("unexpected error loading key set verifier: %v", err)
+		}
+		v, err := ks.MatchingVerifier(bytes.NewReader(sig), bytes.NewReader(message))
+		if err != nil {
+			t.Fatalf("concurrency %d: unexpected error verifying signature: %v", concurrency, err)
+		}
+		if v != verifiers[numKeys-1] {
+			t.Errorf("concurrency %d: expected the last verifier to match", concurrency)
+		}
+		if err := ks.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("tampered"))); err == nil {
+			t.Errorf("concurrency %d: expected error verifying tampered message", concurrency)
+		}
+	}
+
+	ks, err := LoadKeySetVerifier(verifiers[:numKeys-1], 8)
+	if err != nil {
+		t.Fatalf("unexpected error loading key set verifier: %v", err)
+	}
+	if err := ks.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err == nil {
+		t.Error("expected error when no key in the set matches")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ks.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if _, err := LoadKeySetVerifier(nil, 1); err == nil {
+		t.Error("expected error loading empty key set")
+	}
+}
@@ -15,7 +15,90 @@
 
 package aws
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/jellydator/ttlcache/v3"
+
+	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+type testKMSClient struct {
+	kmsClient
+	verifyInput *kms.VerifyInput
+	signInput   *kms.SignInput
+	keyMetadata *types.KeyMetadata
+	describeErr error
+	signErr     error
+	requestID   string
+}
+
+func (c *testKMSClient) Verify(_ context.Context, params *kms.VerifyInput, _ ...func(*kms.Options)) (*kms.VerifyOutput, error) {
+	c.verifyInput = params
+	return &kms.VerifyOutput{SignatureValid: true}, nil
+}
+
+func (c *testKMSClient) Sign(_ context.Context, params *kms.SignInput, _ ...func(*kms.Options)) (*kms.SignOutput, error) {
+	c.signInput = params
+	if c.signErr != nil {
+		return nil, c.signErr
+	}
+	out := &kms.SignOutput{Signature: []byte("signature")}
+	if c.requestID != "" {
+		awsmiddleware.SetRequestIDMetadata(&out.ResultMetadata, c.requestID)
+	}
+	return out, nil
+}
+
+func (c *testKMSClient) DescribeKey(_ context.Context, _ *kms.DescribeKeyInput, _ ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+	if c.describeErr != nil {
+		return nil, c.describeErr
+	}
+	return &kms.DescribeKeyOutput{KeyMetadata: c.keyMetadata}, nil
+}
+
+func newTestSignerVerifier(t *testing.T, client kmsClient) *SignerVerifier {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	keyCache := ttlcache.New[string, cmk](
+		ttlcache.WithDisableTouchOnHit[string, cmk](),
+	)
+	keyCache.Set(cacheKey, cmk{
+		KeyMetadata: &types.KeyMetadata{
+			SigningAlgorithms: []types.SigningAlgorithmSpec{types.SigningAlgorithmSpecEcdsaSha256},
+		},
+		PublicKey: priv.Public(),
+	}, ttlcache.NoTTL)
+
+	return &SignerVerifier{
+		client: &awsClient{
+			client:   client,
+			keyID:    "1234abcd-12ab-34cd-56ef-1234567890ab",
+			keyCache: keyCache,
+		},
+	}
+}
 
 func TestParseReference(t *testing.T) {
 	tests := []struct {
@@ -156,3 +239,269 @@ func TestParseReference(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifySignatureRemoteVerification(t *testing.T) {
+	client := &testKMSClient{}
+	sv := newTestSignerVerifier(t, client)
+
+	msg := []byte("message")
+	// this signature would never verify locally, so success means the backend was used
+	sig := []byte("not a valid signature")
+
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg), options.WithRemoteVerification(true)); err != nil {
+		t.Fatalf("unexpected error verifying remotely: %v", err)
+	}
+	if client.verifyInput == nil {
+		t.Fatal("expected verification to be delegated to AWS KMS")
+	}
+	digest := sha256.Sum256(msg)
+	if !bytes.Equal(client.verifyInput.Message, digest[:]) || client.verifyInput.MessageType != types.MessageTypeDigest {
+		t.Error("expected message digest to be sent to AWS KMS")
+	}
+	if !bytes.Equal(client.verifyInput.Signature, sig) {
+		t.Error("expected signature to be sent to AWS KMS")
+	}
+
+	client.verifyInput = nil
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg)); err == nil {
+		t.Fatal("expected local verification of an invalid signature to fail")
+	}
+	if client.verifyInput != nil {
+		t.Fatal("expected local verification not to call AWS KMS")
+	}
+}
+
+func TestSignMessageKeyStateCheck(t *testing.T) {
+	digest := sha256.Sum256([]byte("message"))
+	for _, tc := range []struct {
+		state   types.KeyState
+		wantErr bool
+	}{
+		{state: types.KeyStateEnabled},
+		{state: types.KeyStateDisabled, wantErr: true},
+		{state: types.KeyStatePendingDeletion, wantErr: true},
+	} {
+		t.Run(string(tc.state), func(t *testing.T) {
+			client := &testKMSClient{}
+			sv := newTestSignerVerifier(t, client)
+			sv.client.keyCache.Get(cacheKey).Value().KeyMetadata.KeyState = tc.state
+
+			_, err := sv.SignMessage(nil, options.WithDigest(digest[:]), options.WithKeyStateCheck(true))
+			if tc.wantErr {
+				if !errors.Is(err, sigkms.ErrKeyNotUsable) {
+					t.Errorf("expected ErrKeyNotUsable, got %v", err)
+				}
+				if client.signInput != nil {
+					t.Error("expected Sign not to be called for an unusable key")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			if client.signInput == nil {
+				t.Error("expected Sign to be called")
+			}
+		})
+	}
+
+	// without the option the key state is not consulted
+	client := &testKMSClient{}
+	sv := newTestSignerVerifier(t, client)
+	sv.client.keyCache.Get(cacheKey).Value().KeyMetadata.KeyState = types.KeyStateDisabled
+	if _, err := sv.SignMessage(nil, options.WithDigest(digest[:])); err != nil {
+		t.Errorf("unexpected error signing without key state check: %v", err)
+	}
+}
+
+func TestGetKeyMetadata(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	client := &testKMSClient{keyMetadata: &types.KeyMetadata{
+		CreationDate: &created,
+		KeyState:     types.KeyStatePendingDeletion,
+		KeySpec:      types.KeySpecEccNistP256,
+	}}
+	sv := newTestSignerVerifier(t, client)
+
+	md, err := sv.GetKeyMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error getting key metadata: %v", err)
+	}
+	if !md.CreatedAt.Equal(created) {
+		t.Errorf("expected creation time %v, got %v", created, md.CreatedAt)
+	}
+	if md.State != string(types.KeyStatePendingDeletion) {
+		t.Errorf("expected state %s, got %s", types.KeyStatePendingDeletion, md.State)
+	}
+	if md.Algorithm != string(types.KeySpecEccNistP256) {
+		t.Errorf("expected algorithm %s, got %s", types.KeySpecEccNistP256, md.Algorithm)
+	}
+}
+
+func TestKeyMetadata(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	arn := "arn:aws:kms:us-east-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	keyID := "1234abcd-12ab-34cd-56ef-1234567890ab"
+	keyStoreID := "cks-1234567890abcdef0"
+	clusterID := "cluster-1a23b4cdefg"
+	client := &testKMSClient{keyMetadata: &types.KeyMetadata{
+		Arn:               &arn,
+		KeyId:             &keyID,
+		CreationDate:      &created,
+		Origin:            types.OriginTypeAwsCloudhsm,
+		KeySpec:           types.KeySpecEccNistP256,
+		KeyUsage:          types.KeyUsageTypeSignVerify,
+		CustomKeyStoreId:  &keyStoreID,
+		CloudHsmClusterId: &clusterID,
+	}}
+	sv := newTestSignerVerifier(t, client)
+
+	md, err := sv.KeyMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error getting key metadata: %v", err)
+	}
+	want := KeyMetadata{
+		ARN:               arn,
+		KeyID:             keyID,
+		Origin:            string(types.OriginTypeAwsCloudhsm),
+		KeySpec:           string(types.KeySpecEccNistP256),
+		CustomKeyStoreID:  keyStoreID,
+		CloudHSMClusterID: clusterID,
+		CreationDate:      created,
+	}
+	if *md != want {
+		t.Errorf("expected key metadata %+v, got %+v", want, *md)
+	}
+
+	sv = newTestSignerVerifier(t, &testKMSClient{keyMetadata: &types.KeyMetadata{
+		Arn:      &arn,
+		Origin:   types.OriginTypeAwsKms,
+		KeySpec:  types.KeySpecSymmetricDefault,
+		KeyUsage: types.KeyUsageTypeEncryptDecrypt,
+	}})
+	if _, err := sv.KeyMetadata(context.Background()); !errors.Is(err, ErrKeyNotAsymmetric) {
+		t.Errorf("expected ErrKeyNotAsymmetric for a symmetric key, got %v", err)
+	}
+
+	sv = newTestSignerVerifier(t, &testKMSClient{describeErr: &types.NotFoundException{}})
+	if _, err := sv.KeyMetadata(context.Background()); err == nil {
+		t.Error("expected error when the key cannot be described")
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	sv := newTestSignerVerifier(t, &testKMSClient{keyMetadata: &types.KeyMetadata{}})
+	if err := sv.Preflight(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	denied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}
+	sv = newTestSignerVerifier(t, &testKMSClient{describeErr: denied})
+	err := sv.Preflight(context.Background())
+	var authErr *sigkms.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("expected *AuthError, got %v", err)
+	}
+	if !errors.Is(err, denied) {
+		t.Errorf("expected AuthError to wrap the underlying error, got %v", err)
+	}
+
+	sv = newTestSignerVerifier(t, &testKMSClient{describeErr: &types.NotFoundException{}})
+	err = sv.Preflight(context.Background())
+	if err == nil || errors.As(err, &authErr) {
+		t.Errorf("expected non-auth error for missing key, got %v", err)
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want sigkms.ErrorKind
+	}{
+		{"throttling", &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}, sigkms.ErrorKindThrottled},
+		{"limit exceeded", &types.LimitExceededException{Message: aws.String("quota exceeded")}, sigkms.ErrorKindThrottled},
+		{"access denied", &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}, sigkms.ErrorKindAuth},
+		{"expired token", &smithy.GenericAPIError{Code: "ExpiredTokenException", Message: "token expired"}, sigkms.ErrorKindAuth},
+		{"not found", &types.NotFoundException{Message: aws.String("key does not exist")}, sigkms.ErrorKindNotFound},
+		{"internal", &types.KMSInternalException{Message: aws.String("internal error")}, sigkms.ErrorKindTransient},
+		{"dependency timeout", &types.DependencyTimeoutException{Message: aws.String("timed out")}, sigkms.ErrorKindTransient},
+		{"disabled key", &types.DisabledException{Message: aws.String("key is disabled")}, sigkms.ErrorKindPermanent},
+		{"deadline", context.DeadlineExceeded, sigkms.ErrorKindTransient},
+		{"unknown", errors.New("unexpected"), sigkms.ErrorKindPermanent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sv := newTestSignerVerifier(t, &testKMSClient{describeErr: tt.err})
+			_, err := sv.GetKeyMetadata(context.Background())
+			var ce *sigkms.ClassifiedError
+			if !errors.As(err, &ce) {
+				t.Fatalf("expected *ClassifiedError, got %v", err)
+			}
+			if ce.Kind != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, ce.Kind)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected classified error to wrap %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestSignMessageRequestID(t *testing.T) {
+	digest := sha256.Sum256([]byte("message"))
+	sv := newTestSignerVerifier(t, &testKMSClient{requestID: "b3a7e0c1-0000-4000-8000-000000000001"})
+
+	var requestID string
+	if _, err := sv.SignMessage(nil, options.WithDigest(digest[:]), options.ReturnBackendRequestID(&requestID)); err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	if requestID != "b3a7e0c1-0000-4000-8000-000000000001" {
+		t.Errorf("expected request ID to be returned, got %q", requestID)
+	}
+
+	apiErr := &types.KMSInvalidStateException{Message: aws.String("key is pending deletion")}
+	sv = newTestSignerVerifier(t, &testKMSClient{signErr: &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusBadRequest}},
+			Err:      apiErr,
+		},
+		RequestID: "b3a7e0c1-0000-4000-8000-000000000002",
+	}})
+	_, err := sv.SignMessage(nil, options.WithDigest(digest[:]))
+	var idErr *sigkms.RequestIDError
+	if !errors.As(err, &idErr) {
+		t.Fatalf("expected *RequestIDError, got %v", err)
+	}
+	if idErr.RequestID != "b3a7e0c1-0000-4000-8000-000000000002" {
+		t.Errorf("unexpected request ID %q", idErr.RequestID)
+	}
+	if !errors.Is(err, apiErr) {
+		t.Errorf("expected error to wrap the AWS error, got %v", err)
+	}
+}
+
+func TestCreateKeyDryRun(t *testing.T) {
+	sv := newTestSignerVerifier(t, &testKMSClient{})
+
+	_, err := sv.CreateKey(context.Background(), string(types.CustomerMasterKeySpecEccNistP256), options.WithDryRun(true))
+	if !errors.Is(err, sigkms.ErrDryRunUnsupported) {
+		t.Fatalf("expected ErrDryRunUnsupported, got %v", err)
+	}
+}
+
+func TestSignMessageVerifyAfterSign(t *testing.T) {
+	// the test client returns a signature that does not verify against the cached public key
+	client := &testKMSClient{}
+	sv := newTestSignerVerifier(t, client)
+	digest := sha256.Sum256([]byte("hello"))
+
+	if _, err := sv.SignMessage(nil, options.WithDigest(digest[:])); err != nil {
+		t.Fatalf("unexpected error signing without verification: %v", err)
+	}
+
+	_, err := sv.SignMessage(nil, options.WithDigest(digest[:]), options.WithVerifyAfterSign(true))
+	if !errors.Is(err, sigkms.ErrSignatureVerificationFailed) {
+		t.Fatalf("expected ErrSignatureVerificationFailed, got %v", err)
+	}
+}
@@ -29,20 +29,33 @@ import (
 	"net/http"
 	"os"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/smithy-go"
 	"github.com/jellydator/ttlcache/v3"
 	"github.com/sigstore/sigstore/pkg/signature"
 	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
 )
 
 func init() {
-	sigkms.AddProvider(ReferenceScheme, func(ctx context.Context, keyResourceID string, _ crypto.Hash, _ ...signature.RPCOption) (sigkms.SignerVerifier, error) {
-		return LoadSignerVerifier(ctx, keyResourceID)
+	sigkms.AddProvider(ReferenceScheme, func(ctx context.Context, keyResourceID string, _ crypto.Hash, opts ...signature.RPCOption) (sigkms.SignerVerifier, error) {
+		algorithm, err := sigkms.DefaultAlgorithmOption((&SignerVerifier{}).SupportedAlgorithms(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		sv, err := LoadSignerVerifier(ctx, keyResourceID)
+		if err != nil {
+			return nil, err
+		}
+		sv.defaultAlgorithm = algorithm
+		return sv, nil
 	})
 }
 
@@ -52,8 +65,18 @@ const (
 	ReferenceScheme = "awskms://"
 )
 
+// kmsClient is the subset of the AWS KMS client used by awsClient
+type kmsClient interface {
+	CreateAlias(ctx context.Context, params *kms.CreateAliasInput, optFns ...func(*kms.Options)) (*kms.CreateAliasOutput, error)
+	CreateKey(ctx context.Context, params *kms.CreateKeyInput, optFns ...func(*kms.Options)) (*kms.CreateKeyOutput, error)
+	DescribeKey(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error)
+	GetPublicKey(ctx context.Context, params *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error)
+	Sign(ctx context.Context, params *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error)
+	Verify(ctx context.Context, params *kms.VerifyInput, optFns ...func(*kms.Options)) (*kms.VerifyOutput, error)
+}
+
 type awsClient struct {
-	client   *kms.Client
+	client   kmsClient
 	endpoint string
 	keyID    string
 	alias    string
@@ -245,11 +268,122 @@ func (a *awsClient) getCMK(ctx context.Context) (*cmk, error) {
 	return nil, lerr
 }
 
-func (a *awsClient) createKey(ctx context.Context, algorithm string) (crypto.PublicKey, error) {
+// checkKeyState returns sigkms.ErrKeyNotUsable if the key is not enabled. The key metadata
+// is served from the cache, so this does not call DescribeKey on every use.
+func (a *awsClient) checkKeyState(ctx context.Context) error {
+	cmk, err := a.getCMK(ctx)
+	if err != nil {
+		return err
+	}
+	if state := cmk.KeyMetadata.KeyState; state != types.KeyStateEnabled {
+		return fmt.Errorf("%w: key state is %s", sigkms.ErrKeyNotUsable, state)
+	}
+	return nil
+}
+
+func (a *awsClient) keyMetadata(ctx context.Context) (*sigkms.KeyMetadata, error) {
+	km, err := a.fetchKeyMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	md := &sigkms.KeyMetadata{
+		State:     string(km.KeyState),
+		Algorithm: string(km.KeySpec),
+	}
+	if km.CreationDate != nil {
+		md.CreatedAt = *km.CreationDate
+	}
+	return md, nil
+}
+
+// ErrKeyNotAsymmetric is returned by SignerVerifier.KeyMetadata for keys that cannot sign,
+// e.g. symmetric encryption or HMAC keys
+var ErrKeyNotAsymmetric = errors.New("key is not an asymmetric signing key")
+
+// KeyMetadata describes an AWS KMS signing key as reported by DescribeKey, e.g. for compliance
+// audits of where the key material is held. Fields that AWS does not report for the key are
+// left empty.
+type KeyMetadata struct {
+	// ARN is the Amazon Resource Name of the key
+	ARN string
+	// KeyID is the unique identifier of the key
+	KeyID string
+	// Origin is the source of the key material: AWS_KMS, EXTERNAL (imported key material),
+	// AWS_CLOUDHSM or EXTERNAL_KEY_STORE
+	Origin string
+	// KeySpec is the type of the key, e.g. ECC_NIST_P256 or RSA_2048
+	KeySpec string
+	// CustomKeyStoreID is the ID of the custom key store holding the key, if any
+	CustomKeyStoreID string
+	// CloudHSMClusterID is the ID of the AWS CloudHSM cluster holding the key, if any
+	CloudHSMClusterID string
+	// CreationDate is when the key was created
+	CreationDate time.Time
+}
+
+func (a *awsClient) awsKeyMetadata(ctx context.Context) (*KeyMetadata, error) {
+	km, err := a.fetchKeyMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if km == nil {
+		return nil, errors.New("no key metadata returned")
+	}
+	if km.KeyUsage != types.KeyUsageTypeSignVerify {
+		return nil, fmt.Errorf("%w: key usage is %s and key spec is %s", ErrKeyNotAsymmetric, km.KeyUsage, km.KeySpec)
+	}
+	md := &KeyMetadata{
+		Origin:  string(km.Origin),
+		KeySpec: string(km.KeySpec),
+	}
+	if km.Arn != nil {
+		md.ARN = *km.Arn
+	}
+	if km.KeyId != nil {
+		md.KeyID = *km.KeyId
+	}
+	if km.CustomKeyStoreId != nil {
+		md.CustomKeyStoreID = *km.CustomKeyStoreId
+	}
+	if km.CloudHsmClusterId != nil {
+		md.CloudHSMClusterID = *km.CloudHsmClusterId
+	}
+	if km.CreationDate != nil {
+		md.CreationDate = *km.CreationDate
+	}
+	return md, nil
+}
+
+// KeyParameterNamespace is the namespace of CreateKey parameters recognized by AWS KMS
+const KeyParameterNamespace = "aws"
+
+func (a *awsClient) createKey(ctx context.Context, algorithm string, params map[string]string) (crypto.PublicKey, error) {
 	if a.alias == "" {
 		return nil, errors.New("must use alias key format")
 	}
 
+	usage := types.KeyUsageTypeSignVerify
+	description := "Created by Sigstore"
+	input := &kms.CreateKeyInput{
+		CustomerMasterKeySpec: types.CustomerMasterKeySpec(algorithm),
+		KeyUsage:              usage,
+		Description:           &description,
+	}
+	for name, value := range params {
+		switch name {
+		case "policy":
+			input.Policy = aws.String(value)
+		case "multiRegion":
+			multiRegion, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s.%s: %w", KeyParameterNamespace, name, err)
+			}
+			input.MultiRegion = aws.Bool(multiRegion)
+		default:
+			return nil, fmt.Errorf("unknown key parameter %s.%s", KeyParameterNamespace, name)
+		}
+	}
+
 	// look for existing key first
 	cmk, err := a.getCMK(ctx)
 	if err == nil {
@@ -263,15 +397,9 @@ func (a *awsClient) createKey(ctx context.Context, algorithm string) (crypto.Pub
 		return nil, fmt.Errorf("looking up key: %w", err)
 	}
 
-	usage := types.KeyUsageTypeSignVerify
-	description := "Created by Sigstore"
-	key, err := a.client.CreateKey(ctx, &kms.CreateKeyInput{
-		CustomerMasterKeySpec: types.CustomerMasterKeySpec(algorithm),
-		KeyUsage:              usage,
-		Description:           &description,
-	})
+	key, err := a.client.CreateKey(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("creating key: %w", err)
+		return nil, fmt.Errorf("creating key: %w", classifyError(err))
 	}
 
 	_, err = a.client.CreateAlias(ctx, &kms.CreateAliasInput{
@@ -279,7 +407,7 @@ func (a *awsClient) createKey(ctx context.Context, algorithm string) (crypto.Pub
 		TargetKeyId: key.KeyMetadata.KeyId,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("creating alias %q: %w", a.alias, err)
+		return nil, fmt.Errorf("creating alias %q: %w", a.alias, classifyError(err))
 	}
 
 	cmk, err = a.getCMK(ctx)
@@ -316,12 +444,13 @@ func (a *awsClient) verifyRemotely(ctx context.Context, sig, digest []byte) erro
 		Signature:        sig,
 		SigningAlgorithm: alg,
 	}); err != nil {
-		return fmt.Errorf("unable to verify signature: %w", err)
+		return fmt.Errorf("unable to verify signature: %w", classifyError(err))
 	}
 	return nil
 }
 
-func (a *awsClient) sign(ctx context.Context, digest []byte, _ crypto.Hash) ([]byte, error) {
+// sign signs digest with the key, storing the AWS request ID in requestID if it is not nil
+func (a *awsClient) sign(ctx context.Context, digest []byte, _ crypto.Hash, requestID *string) ([]byte, error) {
 	cmk, err := a.getCMK(ctx)
 	if err != nil {
 		return nil, err
@@ -336,7 +465,10 @@ func (a *awsClient) sign(ctx context.Context, digest []byte, _ crypto.Hash) ([]b
 		SigningAlgorithm: alg,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("signing with kms: %w", err)
+		return nil, fmt.Errorf("signing with kms: %w", withRequestID(classifyError(err)))
+	}
+	if id, ok := awsmiddleware.GetRequestIDMetadata(out.ResultMetadata); ok && requestID != nil {
+		*requestID = id
 	}
 	return out.Signature, nil
 }
@@ -346,7 +478,7 @@ func (a *awsClient) fetchPublicKey(ctx context.Context) (crypto.PublicKey, error
 		KeyId: &a.keyID,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("getting public key: %w", err)
+		return nil, fmt.Errorf("getting public key: %w", classifyError(err))
 	}
 	key, err := x509.ParsePKIXPublicKey(out.PublicKey)
 	if err != nil {
@@ -360,7 +492,81 @@ func (a *awsClient) fetchKeyMetadata(ctx context.Context) (*types.KeyMetadata, e
 		KeyId: &a.keyID,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("getting key metadata: %w", err)
+		return nil, fmt.Errorf("getting key metadata: %w", classifyError(err))
 	}
 	return out.KeyMetadata, nil
 }
+
+// authErrorCodes are the AWS error codes returned when credentials are invalid or lack
+// permission to use a key
+var authErrorCodes = map[string]struct{}{
+	"AccessDeniedException":       {},
+	"UnrecognizedClientException": {},
+	"InvalidSignatureException":   {},
+	"ExpiredTokenException":       {},
+	"IncompleteSignature":         {},
+	"MissingAuthenticationToken":  {},
+}
+
+// throttlingErrorCodes are the AWS error codes returned when a request exceeds a rate limit or quota
+var throttlingErrorCodes = map[string]struct{}{
+	"ThrottlingException":      {},
+	"TooManyRequestsException": {},
+	"RequestLimitExceeded":     {},
+	"LimitExceededException":   {},
+}
+
+// transientErrorCodes are the AWS error codes returned for temporary service-side failures
+var transientErrorCodes = map[string]struct{}{
+	"KMSInternalException":        {},
+	"DependencyTimeoutException":  {},
+	"InternalFailure":             {},
+	"ServiceUnavailable":          {},
+	"ServiceUnavailableException": {},
+}
+
+// isAuthError returns true if err was caused by AWS rejecting the credentials or denying access to the key
+func isAuthError(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	_, ok := authErrorCodes[apiErr.ErrorCode()]
+	return ok
+}
+
+// errorKind maps an AWS API error to a sigkms.ErrorKind
+func errorKind(err error) sigkms.ErrorKind {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return sigkms.ErrorKindPermanent
+	}
+	code := apiErr.ErrorCode()
+	if _, ok := throttlingErrorCodes[code]; ok {
+		return sigkms.ErrorKindThrottled
+	}
+	if _, ok := authErrorCodes[code]; ok {
+		return sigkms.ErrorKindAuth
+	}
+	if _, ok := transientErrorCodes[code]; ok {
+		return sigkms.ErrorKindTransient
+	}
+	if code == "NotFoundException" {
+		return sigkms.ErrorKindNotFound
+	}
+	return sigkms.ErrorKindPermanent
+}
+
+// classifyError wraps err returned by the AWS KMS API in a *sigkms.ClassifiedError
+func classifyError(err error) error {
+	return sigkms.Classify(err, errorKind)
+}
+
+// withRequestID wraps err in a *sigkms.RequestIDError if AWS reported a request ID for the failed request
+func withRequestID(err error) error {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) && respErr.ServiceRequestID() != "" {
+		return &sigkms.RequestIDError{RequestID: respErr.ServiceRequestID(), Err: err}
+	}
+	return err
+}
@@ -16,6 +16,7 @@
 package aws
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"fmt"
@@ -24,6 +25,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/kms/types"
 	"github.com/sigstore/sigstore/pkg/signature"
+	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
 	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
@@ -45,6 +47,8 @@ var awsSupportedHashFuncs = []crypto.Hash{
 // SignerVerifier is a signature.SignerVerifier that uses the AWS Key Management Service
 type SignerVerifier struct {
 	client *awsClient
+	// defaultAlgorithm overrides the default algorithm if set by options.WithDefaultAlgorithm
+	defaultAlgorithm string
 }
 
 // LoadSignerVerifier generates signatures using the specified key object in AWS KMS and hash algorithm.
@@ -74,15 +78,33 @@ func LoadSignerVerifier(ctx context.Context, referenceStr string, opts ...func(*
 //
 // - WithCryptoSignerOpts()
 //
-// All other options are ignored if specified.
+// - WithKeyStateCheck()
+//
+// - WithVerifyAfterSign()
+//
+// - ReturnBackendRequestID()
+//
+// All other options are ignored if specified. If the AWS KMS request fails, the error includes
+// its request ID as a *sigkms.RequestIDError.
 func (a *SignerVerifier) SignMessage(message io.Reader, opts ...signature.SignOption) ([]byte, error) {
 	var digest []byte
 	var err error
+	var checkKeyState, verifyAfterSign bool
+	var requestID *string
 	ctx := context.Background()
 
 	for _, opt := range opts {
 		opt.ApplyContext(&ctx)
 		opt.ApplyDigest(&digest)
+		opt.ApplyBackendRequestID(&requestID)
+		opt.ApplyKeyStateCheck(&checkKeyState)
+		opt.ApplyVerifyAfterSign(&verifyAfterSign)
+	}
+
+	if checkKeyState {
+		if err := a.client.checkKeyState(ctx); err != nil {
+			return nil, err
+		}
 	}
 
 	var signerOpts crypto.SignerOpts
@@ -103,7 +125,16 @@ func (a *SignerVerifier) SignMessage(message io.Reader, opts ...signature.SignOp
 		}
 	}
 
-	return a.client.sign(ctx, digest, hf)
+	sig, err := a.client.sign(ctx, digest, hf, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if verifyAfterSign {
+		if err := a.client.verify(ctx, bytes.NewReader(sig), nil, options.WithDigest(digest), options.WithCryptoSignerOpts(hf)); err != nil {
+			return nil, fmt.Errorf("%w: %w", sigkms.ErrSignatureVerificationFailed, err)
+		}
+	}
+	return sig, nil
 }
 
 // PublicKey returns the public key that can be used to verify signatures created by
@@ -181,8 +212,29 @@ func (a *SignerVerifier) VerifySignature(sig, message io.Reader, opts ...signatu
 }
 
 // CreateKey attempts to create a new key in Vault with the specified algorithm.
-func (a *SignerVerifier) CreateKey(ctx context.Context, algorithm string) (crypto.PublicKey, error) {
-	return a.client.createKey(ctx, algorithm)
+//
+// AWS KMS has no validate-only key creation call, so WithDryRun(true) returns
+// kms.ErrDryRunUnsupported without creating anything.
+//
+// The following parameters in the "aws" namespace are recognized from WithKeyParameters():
+//
+// - aws.policy: the JSON key policy to attach to the key
+//
+// - aws.multiRegion: "true" to create a multi-Region primary key
+func (a *SignerVerifier) CreateKey(ctx context.Context, algorithm string, opts ...signature.CreateKeyOption) (crypto.PublicKey, error) {
+	var dryRun bool
+	var params map[string]string
+	for _, opt := range opts {
+		opt.ApplyDryRun(&dryRun)
+		opt.ApplyKeyParameters(&params)
+	}
+	if dryRun {
+		return nil, sigkms.ErrDryRunUnsupported
+	}
+	if algorithm == "" {
+		algorithm = a.defaultAlgorithm
+	}
+	return a.client.createKey(ctx, algorithm, sigkms.KeyParameters(KeyParameterNamespace, params))
 }
 
 type cryptoSignerWrapper struct {
@@ -241,7 +293,41 @@ func (*SignerVerifier) SupportedAlgorithms() []string {
 	return s
 }
 
-// DefaultAlgorithm returns the default algorithm for the AWS KMS service
-func (*SignerVerifier) DefaultAlgorithm() string {
+// DefaultAlgorithm returns the default algorithm for the AWS KMS service, or the algorithm
+// given by options.WithDefaultAlgorithm when loaded through kms.Get
+func (a *SignerVerifier) DefaultAlgorithm() string {
+	if a.defaultAlgorithm != "" {
+		return a.defaultAlgorithm
+	}
 	return string(types.CustomerMasterKeySpecEccNistP256)
 }
+
+// LatencyClass reports that operations are network-bound calls to the AWS KMS service
+func (*SignerVerifier) LatencyClass() signature.LatencyClass {
+	return signature.LatencyClassRemote
+}
+
+// GetKeyMetadata returns the creation time, state and key spec of the key from AWS KMS.
+// Tags are not retrieved, so Labels is always empty.
+func (a *SignerVerifier) GetKeyMetadata(ctx context.Context) (*sigkms.KeyMetadata, error) {
+	return a.client.keyMetadata(ctx)
+}
+
+// KeyMetadata describes the key in AWS KMS, including the origin of its key material, so that
+// policy tools can check e.g. that the key is held in an AWS CloudHSM cluster. Unlike
+// GetKeyMetadata, it returns an error wrapping ErrKeyNotAsymmetric if the key cannot sign.
+func (a *SignerVerifier) KeyMetadata(ctx context.Context) (*KeyMetadata, error) {
+	return a.client.awsKeyMetadata(ctx)
+}
+
+// Preflight describes the key in AWS KMS, returning a *sigkms.AuthError if the credentials
+// are rejected or access to the key is denied.
+func (a *SignerVerifier) Preflight(ctx context.Context) error {
+	if _, err := a.client.fetchKeyMetadata(ctx); err != nil {
+		if isAuthError(err) {
+			return &sigkms.AuthError{Err: err}
+		}
+		return err
+	}
+	return nil
+}
@@ -28,6 +28,7 @@ import (
 	"net/http"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -47,7 +48,16 @@ import (
 
 func init() {
 	sigkms.AddProvider(ReferenceScheme, func(ctx context.Context, keyResourceID string, _ crypto.Hash, opts ...signature.RPCOption) (sigkms.SignerVerifier, error) {
-		return LoadSignerVerifier(ctx, keyResourceID)
+		algorithm, err := sigkms.DefaultAlgorithmOption(azureSupportedAlgorithms, opts...)
+		if err != nil {
+			return nil, err
+		}
+		sv, err := LoadSignerVerifier(ctx, keyResourceID)
+		if err != nil {
+			return nil, err
+		}
+		sv.defaultAlgorithm = algorithm
+		return sv, nil
 	})
 }
 
@@ -69,6 +79,10 @@ type azureVaultClient struct {
 var (
 	errAzureReference = errors.New("kms specification should be in the format azurekms://[VAULT_NAME][VAULT_URL]/[KEY_NAME]/[VERSION (optional)]")
 
+	// ErrKeyDiscovery is returned (wrapped) when the type and signing algorithm of the key
+	// could not be discovered from Azure Key Vault, as distinct from a failed signing operation.
+	ErrKeyDiscovery = errors.New("discovering key algorithm from Azure Key Vault")
+
 	referenceRegex = regexp.MustCompile(`^azurekms://([^/]+)/([^/]+)(/[a-z0-9]*)?$`)
 )
 
@@ -293,12 +307,45 @@ func (a *azureVaultClient) fetchPublicKey(ctx context.Context) (crypto.PublicKey
 func (a *azureVaultClient) getKey(ctx context.Context) (azkeys.KeyBundle, error) {
 	resp, err := a.client.GetKey(ctx, a.keyName, a.keyVersion, nil)
 	if err != nil {
-		return azkeys.KeyBundle{}, fmt.Errorf("public key: %w", err)
+		return azkeys.KeyBundle{}, fmt.Errorf("public key: %w", classifyError(err))
 	}
 
 	return resp.KeyBundle, err
 }
 
+func (a *azureVaultClient) keyMetadata(ctx context.Context) (*sigkms.KeyMetadata, error) {
+	keyBundle, err := a.getKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	md := &sigkms.KeyMetadata{}
+	if attrs := keyBundle.Attributes; attrs != nil {
+		if attrs.Created != nil {
+			md.CreatedAt = *attrs.Created
+		}
+		if attrs.Enabled != nil {
+			md.State = "Disabled"
+			if *attrs.Enabled {
+				md.State = "Enabled"
+			}
+		}
+	}
+	for k, v := range keyBundle.Tags {
+		if v == nil {
+			continue
+		}
+		if md.Labels == nil {
+			md.Labels = make(map[string]string, len(keyBundle.Tags))
+		}
+		md.Labels[k] = *v
+	}
+	if _, alg, err := a.getKeyVaultHashFunc(ctx); err == nil {
+		md.Algorithm = string(alg)
+	}
+	return md, nil
+}
+
 func (a *azureVaultClient) public(ctx context.Context) (crypto.PublicKey, error) {
 	var lerr error
 	loader := ttlcache.LoaderFunc[string, crypto.PublicKey](
@@ -319,7 +366,26 @@ func (a *azureVaultClient) public(ctx context.Context) (crypto.PublicKey, error)
 	return item.Value(), nil
 }
 
-func (a *azureVaultClient) createKey(ctx context.Context) (crypto.PublicKey, error) {
+// KeyParameterNamespace is the namespace of CreateKey parameters recognized by Azure Key Vault
+const KeyParameterNamespace = "azure"
+
+func (a *azureVaultClient) createKey(ctx context.Context, params map[string]string) (crypto.PublicKey, error) {
+	keyType := azkeys.KeyTypeEC
+	for name, value := range params {
+		switch name {
+		case "hsm":
+			hsm, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s.%s: %w", KeyParameterNamespace, name, err)
+			}
+			if hsm {
+				keyType = azkeys.KeyTypeECHSM
+			}
+		default:
+			return nil, fmt.Errorf("unknown key parameter %s.%s", KeyParameterNamespace, name)
+		}
+	}
+
 	// check if the key already exists by attempting to fetch it
 	_, err := a.getKey(ctx)
 	// if the error is nil, this means the key already exists
@@ -358,13 +424,13 @@ func (a *azureVaultClient) createKey(ctx context.Context) (crypto.PublicKey, err
 				to.Ptr(azkeys.KeyOperationSign),
 				to.Ptr(azkeys.KeyOperationVerify),
 			},
-			Kty: to.Ptr(azkeys.KeyTypeEC),
+			Kty: to.Ptr(keyType),
 			Tags: map[string]*string{
 				"use": to.Ptr("sigstore"),
 			},
 		}, nil)
 	if err != nil {
-		return nil, err
+		return nil, classifyError(err)
 	}
 
 	return a.public(ctx)
@@ -373,7 +439,7 @@ func (a *azureVaultClient) createKey(ctx context.Context) (crypto.PublicKey, err
 func (a *azureVaultClient) getKeyVaultHashFunc(ctx context.Context) (crypto.Hash, azkeys.SignatureAlgorithm, error) {
 	publicKey, err := a.public(ctx)
 	if err != nil {
-		return 0, "", fmt.Errorf("failed to get public key: %w", err)
+		return 0, "", fmt.Errorf("%w: failed to get public key: %w", ErrKeyDiscovery, err)
 	}
 	switch keyImpl := publicKey.(type) {
 	case *ecdsa.PublicKey:
@@ -385,7 +451,7 @@ func (a *azureVaultClient) getKeyVaultHashFunc(ctx context.Context) (crypto.Hash
 		case elliptic.P521():
 			return crypto.SHA512, azkeys.SignatureAlgorithmES512, nil
 		default:
-			return 0, "", fmt.Errorf("unsupported key size: %s", keyImpl.Params().Name)
+			return 0, "", fmt.Errorf("%w: unsupported key size: %s", ErrKeyDiscovery, keyImpl.Params().Name)
 		}
 	case *rsa.PublicKey:
 		switch keyImpl.Size() {
@@ -396,11 +462,56 @@ func (a *azureVaultClient) getKeyVaultHashFunc(ctx context.Context) (crypto.Hash
 		case 512:
 			return crypto.SHA512, azkeys.SignatureAlgorithmRS512, nil
 		default:
-			return 0, "", fmt.Errorf("unsupported key size: %d", keyImpl.Size())
+			return 0, "", fmt.Errorf("%w: unsupported key size: %d", ErrKeyDiscovery, keyImpl.Size())
 		}
 	default:
-		return 0, "", fmt.Errorf("unsupported public key type: %T", publicKey)
+		return 0, "", fmt.Errorf("%w: unsupported public key type: %T", ErrKeyDiscovery, publicKey)
+	}
+}
+
+// isKeyNotFound returns true if err was caused by Azure Key Vault reporting that the key does not exist
+func isKeyNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
+
+// isAuthError returns true if err was caused by Azure rejecting the credentials or denying access to the key
+func isAuthError(err error) bool {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden
+	}
+	var authErr *azidentity.AuthenticationFailedError
+	return errors.As(err, &authErr)
+}
+
+// errorKind maps an Azure Key Vault or Azure identity error to a sigkms.ErrorKind
+func errorKind(err error) sigkms.ErrorKind {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return sigkms.ClassifyHTTPStatus(respErr.StatusCode)
+	}
+	var authErr *azidentity.AuthenticationFailedError
+	if errors.As(err, &authErr) {
+		return sigkms.ErrorKindAuth
+	}
+	return sigkms.ErrorKindPermanent
+}
+
+// classifyError wraps err returned by the Azure Key Vault API in a *sigkms.ClassifiedError
+func classifyError(err error) error {
+	return sigkms.Classify(err, errorKind)
+}
+
+// withRequestID wraps err in a *sigkms.RequestIDError if Azure Key Vault reported a request ID for the failed request
+func withRequestID(err error) error {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && respErr.RawResponse != nil {
+		if id := respErr.RawResponse.Header.Get("x-ms-request-id"); id != "" {
+			return &sigkms.RequestIDError{RequestID: id, Err: err}
+		}
 	}
+	return err
 }
 
 func (a *azureVaultClient) sign(ctx context.Context, hash []byte) ([]byte, error) {
@@ -416,7 +527,7 @@ func (a *azureVaultClient) sign(ctx context.Context, hash []byte) ([]byte, error
 
 	result, err := a.client.Sign(ctx, a.keyName, a.keyVersion, params, nil)
 	if err != nil {
-		return nil, fmt.Errorf("signing the payload: %w", err)
+		return nil, fmt.Errorf("signing the payload: %w", withRequestID(classifyError(err)))
 	}
 
 	return result.Result, nil
@@ -436,7 +547,7 @@ func (a *azureVaultClient) verify(ctx context.Context, signature, hash []byte) e
 
 	result, err := a.client.Verify(ctx, a.keyName, a.keyVersion, params, nil)
 	if err != nil {
-		return fmt.Errorf("verify: %w", err)
+		return fmt.Errorf("verify: %w", classifyError(err))
 	}
 
 	if !*result.Value {
@@ -32,7 +32,10 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+
+	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
 )
 
 type testKVClient struct {
@@ -116,6 +119,15 @@ func (c *non404RespClient) GetKey(_ context.Context, _, _ string, _ *azkeys.GetK
 	return result, err
 }
 
+type getKeyErrClient struct {
+	testKVClient
+	err error
+}
+
+func (c *getKeyErrClient) GetKey(_ context.Context, _, _ string, _ *azkeys.GetKeyOptions) (azkeys.GetKeyResponse, error) {
+	return azkeys.GetKeyResponse{}, c.err
+}
+
 func generatePublicKey(azureKeyType string) (azkeys.JSONWebKey, error) {
 	keyOps := []*azkeys.KeyOperation{to.Ptr(azkeys.KeyOperationSign), to.Ptr(azkeys.KeyOperationVerify)}
 	kid := "https://honk-vault.vault.azure.net/keys/honk-key/abc123"
@@ -259,7 +271,7 @@ func TestAzureVaultClientCreateKey(t *testing.T) {
 			),
 		}
 
-		_, err = client.createKey(context.Background())
+		_, err = client.createKey(context.Background(), nil)
 		if err != nil && tc.expectSuccess {
 			t.Fatalf("Test '%s' failed. Expected nil error, actual value: %v", tc.name, err)
 		}
@@ -269,6 +281,65 @@ func TestAzureVaultClientCreateKey(t *testing.T) {
 	}
 }
 
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want sigkms.ErrorKind
+	}{
+		{"throttled", &azcore.ResponseError{StatusCode: http.StatusTooManyRequests, ErrorCode: "Throttled"}, sigkms.ErrorKindThrottled},
+		{"forbidden", &azcore.ResponseError{StatusCode: http.StatusForbidden, ErrorCode: "Forbidden"}, sigkms.ErrorKindAuth},
+		{"unauthorized", &azcore.ResponseError{StatusCode: http.StatusUnauthorized, ErrorCode: "Unauthorized"}, sigkms.ErrorKindAuth},
+		{"credential", &azidentity.AuthenticationFailedError{}, sigkms.ErrorKindAuth},
+		{"key not found", &azcore.ResponseError{StatusCode: http.StatusNotFound, ErrorCode: "KeyNotFound"}, sigkms.ErrorKindNotFound},
+		{"unavailable", &azcore.ResponseError{StatusCode: http.StatusServiceUnavailable, ErrorCode: "ServiceUnavailable"}, sigkms.ErrorKindTransient},
+		{"bad parameter", &azcore.ResponseError{StatusCode: http.StatusBadRequest, ErrorCode: "BadParameter"}, sigkms.ErrorKindPermanent},
+		{"unknown", errors.New("unexpected error"), sigkms.ErrorKindPermanent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := azureVaultClient{client: &getKeyErrClient{err: tt.err}}
+			_, err := client.getKey(context.Background())
+			var ce *sigkms.ClassifiedError
+			if !errors.As(err, &ce) {
+				t.Fatalf("expected *ClassifiedError, got %v", err)
+			}
+			if ce.Kind != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, ce.Kind)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected classified error to wrap %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestWithRequestID(t *testing.T) {
+	respErr := &azcore.ResponseError{
+		StatusCode: http.StatusForbidden,
+		RawResponse: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header:     http.Header{"X-Ms-Request-Id": []string{"4f8e6b2a-0000-4000-8000-000000000001"}},
+		},
+	}
+	err := withRequestID(respErr)
+	var idErr *sigkms.RequestIDError
+	if !errors.As(err, &idErr) {
+		t.Fatalf("expected *RequestIDError, got %v", err)
+	}
+	if idErr.RequestID != "4f8e6b2a-0000-4000-8000-000000000001" {
+		t.Errorf("unexpected request ID %q", idErr.RequestID)
+	}
+	if !errors.Is(err, respErr) {
+		t.Error("expected error to wrap the response error")
+	}
+
+	plain := errors.New("no response")
+	if err := withRequestID(plain); err != plain {
+		t.Errorf("expected error without a request ID to be returned unchanged, got %v", err)
+	}
+}
+
 func TestGetAuthenticationMethod(t *testing.T) {
 	clearEnv := map[string]string{
 		"AZURE_TENANT_ID":     "",
@@ -407,3 +478,48 @@ func TestParseReference(t *testing.T) {
 		})
 	}
 }
+
+func TestAlgorithmDiscovery(t *testing.T) {
+	key, err := generatePublicKey("EC")
+	if err != nil {
+		t.Fatalf("unexpected error while generating public key for testing: %v", err)
+	}
+	newClient := func(c kvClient) *azureVaultClient {
+		return &azureVaultClient{
+			client: c,
+			keyCache: ttlcache.New[string, crypto.PublicKey](
+				ttlcache.WithDisableTouchOnHit[string, crypto.PublicKey](),
+			),
+		}
+	}
+
+	sv := &SignerVerifier{
+		defaultCtx: context.Background(),
+		client:     newClient(&testKVClient{key: key}),
+	}
+	if alg := sv.DefaultAlgorithm(); alg != AlgorithmES256 {
+		t.Errorf("expected discovered default algorithm %s, got %s", AlgorithmES256, alg)
+	}
+	if algs := sv.SupportedAlgorithms(); len(algs) != 1 || algs[0] != AlgorithmES256 {
+		t.Errorf("expected discovered supported algorithms [%s], got %v", AlgorithmES256, algs)
+	}
+
+	sv.client = newClient(&nonResponseErrClient{})
+	if _, _, err := sv.client.getKeyVaultHashFunc(context.Background()); !errors.Is(err, ErrKeyDiscovery) {
+		t.Errorf("expected ErrKeyDiscovery, got %v", err)
+	}
+	if alg := sv.DefaultAlgorithm(); alg != AlgorithmES256 {
+		t.Errorf("expected fallback default algorithm %s, got %s", AlgorithmES256, alg)
+	}
+	if algs := sv.SupportedAlgorithms(); len(algs) != len(azureSupportedAlgorithms) {
+		t.Errorf("expected fallback supported algorithms %v, got %v", azureSupportedAlgorithms, algs)
+	}
+
+	notFound := newClient(&keyNotFoundClient{getKeyReturnsErr: true, getKeyCallThreshold: 1})
+	if _, _, err := notFound.getKeyVaultHashFunc(context.Background()); !isKeyNotFound(err) {
+		t.Errorf("expected key not found error, got %v", err)
+	}
+	if _, _, err := newClient(&non404RespClient{}).getKeyVaultHashFunc(context.Background()); isKeyNotFound(err) {
+		t.Errorf("did not expect key not found error, got %v", err)
+	}
+}
@@ -125,7 +125,7 @@ func TestCreateKey(t *testing.T) {
 		t.Fatalf("LoadSignerVerifier unexpectedly returned non-nil error: %v", err)
 	}
 
-	publicKey, err := sv.client.createKey(context.Background())
+	publicKey, err := sv.client.createKey(context.Background(), nil)
 	if err != nil {
 		t.Errorf("getKey failed with error: %v", err)
 	}
@@ -18,6 +18,7 @@ package azure
 import (
 	"context"
 	"crypto"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"io"
@@ -26,7 +27,9 @@ import (
 	"golang.org/x/crypto/cryptobyte"
 	"golang.org/x/crypto/cryptobyte/asn1"
 
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
+	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
 	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
@@ -54,6 +57,8 @@ type SignerVerifier struct {
 	defaultCtx context.Context
 	hashFunc   crypto.Hash
 	client     *azureVaultClient
+	// defaultAlgorithm overrides the default algorithm if set by options.WithDefaultAlgorithm
+	defaultAlgorithm string
 }
 
 // LoadSignerVerifier generates signatures using the specified key in Azure Key Vault and hash algorithm.
@@ -70,6 +75,12 @@ func LoadSignerVerifier(defaultCtx context.Context, referenceStr string) (*Signe
 		return nil, err
 	}
 
+	// discover the key's algorithm up front so that a misconfigured key is reported on load;
+	// a key that does not exist yet (i.e. before CreateKey is called) is not an error
+	if _, _, err := a.client.getKeyVaultHashFunc(defaultCtx); err != nil && !isKeyNotFound(err) {
+		return nil, err
+	}
+
 	return a, nil
 }
 
@@ -134,7 +145,8 @@ func (a *SignerVerifier) SignMessage(message io.Reader, opts ...signature.SignOp
 //
 // - WithDigest()
 //
-// All other options are ignored if specified.
+// All other options are ignored if specified. Signatures are always verified remotely by
+// Azure Key Vault, so WithRemoteVerification() is honored implicitly.
 func (a *SignerVerifier) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
 	hashFunc, _, err := a.client.getKeyVaultHashFunc(a.defaultCtx)
 	if err != nil {
@@ -172,9 +184,19 @@ func (a *SignerVerifier) VerifySignature(sig, message io.Reader, opts ...signatu
 		return errors.New("parsing signature")
 	}
 
-	rawSigBytes := []byte{}
-	rawSigBytes = append(rawSigBytes, r.Bytes()...)
-	rawSigBytes = append(rawSigBytes, s.Bytes()...)
+	// Key Vault expects R and S to each be padded to the curve size
+	pub, err := a.client.public(a.defaultCtx)
+	if err != nil {
+		return fmt.Errorf("getting public key: %w", err)
+	}
+	ecPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unexpected public key type %T", pub)
+	}
+	rawSigBytes, err := cryptoutils.MarshalECDSASignatureRaw(ecPub.Curve, r, s)
+	if err != nil {
+		return fmt.Errorf("encoding signature: %w", err)
+	}
 	return a.client.verify(a.defaultCtx, rawSigBytes, digest)
 }
 
@@ -185,8 +207,24 @@ func (a *SignerVerifier) PublicKey(_ ...signature.PublicKeyOption) (crypto.Publi
 }
 
 // CreateKey attempts to create a new key in Vault with the specified algorithm.
-func (a *SignerVerifier) CreateKey(ctx context.Context, _ string) (crypto.PublicKey, error) {
-	return a.client.createKey(ctx)
+//
+// Azure Key Vault has no validate-only key creation call, so WithDryRun(true) returns
+// kms.ErrDryRunUnsupported without creating anything.
+//
+// The following parameters in the "azure" namespace are recognized from WithKeyParameters():
+//
+// - azure.hsm: "true" to create a hardware-backed (EC-HSM) key
+func (a *SignerVerifier) CreateKey(ctx context.Context, _ string, opts ...signature.CreateKeyOption) (crypto.PublicKey, error) {
+	var dryRun bool
+	var params map[string]string
+	for _, opt := range opts {
+		opt.ApplyDryRun(&dryRun)
+		opt.ApplyKeyParameters(&params)
+	}
+	if dryRun {
+		return nil, sigkms.ErrDryRunUnsupported
+	}
+	return a.client.createKey(ctx, sigkms.KeyParameters(KeyParameterNamespace, params))
 }
 
 type cryptoSignerWrapper struct {
@@ -236,12 +274,60 @@ func (a *SignerVerifier) CryptoSigner(ctx context.Context, errFunc func(error))
 	return csw, hashFunc, nil
 }
 
-// SupportedAlgorithms returns the list of algorithms supported by the Azure KMS service
-func (*SignerVerifier) SupportedAlgorithms() []string {
+// SupportedAlgorithms returns the signing algorithm of the key in Azure Key Vault if it can be
+// discovered; otherwise, it returns the list of algorithms supported by the Azure KMS service.
+func (a *SignerVerifier) SupportedAlgorithms() []string {
+	if alg, ok := a.discoveredAlgorithm(); ok {
+		return []string{alg}
+	}
 	return azureSupportedAlgorithms
 }
 
-// DefaultAlgorithm returns the default algorithm for the Azure KMS service
-func (*SignerVerifier) DefaultAlgorithm() string {
+// DefaultAlgorithm returns the algorithm given by options.WithDefaultAlgorithm when loaded
+// through kms.Get, or else the signing algorithm of the key in Azure Key Vault if it can be
+// discovered; otherwise, it returns the default algorithm for the Azure KMS service.
+func (a *SignerVerifier) DefaultAlgorithm() string {
+	if a.defaultAlgorithm != "" {
+		return a.defaultAlgorithm
+	}
+	if alg, ok := a.discoveredAlgorithm(); ok {
+		return alg
+	}
 	return AlgorithmES256
 }
+
+// discoveredAlgorithm returns the signing algorithm matching the type and size of the key.
+// The public key is cached by the client, so this does not call Azure Key Vault on every use.
+func (a *SignerVerifier) discoveredAlgorithm() (string, bool) {
+	if a.client == nil {
+		return "", false
+	}
+	_, alg, err := a.client.getKeyVaultHashFunc(a.defaultCtx)
+	if err != nil {
+		return "", false
+	}
+	return string(alg), true
+}
+
+// LatencyClass reports that operations are network-bound calls to the Azure Key Vault service
+func (*SignerVerifier) LatencyClass() signature.LatencyClass {
+	return signature.LatencyClassRemote
+}
+
+// GetKeyMetadata returns the creation time, enabled state, signing algorithm and tags of
+// the key from Azure Key Vault.
+func (a *SignerVerifier) GetKeyMetadata(ctx context.Context) (*sigkms.KeyMetadata, error) {
+	return a.client.keyMetadata(ctx)
+}
+
+// Preflight fetches the key from Azure Key Vault, returning a *sigkms.AuthError if the
+// credentials are rejected or access to the key is denied.
+func (a *SignerVerifier) Preflight(ctx context.Context) error {
+	if _, err := a.client.getKey(ctx); err != nil {
+		if isAuthError(err) {
+			return &sigkms.AuthError{Err: err}
+		}
+		return err
+	}
+	return nil
+}
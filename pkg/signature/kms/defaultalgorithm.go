@@ -0,0 +1,37 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// DefaultAlgorithmOption returns the algorithm given by options.WithDefaultAlgorithm, or an
+// empty string if none was given. Providers call it from their ProviderInit to honor the
+// option; it returns an error if the algorithm is not one of supported.
+func DefaultAlgorithmOption(supported []string, opts ...signature.RPCOption) (string, error) {
+	var algorithm string
+	for _, opt := range opts {
+		opt.ApplyDefaultAlgorithm(&algorithm)
+	}
+	if algorithm != "" && !slices.Contains(supported, algorithm) {
+		return "", fmt.Errorf("default algorithm %q is not supported; must be one of %v", algorithm, supported)
+	}
+	return algorithm, nil
+}
@@ -0,0 +1,120 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorKind classifies a failure reported by a KMS provider, so callers can handle errors
+// uniformly regardless of the provider's native error types
+type ErrorKind int
+
+const (
+	// ErrorKindPermanent indicates a failure that will recur if the request is retried unchanged
+	ErrorKindPermanent ErrorKind = iota
+	// ErrorKindThrottled indicates the request was rejected by a rate limit or quota
+	ErrorKindThrottled
+	// ErrorKindAuth indicates the credentials were rejected or access to the key was denied
+	ErrorKindAuth
+	// ErrorKindNotFound indicates the key or key version does not exist
+	ErrorKindNotFound
+	// ErrorKindTransient indicates a temporary failure, such as a timeout or an internal service error
+	ErrorKindTransient
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindPermanent:
+		return "permanent"
+	case ErrorKindThrottled:
+		return "throttled"
+	case ErrorKindAuth:
+		return "auth"
+	case ErrorKindNotFound:
+		return "not-found"
+	case ErrorKindTransient:
+		return "transient"
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// Retryable returns true if a request that failed with this kind of error may succeed if retried
+func (k ErrorKind) Retryable() bool {
+	return k == ErrorKindThrottled || k == ErrorKindTransient
+}
+
+// ClassifiedError wraps an error returned by a KMS provider with its ErrorKind. Providers
+// return it from calls to the backing service; use errors.As or KindOf to inspect it.
+type ClassifiedError struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+// Classify wraps err in a *ClassifiedError of the kind returned by classifier. Context
+// cancellation and deadline errors are classified as permanent and transient respectively
+// before classifier is consulted. A nil err is returned unchanged.
+func Classify(err error, classifier func(error) ErrorKind) error {
+	if err == nil {
+		return nil
+	}
+	var kind ErrorKind
+	switch {
+	case errors.Is(err, context.Canceled):
+		kind = ErrorKindPermanent
+	case errors.Is(err, context.DeadlineExceeded):
+		kind = ErrorKindTransient
+	default:
+		kind = classifier(err)
+	}
+	return &ClassifiedError{Kind: kind, Err: err}
+}
+
+// KindOf returns the ErrorKind of the first *ClassifiedError in err's chain, or
+// ErrorKindPermanent if there is none
+func KindOf(err error) ErrorKind {
+	var ce *ClassifiedError
+	if errors.As(err, &ce) {
+		return ce.Kind
+	}
+	return ErrorKindPermanent
+}
+
+// ClassifyHTTPStatus returns the ErrorKind conventionally associated with an HTTP response status code
+func ClassifyHTTPStatus(code int) ErrorKind {
+	switch {
+	case code == http.StatusTooManyRequests:
+		return ErrorKindThrottled
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ErrorKindAuth
+	case code == http.StatusNotFound:
+		return ErrorKindNotFound
+	case code == http.StatusRequestTimeout || code >= http.StatusInternalServerError:
+		return ErrorKindTransient
+	}
+	return ErrorKindPermanent
+}
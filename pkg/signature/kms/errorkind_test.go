@@ -0,0 +1,71 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	base := errors.New("provider error")
+	classifier := func(error) ErrorKind { return ErrorKindThrottled }
+
+	if Classify(nil, classifier) != nil {
+		t.Error("expected nil error to be returned unchanged")
+	}
+	err := fmt.Errorf("signing: %w", Classify(base, classifier))
+	var ce *ClassifiedError
+	if !errors.As(err, &ce) || ce.Kind != ErrorKindThrottled {
+		t.Fatalf("expected throttled ClassifiedError, got %v", err)
+	}
+	if !errors.Is(err, base) {
+		t.Error("expected classified error to wrap the provider error")
+	}
+	if !KindOf(err).Retryable() {
+		t.Error("expected throttled error to be retryable")
+	}
+
+	if k := KindOf(Classify(context.DeadlineExceeded, classifier)); k != ErrorKindTransient {
+		t.Errorf("expected transient for deadline exceeded, got %v", k)
+	}
+	if k := KindOf(Classify(context.Canceled, classifier)); k != ErrorKindPermanent {
+		t.Errorf("expected permanent for cancellation, got %v", k)
+	}
+	if k := KindOf(base); k != ErrorKindPermanent {
+		t.Errorf("expected permanent for unclassified error, got %v", k)
+	}
+}
+
+func TestClassifyHTTPStatus(t *testing.T) {
+	tests := map[int]ErrorKind{
+		400: ErrorKindPermanent,
+		401: ErrorKindAuth,
+		403: ErrorKindAuth,
+		404: ErrorKindNotFound,
+		408: ErrorKindTransient,
+		429: ErrorKindThrottled,
+		500: ErrorKindTransient,
+		503: ErrorKindTransient,
+	}
+	for code, want := range tests {
+		if got := ClassifyHTTPStatus(code); got != want {
+			t.Errorf("status %d: expected %v, got %v", code, want, got)
+		}
+	}
+}
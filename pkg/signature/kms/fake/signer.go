@@ -30,18 +30,40 @@ type KmsCtxKey struct{}
 
 // SignerVerifier creates and verifies digital signatures over a message using an in-memory signer
 type SignerVerifier struct {
-	signer signature.SignerVerifier
+	signer           signature.SignerVerifier
+	defaultAlgorithm string
 }
 
 // ReferenceScheme is a scheme for fake KMS keys. Do not use in production.
 const ReferenceScheme = "fakekms://"
 
 func init() {
-	sigkms.AddProvider(ReferenceScheme, func(ctx context.Context, _ string, hf crypto.Hash, _ ...signature.RPCOption) (sigkms.SignerVerifier, error) {
-		return LoadSignerVerifier(ctx, hf)
+	sigkms.AddProvider(ReferenceScheme, func(ctx context.Context, _ string, hf crypto.Hash, opts ...signature.RPCOption) (sigkms.SignerVerifier, error) {
+		algorithm, err := sigkms.DefaultAlgorithmOption(supportedAlgorithms, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if algorithm != "" {
+			// sign and verify with the hash of the chosen algorithm
+			hf = algorithmHashes[algorithm]
+		}
+		sv, err := LoadSignerVerifier(ctx, hf)
+		if err != nil {
+			return nil, err
+		}
+		sv.defaultAlgorithm = algorithm
+		return sv, nil
 	})
 }
 
+const algorithmECDSAP256SHA256 = "ecdsa-p256-sha256"
+
+var supportedAlgorithms = []string{algorithmECDSAP256SHA256}
+
+var algorithmHashes = map[string]crypto.Hash{
+	algorithmECDSAP256SHA256: crypto.SHA256,
+}
+
 // LoadSignerVerifier generates a signer/verifier using the default ECDSA signer or loads
 // a signer from a provided private key and hash. The context should contain a mapping from
 // a string "priv" to a crypto.PrivateKey (RSA, ECDSA, or ED25519).
@@ -88,13 +110,32 @@ func (g *SignerVerifier) PublicKey(opts ...signature.PublicKeyOption) (crypto.Pu
 //
 // - WithDigest()
 //
-// All other options are ignored if specified.
+// - WithRemoteVerification()
+//
+// All other options are ignored if specified. As the fake KMS has no remote service,
+// requesting remote verification returns kms.ErrRemoteVerificationUnsupported.
 func (g *SignerVerifier) VerifySignature(signature, message io.Reader, opts ...signature.VerifyOption) error {
+	var remoteVerification bool
+	for _, opt := range opts {
+		opt.ApplyRemoteVerification(&remoteVerification)
+	}
+	if remoteVerification {
+		return sigkms.ErrRemoteVerificationUnsupported
+	}
+
 	return g.signer.VerifySignature(signature, message, opts...)
 }
 
-// CreateKey returns the signer's public key.
-func (g *SignerVerifier) CreateKey(_ context.Context, _ string) (crypto.PublicKey, error) {
+// CreateKey returns the signer's public key. If WithDryRun(true) is given, nothing
+// needs validating and CreateKey returns a nil key and no error.
+func (g *SignerVerifier) CreateKey(_ context.Context, _ string, opts ...signature.CreateKeyOption) (crypto.PublicKey, error) {
+	var dryRun bool
+	for _, opt := range opts {
+		opt.ApplyDryRun(&dryRun)
+	}
+	if dryRun {
+		return nil, nil
+	}
 	pub, err := g.signer.PublicKey()
 	if err != nil {
 		return nil, err
@@ -146,10 +187,32 @@ func (g *SignerVerifier) CryptoSigner(ctx context.Context, errFunc func(error))
 
 // SupportedAlgorithms returns a list with the default algorithm
 func (g *SignerVerifier) SupportedAlgorithms() (result []string) {
-	return []string{"ecdsa-p256-sha256"}
+	return supportedAlgorithms
 }
 
-// DefaultAlgorithm returns the default algorithm for the signer
+// DefaultAlgorithm returns the default algorithm for the signer, or the algorithm given by
+// options.WithDefaultAlgorithm when loaded through kms.Get
 func (g *SignerVerifier) DefaultAlgorithm() string {
-	return "ecdsa-p256-sha256"
+	if g.defaultAlgorithm != "" {
+		return g.defaultAlgorithm
+	}
+	return algorithmECDSAP256SHA256
+}
+
+// LatencyClass reports that operations are performed in-process
+func (g *SignerVerifier) LatencyClass() signature.LatencyClass {
+	return signature.LatencyClassLocal
+}
+
+// GetKeyMetadata returns the algorithm of the in-memory key; no other metadata is available
+func (g *SignerVerifier) GetKeyMetadata(_ context.Context) (*sigkms.KeyMetadata, error) {
+	return &sigkms.KeyMetadata{
+		State:     "Enabled",
+		Algorithm: g.DefaultAlgorithm(),
+	}, nil
+}
+
+// Preflight always succeeds, as the in-memory key needs no credentials
+func (g *SignerVerifier) Preflight(_ context.Context) error {
+	return nil
 }
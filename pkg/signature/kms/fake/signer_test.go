@@ -22,10 +22,13 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
+	"errors"
 	"testing"
 
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/signature/kms"
+	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
 func TestFakeSigner(t *testing.T) {
@@ -46,6 +49,9 @@ func TestFakeSigner(t *testing.T) {
 	if err := cryptoutils.EqualKeys(createdPub, pub); err != nil {
 		t.Fatalf("expected public keys to be equal: %v", err)
 	}
+	if dryRunPub, err := signer.CreateKey(context.Background(), "", options.WithDryRun(true)); err != nil || dryRunPub != nil {
+		t.Fatalf("expected dry-run key creation to return no key and no error, got %v, %v", dryRunPub, err)
+	}
 
 	if signer.DefaultAlgorithm() != signer.SupportedAlgorithms()[0] {
 		t.Fatal("expected algorithms to match")
@@ -126,3 +132,73 @@ func TestFakeSignerWithPrivateKey(t *testing.T) {
 		t.Fatalf("unexpected error verifying signature: %v", err)
 	}
 }
+
+func TestFakeSignerRemoteVerification(t *testing.T) {
+	msg := []byte{1, 2, 3, 4, 5}
+
+	signer, err := kms.Get(context.Background(), "fakekms://key", crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error getting signer: %v", err)
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatalf("unexpected error signing message: %v", err)
+	}
+
+	err = signer.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg), options.WithRemoteVerification(true))
+	if !errors.Is(err, kms.ErrRemoteVerificationUnsupported) {
+		t.Fatalf("expected ErrRemoteVerificationUnsupported, got: %v", err)
+	}
+	if err := signer.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg), options.WithRemoteVerification(false)); err != nil {
+		t.Fatalf("unexpected error verifying signature locally: %v", err)
+	}
+}
+
+func TestFakeSignerDefaultAlgorithm(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error generating key: %v", err)
+	}
+	ctx := context.WithValue(context.Background(), KmsCtxKey{}, priv)
+
+	// the hash passed to kms.Get is overridden by the hash of the default algorithm
+	sv, err := kms.Get(ctx, "fakekms://key", crypto.SHA384, options.WithDefaultAlgorithm("ecdsa-p256-sha256"))
+	if err != nil {
+		t.Fatalf("unexpected error getting signer with supported default algorithm: %v", err)
+	}
+	if _, ok := sv.(*SignerVerifier); !ok {
+		t.Errorf("expected *SignerVerifier, got %T", sv)
+	}
+	if alg := sv.DefaultAlgorithm(); alg != "ecdsa-p256-sha256" {
+		t.Errorf("expected default algorithm ecdsa-p256-sha256, got %s", alg)
+	}
+	if _, err := sv.CreateKey(ctx, ""); err != nil {
+		t.Errorf("unexpected error creating key with default algorithm: %v", err)
+	}
+	msg := []byte("msg")
+	sig, err := sv.SignMessage(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg)); err != nil {
+		t.Errorf("unexpected error verifying: %v", err)
+	}
+	sha256Verifier, err := signature.LoadECDSAVerifier(&priv.PublicKey, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sha256Verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg)); err != nil {
+		t.Errorf("expected signature over a SHA-256 digest: %v", err)
+	}
+	sha384Verifier, err := signature.LoadECDSAVerifier(&priv.PublicKey, crypto.SHA384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sha384Verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg)); err == nil {
+		t.Error("expected signature not to be over a SHA-384 digest")
+	}
+
+	if _, err := kms.Get(ctx, "fakekms://key", crypto.SHA256, options.WithDefaultAlgorithm("ed25519")); err == nil {
+		t.Error("expected error getting signer with unsupported default algorithm")
+	}
+}
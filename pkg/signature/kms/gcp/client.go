@@ -27,11 +27,15 @@ import (
 	"io"
 	"log"
 	"regexp"
+	"strings"
 	"time"
 
 	gcpkms "cloud.google.com/go/kms/apiv1"
 	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/googleapis/gax-go/v2"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/jellydator/ttlcache/v3"
@@ -43,7 +47,16 @@ import (
 
 func init() {
 	sigkms.AddProvider(ReferenceScheme, func(ctx context.Context, keyResourceID string, _ crypto.Hash, opts ...signature.RPCOption) (sigkms.SignerVerifier, error) {
-		return LoadSignerVerifier(ctx, keyResourceID)
+		algorithm, err := sigkms.DefaultAlgorithmOption((&SignerVerifier{}).SupportedAlgorithms(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		sv, err := LoadSignerVerifier(ctx, keyResourceID)
+		if err != nil {
+			return nil, err
+		}
+		sv.defaultAlgorithm = algorithm
+		return sv, nil
 	})
 }
 
@@ -74,6 +87,18 @@ var algorithmMap = map[string]kmspb.CryptoKeyVersion_CryptoKeyVersionAlgorithm{
 	AlgorithmRSAPSS4096SHA512:      kmspb.CryptoKeyVersion_RSA_SIGN_PSS_4096_SHA512,
 }
 
+// keyManagementClient is the subset of the GCP KMS client used by gcpClient
+type keyManagementClient interface {
+	GetCryptoKey(ctx context.Context, req *kmspb.GetCryptoKeyRequest, opts ...gax.CallOption) (*kmspb.CryptoKey, error)
+	GetCryptoKeyVersion(ctx context.Context, req *kmspb.GetCryptoKeyVersionRequest, opts ...gax.CallOption) (*kmspb.CryptoKeyVersion, error)
+	ListCryptoKeyVersions(ctx context.Context, req *kmspb.ListCryptoKeyVersionsRequest, opts ...gax.CallOption) *gcpkms.CryptoKeyVersionIterator
+	GetPublicKey(ctx context.Context, req *kmspb.GetPublicKeyRequest, opts ...gax.CallOption) (*kmspb.PublicKey, error)
+	AsymmetricSign(ctx context.Context, req *kmspb.AsymmetricSignRequest, opts ...gax.CallOption) (*kmspb.AsymmetricSignResponse, error)
+	CreateCryptoKey(ctx context.Context, req *kmspb.CreateCryptoKeyRequest, opts ...gax.CallOption) (*kmspb.CryptoKey, error)
+	GetKeyRing(ctx context.Context, req *kmspb.GetKeyRingRequest, opts ...gax.CallOption) (*kmspb.KeyRing, error)
+	CreateKeyRing(ctx context.Context, req *kmspb.CreateKeyRingRequest, opts ...gax.CallOption) (*kmspb.KeyRing, error)
+}
+
 type gcpClient struct {
 	defaultCtx context.Context
 	refString  string
@@ -83,7 +108,7 @@ type gcpClient struct {
 	keyName    string
 	version    string
 	kvCache    *ttlcache.Cache[string, cryptoKeyVersion]
-	kmsClient  *gcpkms.KeyManagementClient
+	kmsClient  keyManagementClient
 }
 
 func newGCPClient(ctx context.Context, refStr string, opts ...option.ClientOption) (*gcpClient, error) {
@@ -179,7 +204,7 @@ func (g *gcpClient) keyVersionName(ctx context.Context) (*cryptoKeyVersion, erro
 		}
 		kv, err = g.kmsClient.GetCryptoKeyVersion(ctx, req)
 		if err != nil {
-			return nil, err
+			return nil, classifyError(err)
 		}
 	} else {
 		req := &kmspb.ListCryptoKeyVersionsRequest{
@@ -192,7 +217,7 @@ func (g *gcpClient) keyVersionName(ctx context.Context) (*cryptoKeyVersion, erro
 		// pick the key version that is enabled with the greatest version value
 		kv, err = iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("unable to find an enabled key version in GCP KMS: %w", err)
+			return nil, fmt.Errorf("unable to find an enabled key version in GCP KMS: %w", classifyError(err))
 		}
 	}
 	// kv is keyVersion to use
@@ -245,7 +270,7 @@ func (g *gcpClient) fetchPublicKey(ctx context.Context, name string) (crypto.Pub
 	// Call the API.
 	pk, err := g.kmsClient.GetPublicKey(ctx, pkreq)
 	if err != nil {
-		return nil, fmt.Errorf("public key: %w", err)
+		return nil, fmt.Errorf("public key: %w", classifyError(err))
 	}
 	return cryptoutils.UnmarshalPEMToPublicKey([]byte(pk.GetPem()))
 }
@@ -290,12 +315,70 @@ func (g *gcpClient) getCKV() (*cryptoKeyVersion, error) {
 	return nil, lerr
 }
 
+// checkKeyState returns sigkms.ErrKeyNotUsable if the key version is not enabled. The key
+// version is served from the cache, so this does not call GCP KMS on every use.
+func (g *gcpClient) checkKeyState() error {
+	ckv, err := g.getCKV()
+	if err != nil {
+		return err
+	}
+	if state := ckv.CryptoKeyVersion.GetState(); state != kmspb.CryptoKeyVersion_ENABLED {
+		return fmt.Errorf("%w: key version state is %s", sigkms.ErrKeyNotUsable, state)
+	}
+	return nil
+}
+
+func (g *gcpClient) keyMetadata(ctx context.Context) (*sigkms.KeyMetadata, error) {
+	ckv, err := g.getCKV()
+	if err != nil {
+		return nil, err
+	}
+	// fetch the key version again rather than using the cached value, as its state may have changed
+	kv, err := g.kmsClient.GetCryptoKeyVersion(ctx, &kmspb.GetCryptoKeyVersionRequest{
+		Name: ckv.CryptoKeyVersion.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting key version: %w", classifyError(err))
+	}
+	key, err := g.kmsClient.GetCryptoKey(ctx, &kmspb.GetCryptoKeyRequest{
+		Name: fmt.Sprintf("projects/%s/locations/%s/keyRings/%s/cryptoKeys/%s", g.projectID, g.locationID, g.keyRing, g.keyName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting key: %w", classifyError(err))
+	}
+
+	md := &sigkms.KeyMetadata{
+		State:     kv.GetState().String(),
+		Algorithm: kv.GetAlgorithm().String(),
+		Labels:    key.GetLabels(),
+	}
+	if kv.GetCreateTime() != nil {
+		md.CreatedAt = kv.GetCreateTime().AsTime()
+	}
+	for name, alg := range algorithmMap {
+		if alg == kv.GetAlgorithm() {
+			md.Algorithm = name
+			break
+		}
+	}
+	return md, nil
+}
+
 func (g *gcpClient) sign(ctx context.Context, digest []byte, alg crypto.Hash, crc uint32) ([]byte, error) {
 	ckv, err := g.getCKV()
 	if err != nil {
 		return nil, err
 	}
 
+	// the key version's algorithm fixes the digest that GCP KMS will accept, so
+	// reject a mismatch here rather than sending a request that will fail remotely
+	if alg != ckv.HashFunc {
+		return nil, fmt.Errorf("hash function %v does not match the %v digest required by key version", alg, ckv.HashFunc)
+	}
+	if len(digest) != alg.Size() {
+		return nil, fmt.Errorf("unexpected length of digest for hash function %v: got %d bytes, want %d", alg, len(digest), alg.Size())
+	}
+
 	gcpSignReq := kmspb.AsymmetricSignRequest{
 		Name:   ckv.CryptoKeyVersion.Name,
 		Digest: &kmspb.Digest{},
@@ -324,7 +407,7 @@ func (g *gcpClient) sign(ctx context.Context, digest []byte, alg crypto.Hash, cr
 
 	resp, err := g.kmsClient.AsymmetricSign(ctx, &gcpSignReq)
 	if err != nil {
-		return nil, fmt.Errorf("calling GCP AsymmetricSign: %w", err)
+		return nil, fmt.Errorf("calling GCP AsymmetricSign: %w", classifyError(err))
 	}
 
 	// Optional, but recommended: perform integrity verification on result.
@@ -368,7 +451,31 @@ func (g *gcpClient) verify(sig, message io.Reader, opts ...signature.VerifyOptio
 	return nil
 }
 
-func (g *gcpClient) createKey(ctx context.Context, algorithm string) (crypto.PublicKey, error) {
+// KeyParameterNamespace is the namespace of CreateKey parameters recognized by GCP KMS
+const KeyParameterNamespace = "gcp"
+
+func parseProtectionLevel(params map[string]string) (kmspb.ProtectionLevel, error) {
+	protectionLevel := kmspb.ProtectionLevel_SOFTWARE
+	for name, value := range params {
+		switch name {
+		case "protectionLevel":
+			level, ok := kmspb.ProtectionLevel_value[strings.ToUpper(value)]
+			if !ok || level == int32(kmspb.ProtectionLevel_PROTECTION_LEVEL_UNSPECIFIED) {
+				return 0, fmt.Errorf("unknown protection level %q", value)
+			}
+			protectionLevel = kmspb.ProtectionLevel(level)
+		default:
+			return 0, fmt.Errorf("unknown key parameter %s.%s", KeyParameterNamespace, name)
+		}
+	}
+	return protectionLevel, nil
+}
+
+func (g *gcpClient) createKey(ctx context.Context, algorithm string, params map[string]string) (crypto.PublicKey, error) {
+	protectionLevel, err := parseProtectionLevel(params)
+	if err != nil {
+		return nil, err
+	}
 	if err := g.createKeyRing(ctx); err != nil {
 		return nil, fmt.Errorf("creating key ring: %w", err)
 	}
@@ -390,12 +497,13 @@ func (g *gcpClient) createKey(ctx context.Context, algorithm string) (crypto.Pub
 		CryptoKey: &kmspb.CryptoKey{
 			Purpose: kmspb.CryptoKey_ASYMMETRIC_SIGN,
 			VersionTemplate: &kmspb.CryptoKeyVersionTemplate{
-				Algorithm: algorithmMap[algorithm],
+				Algorithm:       algorithmMap[algorithm],
+				ProtectionLevel: protectionLevel,
 			},
 		},
 	}
 	if _, err := g.kmsClient.CreateCryptoKey(ctx, createKeyRequest); err != nil {
-		return nil, fmt.Errorf("creating crypto key: %w", err)
+		return nil, fmt.Errorf("creating crypto key: %w", classifyError(err))
 	}
 	return g.public(ctx)
 }
@@ -415,6 +523,40 @@ func (g *gcpClient) createKeyRing(ctx context.Context) error {
 		KeyRingId: g.keyRing,
 	}
 	result, err := g.kmsClient.CreateKeyRing(ctx, createKeyRingRequest)
+	if err != nil {
+		return classifyError(err)
+	}
 	log.Printf("Created key ring %s in GCP KMS.\n", result.GetName())
-	return err
+	return nil
+}
+
+// isAuthError returns true if err was caused by GCP KMS rejecting the credentials or denying access to the key
+func isAuthError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return true
+	default:
+		return false
+	}
+}
+
+// errorKind maps a GCP KMS gRPC status to a sigkms.ErrorKind
+func errorKind(err error) sigkms.ErrorKind {
+	switch status.Code(err) {
+	case codes.ResourceExhausted:
+		return sigkms.ErrorKindThrottled
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return sigkms.ErrorKindAuth
+	case codes.NotFound:
+		return sigkms.ErrorKindNotFound
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.Internal:
+		return sigkms.ErrorKindTransient
+	default:
+		return sigkms.ErrorKindPermanent
+	}
+}
+
+// classifyError wraps err returned by the GCP KMS API in a *sigkms.ClassifiedError
+func classifyError(err error) error {
+	return sigkms.Classify(err, errorKind)
 }
@@ -16,11 +16,27 @@
 package gcp
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"hash/crc32"
 	"testing"
+	"time"
 
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/jellydator/ttlcache/v3"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
+	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
 func TestParseReference(t *testing.T) {
@@ -108,3 +124,235 @@ func TestOptionsWork(_ *testing.T) {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{})
 	LoadSignerVerifier(context.Background(), "gcpkms://projects/a-project/locations/global/keyRings/a-keyring/cryptoKeys/key-name", option.WithTokenSource(ts))
 }
+
+type testKMSClient struct {
+	keyManagementClient
+	signReq *kmspb.AsymmetricSignRequest
+	signErr error
+}
+
+func (c *testKMSClient) AsymmetricSign(_ context.Context, req *kmspb.AsymmetricSignRequest, _ ...gax.CallOption) (*kmspb.AsymmetricSignResponse, error) {
+	c.signReq = req
+	if c.signErr != nil {
+		return nil, c.signErr
+	}
+	sig := []byte("signature")
+	return &kmspb.AsymmetricSignResponse{
+		Signature:            sig,
+		SignatureCrc32C:      wrapperspb.Int64(int64(crc32.Checksum(sig, crc32.MakeTable(crc32.Castagnoli)))),
+		VerifiedDigestCrc32C: true,
+	}, nil
+}
+
+type errReader struct{}
+
+func (errReader) Read(_ []byte) (int, error) {
+	return 0, errors.New("message should not be read")
+}
+
+func newTestSignerVerifier(client keyManagementClient) *SignerVerifier {
+	g := &gcpClient{
+		kmsClient: client,
+		version:   "1",
+		kvCache: ttlcache.New[string, cryptoKeyVersion](
+			ttlcache.WithDisableTouchOnHit[string, cryptoKeyVersion](),
+		),
+	}
+	g.kvCache.Set(cacheKey, cryptoKeyVersion{
+		CryptoKeyVersion: &kmspb.CryptoKeyVersion{
+			Name:      "projects/pp/locations/ll/keyRings/rr/cryptoKeys/kk/cryptoKeyVersions/1",
+			Algorithm: kmspb.CryptoKeyVersion_EC_SIGN_P256_SHA256,
+		},
+		HashFunc: crypto.SHA256,
+	}, ttlcache.NoTTL)
+	return &SignerVerifier{defaultCtx: context.Background(), client: g}
+}
+
+func TestSignMessageWithDigest(t *testing.T) {
+	client := &testKMSClient{}
+	sv := newTestSignerVerifier(client)
+
+	digest := sha256.Sum256([]byte("a very large message"))
+	if _, err := sv.SignMessage(errReader{}, options.WithDigest(digest[:])); err != nil {
+		t.Fatalf("unexpected error signing with digest: %v", err)
+	}
+	if client.signReq == nil {
+		t.Fatal("expected AsymmetricSign to be called")
+	}
+	if len(client.signReq.GetData()) != 0 {
+		t.Error("expected message data not to be sent to GCP KMS")
+	}
+	if !bytes.Equal(client.signReq.GetDigest().GetSha256(), digest[:]) {
+		t.Errorf("expected digest %x to be sent, got %x", digest, client.signReq.GetDigest().GetSha256())
+	}
+	if client.signReq.GetDigestCrc32C() == nil {
+		t.Error("expected digest CRC32C to be set")
+	}
+}
+
+func TestSignMessageWithInvalidDigest(t *testing.T) {
+	client := &testKMSClient{}
+	sv := newTestSignerVerifier(client)
+
+	if _, err := sv.SignMessage(nil, options.WithDigest([]byte("too short"))); err == nil {
+		t.Error("expected error for digest of the wrong length")
+	}
+
+	digest := make([]byte, crypto.SHA384.Size())
+	if _, err := sv.SignMessage(nil, options.WithDigest(digest), options.WithCryptoSignerOpts(crypto.SHA384)); err == nil {
+		t.Error("expected error for hash function not matching the key version algorithm")
+	}
+
+	if client.signReq != nil {
+		t.Error("expected AsymmetricSign not to be called for an invalid digest")
+	}
+}
+
+func TestVerifySignatureRemoteUnsupported(t *testing.T) {
+	sv := newTestSignerVerifier(&testKMSClient{})
+
+	err := sv.VerifySignature(bytes.NewReader([]byte("sig")), bytes.NewReader([]byte("msg")), options.WithRemoteVerification(true))
+	if !errors.Is(err, sigkms.ErrRemoteVerificationUnsupported) {
+		t.Fatalf("expected ErrRemoteVerificationUnsupported, got: %v", err)
+	}
+}
+
+func TestSignMessageKeyStateCheck(t *testing.T) {
+	digest := sha256.Sum256([]byte("message"))
+	for _, tc := range []struct {
+		state   kmspb.CryptoKeyVersion_CryptoKeyVersionState
+		wantErr bool
+	}{
+		{state: kmspb.CryptoKeyVersion_ENABLED},
+		{state: kmspb.CryptoKeyVersion_DISABLED, wantErr: true},
+		{state: kmspb.CryptoKeyVersion_DESTROY_SCHEDULED, wantErr: true},
+	} {
+		t.Run(tc.state.String(), func(t *testing.T) {
+			client := &testKMSClient{}
+			sv := newTestSignerVerifier(client)
+			sv.client.kvCache.Get(cacheKey).Value().CryptoKeyVersion.State = tc.state
+
+			_, err := sv.SignMessage(nil, options.WithDigest(digest[:]), options.WithKeyStateCheck(true))
+			if tc.wantErr {
+				if !errors.Is(err, sigkms.ErrKeyNotUsable) {
+					t.Errorf("expected ErrKeyNotUsable, got %v", err)
+				}
+				if client.signReq != nil {
+					t.Error("expected AsymmetricSign not to be called for an unusable key")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			if client.signReq == nil {
+				t.Error("expected AsymmetricSign to be called")
+			}
+		})
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	digest := sha256.Sum256([]byte("message"))
+	tests := []struct {
+		name string
+		err  error
+		want sigkms.ErrorKind
+	}{
+		{"quota", status.Error(codes.ResourceExhausted, "Quota exceeded for quota metric 'Cryptographic requests'"), sigkms.ErrorKindThrottled},
+		{"permission denied", status.Error(codes.PermissionDenied, "Permission 'cloudkms.cryptoKeyVersions.useToSign' denied"), sigkms.ErrorKindAuth},
+		{"unauthenticated", status.Error(codes.Unauthenticated, "Request had invalid authentication credentials"), sigkms.ErrorKindAuth},
+		{"not found", status.Error(codes.NotFound, "CryptoKeyVersion not found"), sigkms.ErrorKindNotFound},
+		{"unavailable", status.Error(codes.Unavailable, "The service is currently unavailable"), sigkms.ErrorKindTransient},
+		{"precondition", status.Error(codes.FailedPrecondition, "CryptoKeyVersion is not enabled"), sigkms.ErrorKindPermanent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sv := newTestSignerVerifier(&testKMSClient{signErr: tt.err})
+			_, err := sv.SignMessage(nil, options.WithDigest(digest[:]))
+			var ce *sigkms.ClassifiedError
+			if !errors.As(err, &ce) {
+				t.Fatalf("expected *ClassifiedError, got %v", err)
+			}
+			if ce.Kind != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, ce.Kind)
+			}
+			if status.Code(err) != status.Code(tt.err) {
+				t.Errorf("expected status code %v to be preserved, got %v", status.Code(tt.err), status.Code(err))
+			}
+		})
+	}
+}
+
+type metadataKMSClient struct {
+	keyManagementClient
+	kv  *kmspb.CryptoKeyVersion
+	key *kmspb.CryptoKey
+}
+
+func (c *metadataKMSClient) GetCryptoKeyVersion(_ context.Context, _ *kmspb.GetCryptoKeyVersionRequest, _ ...gax.CallOption) (*kmspb.CryptoKeyVersion, error) {
+	return c.kv, nil
+}
+
+func (c *metadataKMSClient) GetCryptoKey(_ context.Context, _ *kmspb.GetCryptoKeyRequest, _ ...gax.CallOption) (*kmspb.CryptoKey, error) {
+	return c.key, nil
+}
+
+func TestGetKeyMetadata(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	client := &metadataKMSClient{
+		kv: &kmspb.CryptoKeyVersion{
+			State:      kmspb.CryptoKeyVersion_DISABLED,
+			Algorithm:  kmspb.CryptoKeyVersion_EC_SIGN_P256_SHA256,
+			CreateTime: timestamppb.New(created),
+		},
+		key: &kmspb.CryptoKey{Labels: map[string]string{"team": "release"}},
+	}
+	sv := newTestSignerVerifier(client)
+
+	md, err := sv.GetKeyMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error getting key metadata: %v", err)
+	}
+	if !md.CreatedAt.Equal(created) {
+		t.Errorf("expected creation time %v, got %v", created, md.CreatedAt)
+	}
+	if md.State != kmspb.CryptoKeyVersion_DISABLED.String() {
+		t.Errorf("expected state %s, got %s", kmspb.CryptoKeyVersion_DISABLED, md.State)
+	}
+	if md.Algorithm != AlgorithmECDSAP256SHA256 {
+		t.Errorf("expected algorithm %s, got %s", AlgorithmECDSAP256SHA256, md.Algorithm)
+	}
+	if md.Labels["team"] != "release" {
+		t.Errorf("expected labels to be returned, got %v", md.Labels)
+	}
+}
+
+func TestParseProtectionLevel(t *testing.T) {
+	params := map[string]string{
+		"gcp.protectionLevel": "hsm",
+		"aws.policy":          "{}",
+	}
+	level, err := parseProtectionLevel(sigkms.KeyParameters(KeyParameterNamespace, params))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if level != kmspb.ProtectionLevel_HSM {
+		t.Errorf("expected protection level HSM, got %v", level)
+	}
+
+	level, err = parseProtectionLevel(nil)
+	if err != nil || level != kmspb.ProtectionLevel_SOFTWARE {
+		t.Errorf("expected default protection level SOFTWARE, got %v (%v)", level, err)
+	}
+
+	for _, params := range []map[string]string{
+		{"protectionLevel": "QUANTUM"},
+		{"protectionLevel": "PROTECTION_LEVEL_UNSPECIFIED"},
+		{"keyRingLocation": "us"},
+	} {
+		if _, err := parseProtectionLevel(params); err == nil {
+			t.Errorf("expected error for parameters %v", params)
+		}
+	}
+}
@@ -16,6 +16,7 @@
 package gcp
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"fmt"
@@ -23,6 +24,7 @@ import (
 	"io"
 
 	"github.com/sigstore/sigstore/pkg/signature"
+	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
 	"github.com/sigstore/sigstore/pkg/signature/options"
 	"google.golang.org/api/option"
 )
@@ -37,6 +39,8 @@ var gcpSupportedHashFuncs = []crypto.Hash{
 type SignerVerifier struct {
 	defaultCtx context.Context
 	client     *gcpClient
+	// defaultAlgorithm overrides the default algorithm if set by options.WithDefaultAlgorithm
+	defaultAlgorithm string
 }
 
 // LoadSignerVerifier generates signatures using the specified key object in GCP KMS and hash algorithm.
@@ -58,7 +62,8 @@ func LoadSignerVerifier(defaultCtx context.Context, referenceStr string, opts ..
 
 // SignMessage signs the provided message using GCP KMS. If the message is provided,
 // this method will compute the digest according to the hash function specified
-// when the Signer was created.
+// when the Signer was created. Only the digest is ever sent to GCP KMS; if one is
+// supplied with WithDigest(), the message is not read at all.
 //
 // SignMessage recognizes the following Options listed in order of preference:
 //
@@ -68,11 +73,16 @@ func LoadSignerVerifier(defaultCtx context.Context, referenceStr string, opts ..
 //
 // - WithCryptoSignerOpts()
 //
+// - WithKeyStateCheck()
+//
+// - WithVerifyAfterSign()
+//
 // All other options are ignored if specified.
 func (g *SignerVerifier) SignMessage(message io.Reader, opts ...signature.SignOption) ([]byte, error) {
 	ctx := context.Background()
 	var digest []byte
 	var signerOpts crypto.SignerOpts
+	var checkKeyState, verifyAfterSign bool
 	var err error
 
 	signerOpts, err = g.client.getHashFunc()
@@ -84,6 +94,14 @@ func (g *SignerVerifier) SignMessage(message io.Reader, opts ...signature.SignOp
 		opt.ApplyContext(&ctx)
 		opt.ApplyDigest(&digest)
 		opt.ApplyCryptoSignerOpts(&signerOpts)
+		opt.ApplyKeyStateCheck(&checkKeyState)
+		opt.ApplyVerifyAfterSign(&verifyAfterSign)
+	}
+
+	if checkKeyState {
+		if err := g.client.checkKeyState(); err != nil {
+			return nil, err
+		}
 	}
 
 	digest, hf, err := signature.ComputeDigestForSigning(message, signerOpts.HashFunc(), gcpSupportedHashFuncs, opts...)
@@ -97,7 +115,16 @@ func (g *SignerVerifier) SignMessage(message io.Reader, opts ...signature.SignOp
 		return nil, err
 	}
 
-	return g.client.sign(ctx, digest, hf, crc32cHasher.Sum32())
+	sig, err := g.client.sign(ctx, digest, hf, crc32cHasher.Sum32())
+	if err != nil {
+		return nil, err
+	}
+	if verifyAfterSign {
+		if err := g.client.verify(bytes.NewReader(sig), nil, options.WithDigest(digest), options.WithCryptoSignerOpts(hf)); err != nil {
+			return nil, fmt.Errorf("%w: %w", sigkms.ErrSignatureVerificationFailed, err)
+		}
+	}
+	return sig, nil
 }
 
 // PublicKey returns the public key that can be used to verify signatures created by
@@ -124,14 +151,46 @@ func (g *SignerVerifier) PublicKey(opts ...signature.PublicKeyOption) (crypto.Pu
 //
 // - WithDigest()
 //
+// - WithRemoteVerification()
+//
 // All other options are ignored if specified.
+//
+// GCP KMS does not offer remote verification of asymmetric signatures, so requesting
+// it returns kms.ErrRemoteVerificationUnsupported rather than silently verifying locally.
 func (g *SignerVerifier) VerifySignature(signature, message io.Reader, opts ...signature.VerifyOption) error {
+	var remoteVerification bool
+	for _, opt := range opts {
+		opt.ApplyRemoteVerification(&remoteVerification)
+	}
+	if remoteVerification {
+		return sigkms.ErrRemoteVerificationUnsupported
+	}
+
 	return g.client.verify(signature, message, opts...)
 }
 
 // CreateKey attempts to create a new key in Vault with the specified algorithm.
-func (g *SignerVerifier) CreateKey(ctx context.Context, algorithm string) (crypto.PublicKey, error) {
-	return g.client.createKey(ctx, algorithm)
+//
+// GCP KMS has no validate-only key creation call, so WithDryRun(true) returns
+// kms.ErrDryRunUnsupported without creating anything.
+//
+// The following parameters in the "gcp" namespace are recognized from WithKeyParameters():
+//
+// - gcp.protectionLevel: the protection level of the key, e.g. "SOFTWARE" (default) or "HSM"
+func (g *SignerVerifier) CreateKey(ctx context.Context, algorithm string, opts ...signature.CreateKeyOption) (crypto.PublicKey, error) {
+	var dryRun bool
+	var params map[string]string
+	for _, opt := range opts {
+		opt.ApplyDryRun(&dryRun)
+		opt.ApplyKeyParameters(&params)
+	}
+	if dryRun {
+		return nil, sigkms.ErrDryRunUnsupported
+	}
+	if algorithm == "" {
+		algorithm = g.defaultAlgorithm
+	}
+	return g.client.createKey(ctx, algorithm, sigkms.KeyParameters(KeyParameterNamespace, params))
 }
 
 type cryptoSignerWrapper struct {
@@ -189,7 +248,34 @@ func (g *SignerVerifier) SupportedAlgorithms() (result []string) {
 	return
 }
 
-// DefaultAlgorithm returns the default algorithm for the GCP KMS service
+// DefaultAlgorithm returns the default algorithm for the GCP KMS service, or the algorithm
+// given by options.WithDefaultAlgorithm when loaded through kms.Get
 func (g *SignerVerifier) DefaultAlgorithm() string {
+	if g.defaultAlgorithm != "" {
+		return g.defaultAlgorithm
+	}
 	return AlgorithmECDSAP256SHA256
 }
+
+// LatencyClass reports that operations are network-bound calls to the GCP KMS service
+func (*SignerVerifier) LatencyClass() signature.LatencyClass {
+	return signature.LatencyClassRemote
+}
+
+// GetKeyMetadata returns the creation time, state and algorithm of the key version in use,
+// along with the labels of the key, from GCP KMS.
+func (g *SignerVerifier) GetKeyMetadata(ctx context.Context) (*sigkms.KeyMetadata, error) {
+	return g.client.keyMetadata(ctx)
+}
+
+// Preflight fetches the key version and key from GCP KMS, returning a *sigkms.AuthError if
+// the credentials are rejected or access to the key is denied.
+func (g *SignerVerifier) Preflight(ctx context.Context) error {
+	if _, err := g.client.keyMetadata(ctx); err != nil {
+		if isAuthError(err) {
+			return &sigkms.AuthError{Err: err}
+		}
+		return err
+	}
+	return nil
+}
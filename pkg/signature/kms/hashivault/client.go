@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -40,7 +41,16 @@ import (
 
 func init() {
 	sigkms.AddProvider(ReferenceScheme, func(_ context.Context, keyResourceID string, hashFunc crypto.Hash, opts ...signature.RPCOption) (sigkms.SignerVerifier, error) {
-		return LoadSignerVerifier(keyResourceID, hashFunc, opts...)
+		algorithm, err := sigkms.DefaultAlgorithmOption(hvSupportedAlgorithms, opts...)
+		if err != nil {
+			return nil, err
+		}
+		sv, err := LoadSignerVerifier(keyResourceID, hashFunc, opts...)
+		if err != nil {
+			return nil, err
+		}
+		sv.defaultAlgorithm = algorithm
+		return sv, nil
 	})
 }
 
@@ -187,7 +197,7 @@ func (h *hashivaultClient) fetchPublicKey(_ context.Context) (crypto.PublicKey,
 
 	keyResult, err := client.Read(path)
 	if err != nil {
-		return nil, fmt.Errorf("public key: %w", err)
+		return nil, fmt.Errorf("public key: %w", classifyError(err))
 	}
 
 	if keyResult == nil {
@@ -233,6 +243,35 @@ func (h *hashivaultClient) fetchPublicKey(_ context.Context) (crypto.PublicKey,
 	return cryptoutils.UnmarshalPEMToPublicKey([]byte(strPublicKeyPem))
 }
 
+func (h *hashivaultClient) keyMetadata(ctx context.Context) (*sigkms.KeyMetadata, error) {
+	path := fmt.Sprintf("/%s/keys/%s", h.transitSecretEnginePath, h.keyPath)
+
+	keyResult, err := h.client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("key metadata: %w", classifyError(err))
+	}
+	if keyResult == nil {
+		return nil, fmt.Errorf("could not read data from transit key path: %s", path)
+	}
+
+	md := &sigkms.KeyMetadata{}
+	if keyType, ok := keyResult.Data["type"].(string); ok {
+		md.Algorithm = keyType
+	}
+
+	// the creation time is reported per key version; use that of the latest version
+	keys, _ := keyResult.Data["keys"].(map[string]interface{})
+	latestVersion, _ := keyResult.Data["latest_version"].(json.Number)
+	if keyMap, ok := keys[string(latestVersion)].(map[string]interface{}); ok {
+		if creationTime, ok := keyMap["creation_time"].(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, creationTime); err == nil {
+				md.CreatedAt = t
+			}
+		}
+	}
+	return md, nil
+}
+
 func (h *hashivaultClient) public() (crypto.PublicKey, error) {
 	var lerr error
 	loader := ttlcache.LoaderFunc[string, crypto.PublicKey](
@@ -263,10 +302,11 @@ func (h hashivaultClient) sign(digest []byte, alg crypto.Hash, opts ...signature
 	client := h.client.Logical()
 
 	keyVersion := fmt.Sprintf("%d", h.keyVersion)
-	var keyVersionUsedPtr *string
+	var keyVersionUsedPtr, requestIDPtr *string
 	for _, opt := range opts {
 		opt.ApplyKeyVersion(&keyVersion)
 		opt.ApplyKeyVersionUsed(&keyVersionUsedPtr)
+		opt.ApplyBackendRequestID(&requestIDPtr)
 	}
 
 	if keyVersion != "" {
@@ -282,7 +322,11 @@ func (h hashivaultClient) sign(digest []byte, alg crypto.Hash, opts ...signature
 		"signature_algorithm": "pkcs1v15",
 	})
 	if err != nil {
-		return nil, fmt.Errorf("transit: failed to sign payload: %w", err)
+		return nil, fmt.Errorf("transit: failed to sign payload: %w", classifyError(err))
+	}
+
+	if requestIDPtr != nil && signResult.RequestID != "" {
+		*requestIDPtr = signResult.RequestID
 	}
 
 	encodedSignature, ok := signResult.Data["signature"]
@@ -330,7 +374,7 @@ func (h hashivaultClient) verify(sig, digest []byte, alg crypto.Hash, opts ...si
 		"signature": fmt.Sprintf("%s%s", vaultDataPrefix, encodedSig),
 	})
 	if err != nil {
-		return fmt.Errorf("verify: %w", err)
+		return fmt.Errorf("verify: %w", classifyError(err))
 	}
 
 	valid, ok := result.Data["valid"]
@@ -380,13 +424,44 @@ func hashString(h crypto.Hash) string {
 	return hashStr
 }
 
-func (h hashivaultClient) createKey(typeStr string) (crypto.PublicKey, error) {
+// KeyParameterNamespace is the namespace of CreateKey parameters recognized by Hashicorp Vault
+const KeyParameterNamespace = "hashivault"
+
+func (h hashivaultClient) createKey(typeStr string, params map[string]string) (crypto.PublicKey, error) {
 	client := h.client.Logical()
 
-	if _, err := client.Write(fmt.Sprintf("/%s/keys/%s", h.transitSecretEnginePath, h.keyPath), map[string]interface{}{
+	data := map[string]interface{}{
 		"type": typeStr,
-	}); err != nil {
-		return nil, fmt.Errorf("failed to create transit key: %w", err)
+	}
+	for name, value := range params {
+		if name == "type" {
+			return nil, fmt.Errorf("key parameter %s.type conflicts with the algorithm", KeyParameterNamespace)
+		}
+		data[name] = value
+	}
+	if _, err := client.Write(fmt.Sprintf("/%s/keys/%s", h.transitSecretEnginePath, h.keyPath), data); err != nil {
+		return nil, fmt.Errorf("failed to create transit key: %w", classifyError(err))
 	}
 	return h.public()
 }
+
+// isAuthError returns true if err was caused by Vault rejecting the token or denying access to the key
+func isAuthError(err error) bool {
+	var respErr *vault.ResponseError
+	return errors.As(err, &respErr) &&
+		(respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden)
+}
+
+// errorKind maps a Vault API error to a sigkms.ErrorKind by its HTTP status code
+func errorKind(err error) sigkms.ErrorKind {
+	var respErr *vault.ResponseError
+	if errors.As(err, &respErr) {
+		return sigkms.ClassifyHTTPStatus(respErr.StatusCode)
+	}
+	return sigkms.ErrorKindPermanent
+}
+
+// classifyError wraps err returned by the Vault API in a *sigkms.ClassifiedError
+func classifyError(err error) error {
+	return sigkms.Classify(err, errorKind)
+}
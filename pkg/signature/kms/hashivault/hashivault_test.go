@@ -15,7 +15,17 @@
 
 package hashivault
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	vault "github.com/hashicorp/vault/api"
+
+	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
+)
 
 func TestParseReference(t *testing.T) {
 	tests := []struct {
@@ -54,3 +64,39 @@ func TestParseReference(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorClassification(t *testing.T) {
+	responseErr := func(code int, msg string) error {
+		return &vault.ResponseError{
+			HTTPMethod: http.MethodPost,
+			URL:        "https://vault.example.com/v1/transit/sign/cosign",
+			StatusCode: code,
+			Errors:     []string{msg},
+		}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want sigkms.ErrorKind
+	}{
+		{"rate limited", responseErr(http.StatusTooManyRequests, "request path \"transit/sign/cosign\": rate limit quota exceeded"), sigkms.ErrorKindThrottled},
+		{"permission denied", responseErr(http.StatusForbidden, "permission denied"), sigkms.ErrorKindAuth},
+		{"missing token", responseErr(http.StatusUnauthorized, "missing client token"), sigkms.ErrorKindAuth},
+		{"not found", responseErr(http.StatusNotFound, "encryption key not found"), sigkms.ErrorKindNotFound},
+		{"sealed", responseErr(http.StatusServiceUnavailable, "Vault is sealed"), sigkms.ErrorKindTransient},
+		{"bad request", responseErr(http.StatusBadRequest, "signing key type ed25519 does not support prehashed input"), sigkms.ErrorKindPermanent},
+		{"deadline", context.DeadlineExceeded, sigkms.ErrorKindTransient},
+		{"unknown", errors.New("unexpected"), sigkms.ErrorKindPermanent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("transit: failed to sign payload: %w", classifyError(tt.err))
+			if got := sigkms.KindOf(err); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected classified error to wrap %v", tt.err)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"strconv"
 
 	"github.com/sigstore/sigstore/pkg/signature"
+	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
 	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
@@ -61,6 +62,8 @@ var hvSupportedHashFuncs = []crypto.Hash{
 type SignerVerifier struct {
 	hashFunc crypto.Hash
 	client   *hashivaultClient
+	// defaultAlgorithm overrides the default algorithm if set by options.WithDefaultAlgorithm
+	defaultAlgorithm string
 }
 
 // LoadSignerVerifier generates signatures using the specified key object in Vault and hash algorithm.
@@ -116,6 +119,8 @@ func LoadSignerVerifier(referenceStr string, hashFunc crypto.Hash, opts ...signa
 //
 // - WithDigest()
 //
+// - ReturnBackendRequestID()
+//
 // All other options are ignored if specified.
 func (h SignerVerifier) SignMessage(message io.Reader, opts ...signature.SignOption) ([]byte, error) {
 	var digest []byte
@@ -152,7 +157,8 @@ func (h SignerVerifier) PublicKey(_ ...signature.PublicKeyOption) (crypto.Public
 //
 // - WithCryptoSignerOpts()
 //
-// All other options are ignored if specified.
+// All other options are ignored if specified. Signatures are always verified remotely by
+// Vault, so WithRemoteVerification() is honored implicitly.
 func (h SignerVerifier) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
 	var digest []byte
 	var signerOpts crypto.SignerOpts = h.hashFunc
@@ -176,8 +182,26 @@ func (h SignerVerifier) VerifySignature(sig, message io.Reader, opts ...signatur
 }
 
 // CreateKey attempts to create a new key in Vault with the specified algorithm.
-func (h SignerVerifier) CreateKey(_ context.Context, algorithm string) (crypto.PublicKey, error) {
-	return h.client.createKey(algorithm)
+//
+// Vault's transit engine has no validate-only key creation call, so WithDryRun(true)
+// returns kms.ErrDryRunUnsupported without creating anything.
+//
+// Parameters in the "hashivault" namespace from WithKeyParameters() are passed through to
+// the transit key creation request, e.g. "hashivault.exportable" or "hashivault.auto_rotate_period".
+func (h SignerVerifier) CreateKey(_ context.Context, algorithm string, opts ...signature.CreateKeyOption) (crypto.PublicKey, error) {
+	var dryRun bool
+	var params map[string]string
+	for _, opt := range opts {
+		opt.ApplyDryRun(&dryRun)
+		opt.ApplyKeyParameters(&params)
+	}
+	if dryRun {
+		return nil, sigkms.ErrDryRunUnsupported
+	}
+	if algorithm == "" {
+		algorithm = h.defaultAlgorithm
+	}
+	return h.client.createKey(algorithm, sigkms.KeyParameters(KeyParameterNamespace, params))
 }
 
 type cryptoSignerWrapper struct {
@@ -227,7 +251,34 @@ func (h *SignerVerifier) SupportedAlgorithms() []string {
 	return hvSupportedAlgorithms
 }
 
-// DefaultAlgorithm returns the default algorithm for the Hashicorp Vault service
+// DefaultAlgorithm returns the default algorithm for the Hashicorp Vault service, or the
+// algorithm given by options.WithDefaultAlgorithm when loaded through kms.Get
 func (h *SignerVerifier) DefaultAlgorithm() string {
+	if h.defaultAlgorithm != "" {
+		return h.defaultAlgorithm
+	}
 	return AlgorithmECDSAP256
 }
+
+// LatencyClass reports that operations are network-bound calls to the Hashicorp Vault service
+func (*SignerVerifier) LatencyClass() signature.LatencyClass {
+	return signature.LatencyClassRemote
+}
+
+// GetKeyMetadata returns the type of the transit key and the creation time of its latest
+// version from Hashicorp Vault. Vault does not expose a key state or labels.
+func (h *SignerVerifier) GetKeyMetadata(ctx context.Context) (*sigkms.KeyMetadata, error) {
+	return h.client.keyMetadata(ctx)
+}
+
+// Preflight reads the transit key from Hashicorp Vault, returning a *sigkms.AuthError if the
+// token is rejected or access to the key is denied.
+func (h *SignerVerifier) Preflight(ctx context.Context) error {
+	if _, err := h.client.keyMetadata(ctx); err != nil {
+		if isAuthError(err) {
+			return &sigkms.AuthError{Err: err}
+		}
+		return err
+	}
+	return nil
+}
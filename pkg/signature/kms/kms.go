@@ -19,8 +19,11 @@ package kms
 import (
 	"context"
 	"crypto"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sigstore/sigstore/pkg/signature"
 )
@@ -34,33 +37,156 @@ func (e *ProviderNotFoundError) Error() string {
 	return fmt.Sprintf("no kms provider found for key reference: %s", e.ref)
 }
 
+// ErrRemoteVerificationUnsupported is returned by a KMS SignerVerifier when remote
+// verification is requested via options.WithRemoteVerification(true) but the
+// backing service is unable to verify signatures itself
+var ErrRemoteVerificationUnsupported = errors.New("remote verification is not supported by this KMS provider")
+
+// ErrKeyNotUsable is returned by a KMS SignerVerifier when options.WithKeyStateCheck(true)
+// is given and the key is disabled, scheduled for deletion or otherwise unable to sign
+var ErrKeyNotUsable = errors.New("key is not usable for signing")
+
+// ErrSignatureVerificationFailed is returned by a KMS SignerVerifier when options.WithVerifyAfterSign(true)
+// is given and the signature returned by the backend does not verify against the key's public key
+var ErrSignatureVerificationFailed = errors.New("signature returned by KMS failed local verification")
+
+// ErrDryRunUnsupported is returned by CreateKey when options.WithDryRun(true) is given
+// but the backing service has no way to validate a key creation request without
+// performing it
+var ErrDryRunUnsupported = errors.New("dry-run key creation is not supported by this KMS provider")
+
+// AuthError is returned by Preflight when the KMS provider rejects the configured
+// credentials or denies access to the key
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("kms authentication or authorization failed: %v", e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// RequestIDError is returned by a KMS SignerVerifier when a request to the backing service
+// fails and the service reported an ID for the request, which can be quoted to the
+// provider's support
+type RequestIDError struct {
+	RequestID string
+	Err       error
+}
+
+func (e *RequestIDError) Error() string {
+	return fmt.Sprintf("%v (request ID: %s)", e.Err, e.RequestID)
+}
+
+func (e *RequestIDError) Unwrap() error {
+	return e.Err
+}
+
+// KeyParameters returns the CreateKey parameters (see options.WithKeyParameters) in the given
+// provider namespace, with the "<namespace>." prefix removed from their names
+func KeyParameters(namespace string, params map[string]string) map[string]string {
+	prefix := namespace + "."
+	result := map[string]string{}
+	for k, v := range params {
+		if name, ok := strings.CutPrefix(k, prefix); ok {
+			result[name] = v
+		}
+	}
+	return result
+}
+
 // ProviderInit is a function that initializes provider-specific SignerVerifier.
 //
 // It takes a provider-specific resource ID and hash function, and returns a
 // SignerVerifier using that resource, or any error that was encountered.
 type ProviderInit func(context.Context, string, crypto.Hash, ...signature.RPCOption) (SignerVerifier, error)
 
-// AddProvider adds the provider implementation into the local cache
+// AddProvider adds the provider implementation into the local cache, replacing any provider
+// previously added for the same reference scheme
 func AddProvider(keyResourceID string, init ProviderInit) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
 	providersMap[keyResourceID] = init
 }
 
-var providersMap = map[string]ProviderInit{}
+// ErrProviderAlreadyRegistered is returned by RegisterKMSProvider when a provider has already
+// been added for the given reference scheme
+var ErrProviderAlreadyRegistered = errors.New("kms provider already registered for scheme")
+
+// RegisterKMSProvider adds a provider for key resource IDs beginning with scheme (e.g.
+// "examplekms://"), so that Get can construct SignerVerifiers for custom schemes in the same way
+// as for the built-in providers. Unlike AddProvider, it returns ErrProviderAlreadyRegistered
+// rather than replacing an existing provider for the same scheme.
+func RegisterKMSProvider(scheme string, init ProviderInit) error {
+	if scheme == "" {
+		return errors.New("scheme cannot be empty")
+	}
+	if init == nil {
+		return errors.New("provider init function cannot be nil")
+	}
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if _, ok := providersMap[scheme]; ok {
+		return fmt.Errorf("%w: %s", ErrProviderAlreadyRegistered, scheme)
+	}
+	providersMap[scheme] = init
+	return nil
+}
+
+var (
+	providersMu  sync.RWMutex
+	providersMap = map[string]ProviderInit{}
+)
 
 // Get returns a KMS SignerVerifier for the given resource string and hash function.
 // If no matching provider is found, Get returns a ProviderNotFoundError. It
 // also returns an error if initializing the SignerVerifier fails.
+//
+// If options.WithDefaultAlgorithm() is given, the algorithm must be one of the
+// SignerVerifier's SupportedAlgorithms, and the returned SignerVerifier reports it
+// from DefaultAlgorithm and uses it when CreateKey is called without an algorithm.
+// Get returns an error if the provider does not honor the option.
 func Get(ctx context.Context, keyResourceID string, hashFunc crypto.Hash, opts ...signature.RPCOption) (SignerVerifier, error) {
+	var defaultAlgorithm string
+	for _, opt := range opts {
+		opt.ApplyDefaultAlgorithm(&defaultAlgorithm)
+	}
+	pi, ok := providerFor(keyResourceID)
+	if !ok {
+		return nil, &ProviderNotFoundError{ref: keyResourceID}
+	}
+	sv, err := pi(ctx, keyResourceID, hashFunc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if defaultAlgorithm != "" && sv.DefaultAlgorithm() != defaultAlgorithm {
+		return nil, fmt.Errorf("kms provider for %s does not support overriding the default algorithm", keyResourceID)
+	}
+	return sv, nil
+}
+
+// providerFor returns the provider whose reference scheme is a prefix of keyResourceID,
+// preferring the longest match so that a registered scheme is never shadowed by a shorter one
+func providerFor(keyResourceID string) (ProviderInit, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	var match string
+	var init ProviderInit
 	for ref, pi := range providersMap {
-		if strings.HasPrefix(keyResourceID, ref) {
-			return pi(ctx, keyResourceID, hashFunc, opts...)
+		if strings.HasPrefix(keyResourceID, ref) && (init == nil || len(ref) > len(match)) {
+			match, init = ref, pi
 		}
 	}
-	return nil, &ProviderNotFoundError{ref: keyResourceID}
+	return init, init != nil
 }
 
 // SupportedProviders returns list of initialized providers
 func SupportedProviders() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
 	keys := make([]string, 0, len(providersMap))
 	for key := range providersMap {
 		keys = append(keys, key)
@@ -71,8 +197,26 @@ func SupportedProviders() []string {
 // SignerVerifier creates and verifies digital signatures over a message using a KMS service
 type SignerVerifier interface {
 	signature.SignerVerifier
-	CreateKey(ctx context.Context, algorithm string) (crypto.PublicKey, error)
+	CreateKey(ctx context.Context, algorithm string, opts ...signature.CreateKeyOption) (crypto.PublicKey, error)
 	CryptoSigner(ctx context.Context, errFunc func(error)) (crypto.Signer, crypto.SignerOpts, error)
 	SupportedAlgorithms() []string
 	DefaultAlgorithm() string
+	GetKeyMetadata(ctx context.Context) (*KeyMetadata, error)
+	// Preflight checks that the configured credentials are valid and that the key is
+	// accessible, without signing anything. It returns an *AuthError if the provider
+	// rejects the credentials or denies access to the key.
+	Preflight(ctx context.Context) error
+}
+
+// KeyMetadata describes the key (or key version) used by a SignerVerifier, as reported by the
+// KMS provider. Fields that the provider does not expose are left empty.
+type KeyMetadata struct {
+	// CreatedAt is when the key or key version was created
+	CreatedAt time.Time
+	// State is the provider-specific state of the key, e.g. "Enabled" or "PendingDeletion"
+	State string
+	// Algorithm is the key algorithm, using the same names as SupportedAlgorithms where possible
+	Algorithm string
+	// Labels are the provider-specific labels or tags attached to the key
+	Labels map[string]string
 }
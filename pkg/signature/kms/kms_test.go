@@ -0,0 +1,77 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+func TestRegisterKMSProvider(t *testing.T) {
+	errInit := errors.New("init called")
+	var gotRef string
+	init := func(_ context.Context, keyResourceID string, _ crypto.Hash, _ ...signature.RPCOption) (SignerVerifier, error) {
+		gotRef = keyResourceID
+		return nil, errInit
+	}
+	const scheme = "testregisterkms://"
+	t.Cleanup(func() {
+		providersMu.Lock()
+		defer providersMu.Unlock()
+		delete(providersMap, scheme)
+		delete(providersMap, scheme+"special/")
+	})
+
+	if err := RegisterKMSProvider(scheme, init); err != nil {
+		t.Fatalf("unexpected error registering provider: %v", err)
+	}
+	if !slices.Contains(SupportedProviders(), scheme) {
+		t.Errorf("expected %s in supported providers", scheme)
+	}
+	if _, err := Get(context.Background(), scheme+"key", crypto.SHA256); !errors.Is(err, errInit) || gotRef != scheme+"key" {
+		t.Errorf("expected registered provider to be called with the key reference, got %q, %v", gotRef, err)
+	}
+	if err := RegisterKMSProvider(scheme, init); !errors.Is(err, ErrProviderAlreadyRegistered) {
+		t.Errorf("expected ErrProviderAlreadyRegistered for duplicate scheme, got %v", err)
+	}
+
+	// a longer scheme takes precedence over a shorter one that is also a prefix
+	errSpecial := errors.New("special init called")
+	if err := RegisterKMSProvider(scheme+"special/", func(context.Context, string, crypto.Hash, ...signature.RPCOption) (SignerVerifier, error) {
+		return nil, errSpecial
+	}); err != nil {
+		t.Fatalf("unexpected error registering provider: %v", err)
+	}
+	if _, err := Get(context.Background(), scheme+"special/key", crypto.SHA256); !errors.Is(err, errSpecial) {
+		t.Errorf("expected longest matching scheme to be used, got %v", err)
+	}
+
+	if err := RegisterKMSProvider("", init); err == nil {
+		t.Error("expected error registering empty scheme")
+	}
+	if err := RegisterKMSProvider("othertestkms://", nil); err == nil {
+		t.Error("expected error registering nil init function")
+	}
+	var notFound *ProviderNotFoundError
+	if _, err := Get(context.Background(), "unregistered://key", crypto.SHA256); !errors.As(err, &notFound) {
+		t.Errorf("expected ProviderNotFoundError, got %v", err)
+	}
+}
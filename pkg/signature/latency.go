@@ -0,0 +1,59 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+// LatencyClass is a coarse, self-reported estimate of the per-operation latency of a
+// Signer or Verifier. It is advisory only and is intended to help callers choose
+// concurrency and batching strategies without benchmarking.
+type LatencyClass int
+
+const (
+	// LatencyClassLocal indicates that operations are performed in-process with key material held in memory
+	LatencyClassLocal LatencyClass = iota
+	// LatencyClassRemote indicates that operations are network-bound, e.g. performed by a remote KMS service
+	LatencyClassRemote
+	// LatencyClassPlugin indicates that operations require spawning an external process
+	LatencyClassPlugin
+)
+
+// String returns a human-readable name for the latency class
+func (l LatencyClass) String() string {
+	switch l {
+	case LatencyClassLocal:
+		return "local"
+	case LatencyClassRemote:
+		return "remote"
+	case LatencyClassPlugin:
+		return "plugin"
+	default:
+		return "unknown"
+	}
+}
+
+// LatencyClassifier is implemented by signers and verifiers that can report their LatencyClass
+type LatencyClassifier interface {
+	LatencyClass() LatencyClass
+}
+
+// GetLatencyClass returns the LatencyClass reported by v if it implements LatencyClassifier.
+// Otherwise LatencyClassLocal is returned, as the in-memory implementations in this package
+// do not perform any I/O.
+func GetLatencyClass(v interface{}) LatencyClass {
+	if lc, ok := v.(LatencyClassifier); ok {
+		return lc.LatencyClass()
+	}
+	return LatencyClassLocal
+}
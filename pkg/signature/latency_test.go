@@ -0,0 +1,42 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import "testing"
+
+type remoteVerifier struct {
+	*ECDSAVerifier
+}
+
+func (remoteVerifier) LatencyClass() LatencyClass {
+	return LatencyClassRemote
+}
+
+func TestGetLatencyClass(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	if got := GetLatencyClass(sv); got != LatencyClassLocal {
+		t.Errorf("expected %v for in-memory signer, got %v", LatencyClassLocal, got)
+	}
+	if got := GetLatencyClass(remoteVerifier{sv.ECDSAVerifier}); got != LatencyClassRemote {
+		t.Errorf("expected %v for self-reporting verifier, got %v", LatencyClassRemote, got)
+	}
+	if got := LatencyClass(42).String(); got != "unknown" {
+		t.Errorf("expected unknown for out of range class, got %q", got)
+	}
+}
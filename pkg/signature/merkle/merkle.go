@@ -0,0 +1,220 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merkle
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+
+	rootHeader = "sigstore merkle root v1"
+)
+
+// Tree is a Merkle tree over fixed-size chunks of data
+type Tree struct {
+	hashFunc  crypto.Hash
+	chunkSize int
+	leaves    [][]byte
+}
+
+// Build reads r to EOF, splitting it into chunks of chunkSize bytes (the last chunk may be
+// shorter) and computing a Merkle tree over them using hashFunc
+func Build(r io.Reader, chunkSize int, hashFunc crypto.Hash) (*Tree, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("invalid chunk size: %d", chunkSize)
+	}
+	if !hashFunc.Available() {
+		return nil, fmt.Errorf("hash function %v is not available", hashFunc)
+	}
+	t := &Tree{hashFunc: hashFunc, chunkSize: chunkSize}
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			t.leaves = append(t.leaves, leafHash(hashFunc, buf[:n]))
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return t, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Size returns the number of chunks in the tree
+func (t *Tree) Size() uint64 {
+	return uint64(len(t.leaves))
+}
+
+// Root returns the root of the tree, along with the parameters used to build it
+func (t *Tree) Root() *Root {
+	return &Root{
+		HashFunc:  t.hashFunc,
+		ChunkSize: t.chunkSize,
+		TreeSize:  t.Size(),
+		Hash:      subtreeHash(t.hashFunc, t.leaves),
+	}
+}
+
+// InclusionProof returns the audit path for the chunk at index, as defined in RFC 6962 section 2.1.1
+func (t *Tree) InclusionProof(index uint64) ([][]byte, error) {
+	if index >= t.Size() {
+		return nil, fmt.Errorf("chunk index %d out of range for tree of size %d", index, t.Size())
+	}
+	return path(t.hashFunc, index, t.leaves), nil
+}
+
+func path(hashFunc crypto.Hash, index uint64, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := splitPoint(uint64(len(leaves)))
+	if index < k {
+		return append(path(hashFunc, index, leaves[:k]), subtreeHash(hashFunc, leaves[k:]))
+	}
+	return append(path(hashFunc, index-k, leaves[k:]), subtreeHash(hashFunc, leaves[:k]))
+}
+
+func subtreeHash(hashFunc crypto.Hash, leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return hashFunc.New().Sum(nil)
+	case 1:
+		return leaves[0]
+	}
+	k := splitPoint(uint64(len(leaves)))
+	return nodeHash(hashFunc, subtreeHash(hashFunc, leaves[:k]), subtreeHash(hashFunc, leaves[k:]))
+}
+
+// splitPoint returns the largest power of two smaller than n, for n > 1
+func splitPoint(n uint64) uint64 {
+	return 1 << (bits.Len64(n-1) - 1)
+}
+
+func leafHash(hashFunc crypto.Hash, chunk []byte) []byte {
+	h := hashFunc.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(chunk)
+	return h.Sum(nil)
+}
+
+func nodeHash(hashFunc crypto.Hash, left, right []byte) []byte {
+	h := hashFunc.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Root is the root hash of a Tree, along with the parameters needed to verify chunks against it
+type Root struct {
+	HashFunc  crypto.Hash
+	ChunkSize int
+	TreeSize  uint64
+	Hash      []byte
+}
+
+// MarshalText returns the canonical encoding of the root that is signed by SignRoot, which binds
+// the root hash to the hash function, chunk size and tree size used to compute it
+func (r *Root) MarshalText() ([]byte, error) {
+	if !r.HashFunc.Available() {
+		return nil, fmt.Errorf("hash function %v is not available", r.HashFunc)
+	}
+	if len(r.Hash) != r.HashFunc.Size() {
+		return nil, fmt.Errorf("root hash length %d does not match %v", len(r.Hash), r.HashFunc)
+	}
+	return []byte(fmt.Sprintf("%s\n%s\n%d\n%d\n%s\n", rootHeader, r.HashFunc, r.ChunkSize, r.TreeSize,
+		base64.StdEncoding.EncodeToString(r.Hash))), nil
+}
+
+// SignRoot signs the canonical encoding of root with s
+func SignRoot(s signature.Signer, root *Root, opts ...signature.SignOption) ([]byte, error) {
+	msg, err := root.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return s.SignMessage(bytes.NewReader(msg), opts...)
+}
+
+// VerifyRoot verifies sig over the canonical encoding of root with v
+func VerifyRoot(v signature.Verifier, root *Root, sig []byte, opts ...signature.VerifyOption) error {
+	msg, err := root.MarshalText()
+	if err != nil {
+		return err
+	}
+	return v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg), opts...)
+}
+
+// VerifyChunk verifies sig over root with v, then verifies that chunk is the chunk at index in
+// the tree described by root using the inclusion proof
+func VerifyChunk(v signature.Verifier, root *Root, sig []byte, index uint64, chunk []byte, proof [][]byte, opts ...signature.VerifyOption) error {
+	if err := VerifyRoot(v, root, sig, opts...); err != nil {
+		return err
+	}
+	return VerifyInclusion(root, index, chunk, proof)
+}
+
+// VerifyInclusion verifies that chunk is the chunk at index in the tree described by root using
+// the inclusion proof, following RFC 9162 section 2.1.3.2. It does not check any signature over root.
+func VerifyInclusion(root *Root, index uint64, chunk []byte, proof [][]byte) error {
+	if index >= root.TreeSize {
+		return fmt.Errorf("chunk index %d out of range for tree of size %d", index, root.TreeSize)
+	}
+	if len(chunk) > root.ChunkSize || (len(chunk) < root.ChunkSize && index != root.TreeSize-1) {
+		return fmt.Errorf("chunk %d has invalid length %d for chunk size %d", index, len(chunk), root.ChunkSize)
+	}
+	if !root.HashFunc.Available() {
+		return fmt.Errorf("hash function %v is not available", root.HashFunc)
+	}
+
+	fn, sn := index, root.TreeSize-1
+	r := leafHash(root.HashFunc, chunk)
+	for _, p := range proof {
+		if sn == 0 {
+			return errors.New("inclusion proof is too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(root.HashFunc, p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(root.HashFunc, r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return errors.New("inclusion proof is too short")
+	}
+	if !bytes.Equal(r, root.Hash) {
+		return errors.New("inclusion proof does not match root hash")
+	}
+	return nil
+}
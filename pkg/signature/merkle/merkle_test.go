@@ -0,0 +1,139 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merkle
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+func TestTreeRoot(t *testing.T) {
+	data := []byte("aaaabbbbcc")
+	tree, err := Build(bytes.NewReader(data), 4, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Size() != 3 {
+		t.Fatalf("expected 3 chunks, got %d", tree.Size())
+	}
+
+	l0 := leafHash(crypto.SHA256, []byte("aaaa"))
+	l1 := leafHash(crypto.SHA256, []byte("bbbb"))
+	l2 := leafHash(crypto.SHA256, []byte("cc"))
+	want := nodeHash(crypto.SHA256, nodeHash(crypto.SHA256, l0, l1), l2)
+	if root := tree.Root(); !bytes.Equal(root.Hash, want) {
+		t.Errorf("unexpected root hash %x, want %x", root.Hash, want)
+	}
+
+	empty, err := Build(bytes.NewReader(nil), 4, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Size() != 0 || len(empty.Root().Hash) != crypto.SHA256.Size() {
+		t.Errorf("unexpected empty tree: size %d, root %x", empty.Size(), empty.Root().Hash)
+	}
+
+	if _, err := Build(bytes.NewReader(data), 0, crypto.SHA256); err == nil {
+		t.Error("expected error for invalid chunk size")
+	}
+}
+
+func TestInclusionProofs(t *testing.T) {
+	const chunkSize = 3
+	for size := 1; size <= 17; size++ {
+		data := make([]byte, size*chunkSize-1)
+		if _, err := rand.Read(data); err != nil {
+			t.Fatal(err)
+		}
+		tree, err := Build(bytes.NewReader(data), chunkSize, crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root := tree.Root()
+		for i := 0; i < size; i++ {
+			chunk := data[i*chunkSize : min((i+1)*chunkSize, len(data))]
+			proof, err := tree.InclusionProof(uint64(i))
+			if err != nil {
+				t.Fatalf("size %d, index %d: unexpected error generating proof: %v", size, i, err)
+			}
+			if err := VerifyInclusion(root, uint64(i), chunk, proof); err != nil {
+				t.Errorf("size %d, index %d: unexpected error verifying proof: %v", size, i, err)
+			}
+
+			tampered := bytes.Clone(chunk)
+			tampered[0] ^= 0xff
+			if err := VerifyInclusion(root, uint64(i), tampered, proof); err == nil {
+				t.Errorf("size %d, index %d: expected error verifying tampered chunk", size, i)
+			}
+			if size > 1 {
+				other := uint64((i + 1) % size)
+				if err := VerifyInclusion(root, other, chunk, proof); err == nil {
+					t.Errorf("size %d, index %d: expected error verifying chunk at wrong index", size, i)
+				}
+				if err := VerifyInclusion(root, uint64(i), chunk, proof[:len(proof)-1]); err == nil {
+					t.Errorf("size %d, index %d: expected error verifying truncated proof", size, i)
+				}
+			}
+		}
+		if _, err := tree.InclusionProof(uint64(size)); err == nil {
+			t.Errorf("size %d: expected error for out of range index", size)
+		}
+	}
+}
+
+func TestSignAndVerifyChunk(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	tree, err := Build(bytes.NewReader(data), 64, crypto.SHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := tree.Root()
+	sig, err := SignRoot(sv, root)
+	if err != nil {
+		t.Fatalf("unexpected error signing root: %v", err)
+	}
+
+	proof, err := tree.InclusionProof(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := data[5*64 : 6*64]
+	if err := VerifyChunk(sv, root, sig, 5, chunk, proof); err != nil {
+		t.Errorf("unexpected error verifying chunk: %v", err)
+	}
+
+	// the signature binds the tree parameters as well as the root hash
+	modified := *root
+	modified.TreeSize++
+	if err := VerifyChunk(sv, &modified, sig, 5, chunk, proof); err == nil {
+		t.Error("expected error verifying chunk against modified root")
+	}
+}
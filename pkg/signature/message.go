@@ -16,11 +16,15 @@
 package signature
 
 import (
+	"bytes"
 	"crypto"
 	crand "crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
 func isSupportedAlg(alg crypto.Hash, supportedAlgs []crypto.Hash) bool {
@@ -41,22 +45,45 @@ func isSupportedAlg(alg crypto.Hash, supportedAlgs []crypto.Hash) bool {
 // digest value will be returned without any further computation
 // - if a hash function is given using WithCryptoSignerOpts(opts) as a SignOption, it will be used (if it is in the supported list)
 // - otherwise defaultHashFunc will be used (if it is in the supported list)
+//
+// If a label is given using WithDomainSeparation(label), it is bound into the digest; this cannot be combined with a precomputed digest.
+// If a decompressor is given using WithDecompressor(d), the message is decompressed as it is hashed.
+//
+// A zero-length message is valid and is hashed like any other (e.g. to the SHA-256 digest of the empty string); only a nil
+// io.Reader is rejected.
 func ComputeDigestForSigning(rawMessage io.Reader, defaultHashFunc crypto.Hash, supportedHashFuncs []crypto.Hash, opts ...SignOption) (digest []byte, hashedWith crypto.Hash, err error) {
 	var cryptoSignerOpts crypto.SignerOpts = defaultHashFunc
+	var label []byte
+	var decompressor options.Decompressor
+	var progress options.ProgressFunc
 	for _, opt := range opts {
 		opt.ApplyDigest(&digest)
+		opt.ApplyDomainSeparation(&label)
+		opt.ApplyDecompressor(&decompressor)
+		opt.ApplyProgress(&progress)
 		opt.ApplyCryptoSignerOpts(&cryptoSignerOpts)
 	}
 	hashedWith = cryptoSignerOpts.HashFunc()
 	if !isSupportedAlg(hashedWith, supportedHashFuncs) {
 		return nil, crypto.Hash(0), fmt.Errorf("unsupported hash algorithm: %q not in %v", hashedWith.String(), supportedHashFuncs)
 	}
+	if FIPSMode() {
+		if err := checkFIPSHash(hashedWith); err != nil {
+			return nil, crypto.Hash(0), err
+		}
+	}
 	if len(digest) > 0 {
+		if label != nil {
+			return nil, crypto.Hash(0), errDomainSeparationWithDigest
+		}
 		if hashedWith != crypto.Hash(0) && len(digest) != hashedWith.Size() {
 			err = errors.New("unexpected length of digest for hash function specified")
 		}
 		return
 	}
+	if rawMessage, err = transformMessage(rawMessage, label, decompressor, progress, 0); err != nil {
+		return nil, crypto.Hash(0), err
+	}
 	digest, err = hashMessage(rawMessage, hashedWith)
 	return
 }
@@ -67,26 +94,157 @@ func ComputeDigestForSigning(rawMessage io.Reader, defaultHashFunc crypto.Hash,
 // digest value will be returned without any further computation
 // - if a hash function is given using WithCryptoSignerOpts(opts) as a SignOption, it will be used (if it is in the supported list)
 // - otherwise defaultHashFunc will be used (if it is in the supported list)
+//
+// If a label is given using WithDomainSeparation(label), it is bound into the digest; this cannot be combined with a precomputed digest.
+// If a decompressor is given using WithDecompressor(d), the message is decompressed as it is hashed.
+// If a limit is given using WithMaxMessageSize(n), a *MessageTooLargeError is returned once more than n bytes of the
+// (decompressed) message have been read.
+//
+// A zero-length message is valid and is hashed like any other (e.g. to the SHA-256 digest of the empty string); only a nil
+// io.Reader is rejected.
+//
+// If the selected hash function is weaker than a minimum given with WithMinimumHash, or is SHA-1 and WithAllowSHA1(false)
+// is given, a *WeakHashError is returned.
 func ComputeDigestForVerifying(rawMessage io.Reader, defaultHashFunc crypto.Hash, supportedHashFuncs []crypto.Hash, opts ...VerifyOption) (digest []byte, hashedWith crypto.Hash, err error) {
 	var cryptoSignerOpts crypto.SignerOpts = defaultHashFunc
+	var label []byte
+	var minimumHash crypto.Hash
+	var allowSHA1 *bool
+	var decompressor options.Decompressor
+	var progress options.ProgressFunc
+	var maxSize int64
 	for _, opt := range opts {
 		opt.ApplyDigest(&digest)
+		opt.ApplyDomainSeparation(&label)
+		opt.ApplyDecompressor(&decompressor)
+		opt.ApplyProgress(&progress)
+		opt.ApplyMaxMessageSize(&maxSize)
 		opt.ApplyCryptoSignerOpts(&cryptoSignerOpts)
+		opt.ApplyMinimumHash(&minimumHash)
+		opt.ApplyAllowSHA1(&allowSHA1)
 	}
 	hashedWith = cryptoSignerOpts.HashFunc()
 	if !isSupportedAlg(hashedWith, supportedHashFuncs) {
 		return nil, crypto.Hash(0), fmt.Errorf("unsupported hash algorithm: %q not in %v", hashedWith.String(), supportedHashFuncs)
 	}
+	if err := checkHashStrength(hashedWith, minimumHash, allowSHA1); err != nil {
+		return nil, crypto.Hash(0), err
+	}
+	if FIPSMode() {
+		if err := checkFIPSHash(hashedWith); err != nil {
+			return nil, crypto.Hash(0), err
+		}
+	}
 	if len(digest) > 0 {
+		if label != nil {
+			return nil, crypto.Hash(0), errDomainSeparationWithDigest
+		}
 		if hashedWith != crypto.Hash(0) && len(digest) != hashedWith.Size() {
 			err = errors.New("unexpected length of digest for hash function specified")
 		}
 		return
 	}
+	if rawMessage, err = transformMessage(rawMessage, label, decompressor, progress, maxSize); err != nil {
+		return nil, crypto.Hash(0), err
+	}
 	digest, err = hashMessage(rawMessage, hashedWith)
 	return
 }
 
+var errDomainSeparationWithDigest = errors.New("domain separation label cannot be applied to a precomputed digest")
+
+// withDomainSeparation prefixes rawMessage with the big-endian uint64 length of label followed by
+// label itself, so that distinct labels can never produce the same byte stream.
+func withDomainSeparation(rawMessage io.Reader, label []byte) io.Reader {
+	if label == nil || rawMessage == nil {
+		return rawMessage
+	}
+	prefix := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(label)), uint64(len(label)))
+	prefix = append(prefix, label...)
+	return io.MultiReader(bytes.NewReader(prefix), rawMessage)
+}
+
+// transformMessage reports progress (if requested) on reading rawMessage, applies the decompressor
+// (if any), caps the result at maxSize bytes (if positive) and then binds the domain separation
+// label (if any) to it.
+func transformMessage(rawMessage io.Reader, label []byte, decompressor options.Decompressor, progress options.ProgressFunc, maxSize int64) (io.Reader, error) {
+	if rawMessage != nil && progress != nil {
+		rawMessage = newProgressReader(rawMessage, progress)
+	}
+	if rawMessage != nil && decompressor != nil {
+		r, err := decompressor(rawMessage)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing message: %w", err)
+		}
+		rawMessage = r
+	}
+	if rawMessage != nil && maxSize > 0 {
+		rawMessage = &maxSizeReader{r: rawMessage, limit: maxSize, remaining: maxSize}
+	}
+	return withDomainSeparation(rawMessage, label), nil
+}
+
+// MessageTooLargeError is returned when a message being verified is longer than the limit set
+// with options.WithMaxMessageSize
+type MessageTooLargeError struct {
+	Limit int64
+}
+
+func (e *MessageTooLargeError) Error() string {
+	return fmt.Sprintf("message exceeds maximum size of %d bytes", e.Limit)
+}
+
+// maxSizeReader fails with a *MessageTooLargeError once more than limit bytes have been read from
+// the underlying reader; it reads at most one byte past the limit
+type maxSizeReader struct {
+	r         io.Reader
+	limit     int64
+	remaining int64
+}
+
+func (m *maxSizeReader) Read(b []byte) (int, error) {
+	if m.remaining < 0 {
+		return 0, &MessageTooLargeError{Limit: m.limit}
+	}
+	if int64(len(b)) > m.remaining+1 {
+		b = b[:m.remaining+1]
+	}
+	n, err := m.r.Read(b)
+	m.remaining -= int64(n)
+	if m.remaining < 0 {
+		return n + int(m.remaining), &MessageTooLargeError{Limit: m.limit}
+	}
+	return n, err
+}
+
+// progressReader invokes a ProgressFunc after each read from the underlying reader
+type progressReader struct {
+	r         io.Reader
+	progress  options.ProgressFunc
+	processed int64
+	total     int64
+}
+
+func newProgressReader(r io.Reader, progress options.ProgressFunc) *progressReader {
+	total := int64(-1)
+	switch sized := r.(type) {
+	case interface{ Len() int }:
+		total = int64(sized.Len())
+	case interface{ Size() int64 }:
+		total = sized.Size()
+	}
+	return &progressReader{r: r, progress: progress, total: total}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.processed += int64(n)
+		p.progress(p.processed, p.total)
+	}
+	return n, err
+}
+
 func hashMessage(rawMessage io.Reader, hashFunc crypto.Hash) ([]byte, error) {
 	if rawMessage == nil {
 		return nil, errors.New("message cannot be nil")
@@ -0,0 +1,86 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// SignatureToVerify pairs a signature with the verifier for the key that is expected to have created it.
+type SignatureToVerify struct {
+	Verifier  Verifier
+	Signature []byte
+}
+
+// VerifyMultiple verifies several signatures over the same message, hashing the message only once
+// with hashFunc and verifying each signature against that shared digest. The returned slice holds
+// one result per entry, in the same order: nil if that signature is valid, otherwise the
+// verification error. The second return value is only non-nil if the message could not be hashed.
+//
+// If concurrency is greater than one, up to that many signatures are verified in parallel.
+// Each verifier must accept a precomputed digest (see options.WithDigest); this holds for the
+// ECDSA, RSA and Ed25519ph verifiers but not for pure Ed25519, which always needs the full message.
+func VerifyMultiple(message io.Reader, hashFunc crypto.Hash, entries []SignatureToVerify, concurrency int, opts ...VerifyOption) ([]error, error) {
+	if !hashFunc.Available() {
+		return nil, fmt.Errorf("unsupported hash algorithm: %q", hashFunc.String())
+	}
+	digest, err := hashMessage(message, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+	verifyOpts := make([]VerifyOption, 0, len(opts)+2)
+	verifyOpts = append(verifyOpts, opts...)
+	verifyOpts = append(verifyOpts, options.WithDigest(digest), options.WithCryptoSignerOpts(hashFunc))
+
+	results := make([]error, len(entries))
+	verify := func(i int) {
+		e := entries[i]
+		if e.Verifier == nil {
+			results[i] = errors.New("nil verifier")
+			return
+		}
+		results[i] = e.Verifier.VerifySignature(bytes.NewReader(e.Signature), nil, verifyOpts...)
+	}
+	if concurrency <= 1 {
+		for i := range entries {
+			verify(i)
+		}
+		return results, nil
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range entries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			verify(i)
+		}(i)
+	}
+	wg.Wait()
+	return results, nil
+}
@@ -0,0 +1,86 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"testing"
+)
+
+func TestVerifyMultiple(t *testing.T) {
+	message := []byte("one message, many signers")
+
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	otherECDSASV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	rsaSV, _, err := NewDefaultRSAPKCS1v15SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	pssSV, _, err := NewRSAPSSSignerVerifier(rand.Reader, 2048, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+
+	sign := func(s Signer, msg []byte) []byte {
+		t.Helper()
+		sig, err := s.SignMessage(bytes.NewReader(msg))
+		if err != nil {
+			t.Fatalf("unexpected error signing: %v", err)
+		}
+		return sig
+	}
+	entries := []SignatureToVerify{
+		{Verifier: ecdsaSV, Signature: sign(ecdsaSV, message)},
+		{Verifier: rsaSV, Signature: sign(rsaSV, message)},
+		// signed by a different key
+		{Verifier: ecdsaSV, Signature: sign(otherECDSASV, message)},
+		{Verifier: pssSV, Signature: sign(pssSV, message)},
+		// signed over a different message
+		{Verifier: rsaSV, Signature: sign(rsaSV, []byte("something else"))},
+		{Verifier: nil, Signature: []byte("sig")},
+	}
+	wantValid := []bool{true, true, false, true, false, false}
+
+	for _, concurrency := range []int{0, 1, 4} {
+		results, err := VerifyMultiple(bytes.NewReader(message), crypto.SHA256, entries, concurrency)
+		if err != nil {
+			t.Fatalf("concurrency %d: unexpected error: %v", concurrency, err)
+		}
+		if len(results) != len(entries) {
+			t.Fatalf("concurrency %d: got %d results, want %d", concurrency, len(results), len(entries))
+		}
+		for i, want := range wantValid {
+			if got := results[i] == nil; got != want {
+				t.Errorf("concurrency %d: entry %d valid = %v, want %v (err: %v)", concurrency, i, got, want, results[i])
+			}
+		}
+	}
+
+	if _, err := VerifyMultiple(nil, crypto.SHA256, entries, 1); err == nil {
+		t.Error("expected error for nil message")
+	}
+	if _, err := VerifyMultiple(bytes.NewReader(message), crypto.Hash(0), entries, 1); err == nil {
+		t.Error("expected error for unavailable hash function")
+	}
+}
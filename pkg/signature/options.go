@@ -30,6 +30,7 @@ type RPCOption interface {
 	ApplyRemoteVerification(*bool)
 	ApplyRPCAuthOpts(opts *options.RPCAuth)
 	ApplyKeyVersion(keyVersion *string)
+	ApplyDefaultAlgorithm(algorithm *string)
 }
 
 // PublicKeyOption specifies options to be used when obtaining a public key
@@ -40,6 +41,9 @@ type PublicKeyOption interface {
 // MessageOption specifies options to be used when processing messages during signing or verification
 type MessageOption interface {
 	ApplyDigest(*[]byte)
+	ApplyDomainSeparation(*[]byte)
+	ApplyDecompressor(*options.Decompressor)
+	ApplyProgress(*options.ProgressFunc)
 	ApplyCryptoSignerOpts(*crypto.SignerOpts)
 }
 
@@ -49,12 +53,28 @@ type SignOption interface {
 	MessageOption
 	ApplyRand(*io.Reader)
 	ApplyKeyVersionUsed(**string)
+	ApplyBackendRequestID(**string)
+	ApplyKeyStateCheck(*bool)
+	ApplyVerifyAfterSign(*bool)
+	ApplyDeterministicSignature(*bool)
 }
 
 // VerifyOption specifies options to be used when verifying a signature
 type VerifyOption interface {
 	RPCOption
 	MessageOption
+	ApplyCertificatePolicy(*options.CertificatePolicy)
+	ApplyMinimumHash(*crypto.Hash)
+	ApplyAllowSHA1(**bool)
+	ApplyKeyID(*string)
+	ApplyMaxMessageSize(*int64)
+}
+
+// CreateKeyOption specifies options to be used when creating a key in a KMS
+type CreateKeyOption interface {
+	RPCOption
+	ApplyDryRun(*bool)
+	ApplyKeyParameters(*map[string]string)
 }
 
 // LoadOption specifies options to be used when creating a Signer/Verifier
@@ -62,4 +82,9 @@ type LoadOption interface {
 	ApplyHash(*crypto.Hash)
 	ApplyED25519ph(*bool)
 	ApplyRSAPSS(**rsa.PSSOptions)
+	ApplyAllowedAlgorithms(*[]string)
+	ApplyKeyAttestation(**options.KeyAttestation)
+	ApplyPublicKeyPin(*string)
+	ApplyStrictED25519(*bool)
+	ApplySkippedFileHandler(*func(error))
 }
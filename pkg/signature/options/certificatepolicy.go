@@ -0,0 +1,40 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import "crypto/x509"
+
+// CertificatePolicy is invoked with the signing certificate after a signature has been
+// cryptographically verified; returning an error rejects the signature.
+type CertificatePolicy func(*x509.Certificate) error
+
+// RequestCertificatePolicy implements the functional option pattern for applying a policy to the signing certificate
+type RequestCertificatePolicy struct {
+	NoOpOptionImpl
+	policy CertificatePolicy
+}
+
+// ApplyCertificatePolicy sets the specified certificate policy as the functional option
+func (r RequestCertificatePolicy) ApplyCertificatePolicy(policy *CertificatePolicy) {
+	*policy = r.policy
+}
+
+// WithCertificatePolicy specifies a policy (e.g. matching the certificate's SAN or issuer) that
+// certificate-based verifiers run after the signature verifies. An error returned by the
+// policy is reported as a verification failure.
+func WithCertificatePolicy(policy CertificatePolicy) RequestCertificatePolicy {
+	return RequestCertificatePolicy{policy: policy}
+}
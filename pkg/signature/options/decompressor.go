@@ -0,0 +1,54 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// Decompressor wraps a compressed message stream in a reader that yields the decompressed content
+type Decompressor func(io.Reader) (io.Reader, error)
+
+// GzipDecompressor is a Decompressor for gzip-compressed messages
+func GzipDecompressor(r io.Reader) (io.Reader, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return zr, nil
+}
+
+// RequestDecompressor implements the functional option pattern for decompressing a message before hashing
+type RequestDecompressor struct {
+	NoOpOptionImpl
+	decompressor Decompressor
+}
+
+// ApplyDecompressor sets the specified decompressor as the functional option
+func (r RequestDecompressor) ApplyDecompressor(decompressor *Decompressor) {
+	*decompressor = r.decompressor
+}
+
+// WithDecompressor specifies that the message is compressed and should be decompressed as it is
+// streamed into the hash function, so that the signature covers the decompressed content. Use
+// GzipDecompressor for gzip; other formats (e.g. zstd) can be supported by passing a wrapper
+// around the corresponding reader.
+//
+// The decompressor is not used if a digest is supplied with WithDigest.
+func WithDecompressor(decompressor Decompressor) RequestDecompressor {
+	return RequestDecompressor{decompressor: decompressor}
+}
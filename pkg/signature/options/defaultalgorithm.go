@@ -0,0 +1,34 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+// RequestDefaultAlgorithm implements the functional option pattern for overriding the default algorithm of a KMS SignerVerifier
+type RequestDefaultAlgorithm struct {
+	NoOpOptionImpl
+	algorithm string
+}
+
+// ApplyDefaultAlgorithm sets the default algorithm as a functional option
+func (r RequestDefaultAlgorithm) ApplyDefaultAlgorithm(algorithm *string) {
+	*algorithm = r.algorithm
+}
+
+// WithDefaultAlgorithm specifies the algorithm a KMS SignerVerifier reports from DefaultAlgorithm and
+// uses when creating a key without an explicit algorithm. It must be one of the provider's
+// SupportedAlgorithms; otherwise loading the SignerVerifier fails.
+func WithDefaultAlgorithm(algorithm string) RequestDefaultAlgorithm {
+	return RequestDefaultAlgorithm{algorithm: algorithm}
+}
@@ -0,0 +1,35 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+// RequestDeterministicSignature implements the functional option pattern for producing deterministic signatures
+type RequestDeterministicSignature struct {
+	NoOpOptionImpl
+	deterministic bool
+}
+
+// ApplyDeterministicSignature sets whether to produce a deterministic signature as a functional option
+func (r RequestDeterministicSignature) ApplyDeterministicSignature(deterministic *bool) {
+	*deterministic = r.deterministic
+}
+
+// WithDeterministicSignature specifies that ECDSA signatures should use the deterministic nonces
+// of RFC 6979, so that signing the same message with the same key always produces the same
+// signature. The source of entropy given with WithRand is then ignored. Other signers ignore this
+// option.
+func WithDeterministicSignature() RequestDeterministicSignature {
+	return RequestDeterministicSignature{deterministic: true}
+}
@@ -0,0 +1,58 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import "encoding/binary"
+
+// RequestDomainSeparation implements the functional option pattern for specifying a domain separation label
+type RequestDomainSeparation struct {
+	NoOpOptionImpl
+	label []byte
+}
+
+// ApplyDomainSeparation sets the specified domain separation label as the functional option
+func (r RequestDomainSeparation) ApplyDomainSeparation(label *[]byte) {
+	*label = r.label
+}
+
+// WithDomainSeparation specifies a label that is bound into the signed message. The
+// same label must be given when verifying, otherwise verification will fail.
+//
+// The label is length-prefixed and prepended to the message before hashing, so it
+// cannot be combined with WithDigest.
+func WithDomainSeparation(label []byte) RequestDomainSeparation {
+	return RequestDomainSeparation{label: label}
+}
+
+// keyBindingLabelPrefix distinguishes key-bound labels from labels given to WithDomainSeparation
+const keyBindingLabelPrefix = "sigstore-key-binding-v1"
+
+// WithKeyBinding specifies a domain separation label that binds the signed message to keyURI,
+// the resource ID of the signing key (e.g. "awskms:///arn:aws:kms:..."), and to label, which may
+// be nil. The same key URI and label must be given when verifying, so a signature cannot be
+// replayed as one made under a different key identity.
+//
+// The key URI is length-prefixed within the label, so distinct key URI and label pairs never
+// produce the same label. WithKeyBinding replaces, rather than adds to, a label given with
+// WithDomainSeparation, and likewise cannot be combined with WithDigest.
+func WithKeyBinding(keyURI string, label []byte) RequestDomainSeparation {
+	bound := make([]byte, 0, len(keyBindingLabelPrefix)+8+len(keyURI)+len(label))
+	bound = append(bound, keyBindingLabelPrefix...)
+	bound = binary.BigEndian.AppendUint64(bound, uint64(len(keyURI)))
+	bound = append(bound, keyURI...)
+	bound = append(bound, label...)
+	return RequestDomainSeparation{label: bound}
+}
@@ -0,0 +1,33 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+// RequestDryRun implements the functional option pattern for validating a request without performing it
+type RequestDryRun struct {
+	NoOpOptionImpl
+	dryRun bool
+}
+
+// ApplyDryRun sets whether the request should only be validated as a functional option
+func (r RequestDryRun) ApplyDryRun(dryRun *bool) {
+	*dryRun = r.dryRun
+}
+
+// WithDryRun specifies that CreateKey should only validate the request, without creating
+// a key. Providers whose backend has no validate-only call return an error instead.
+func WithDryRun(dryRun bool) RequestDryRun {
+	return RequestDryRun{dryRun: dryRun}
+}
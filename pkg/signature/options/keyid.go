@@ -0,0 +1,33 @@
+//
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+// RequestKeyID implements the functional option pattern for identifying the key a signature was made with
+type RequestKeyID struct {
+	NoOpOptionImpl
+	keyID string
+}
+
+// ApplyKeyID sets the key id as a functional option
+func (r RequestKeyID) ApplyKeyID(keyID *string) {
+	*keyID = r.keyID
+}
+
+// WithKeyID specifies the id of the key a signature was made with, e.g. as read from the "kid"
+// header of an envelope, for verifiers that select or fetch the key by id
+func WithKeyID(keyID string) RequestKeyID {
+	return RequestKeyID{keyID: keyID}
+}
@@ -0,0 +1,40 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+// RequestKeyParameters implements the functional option pattern for passing provider-specific key creation parameters
+type RequestKeyParameters struct {
+	NoOpOptionImpl
+	params map[string]string
+}
+
+// ApplyKeyParameters adds the specified key creation parameters as a functional option
+func (r RequestKeyParameters) ApplyKeyParameters(params *map[string]string) {
+	if *params == nil {
+		*params = make(map[string]string, len(r.params))
+	}
+	for k, v := range r.params {
+		(*params)[k] = v
+	}
+}
+
+// WithKeyParameters specifies provider-specific parameters for CreateKey. Each key is namespaced
+// by the provider it applies to, e.g. "gcp.protectionLevel" with a value of "HSM"; providers
+// ignore parameters in other namespaces. Parameters from multiple WithKeyParameters options are
+// merged.
+func WithKeyParameters(params map[string]string) RequestKeyParameters {
+	return RequestKeyParameters{params: params}
+}
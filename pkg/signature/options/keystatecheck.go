@@ -0,0 +1,34 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+// RequestKeyStateCheck implements the functional option pattern for checking the state of a key before signing
+type RequestKeyStateCheck struct {
+	NoOpOptionImpl
+	checkKeyState bool
+}
+
+// ApplyKeyStateCheck sets whether to check the key state as a functional option
+func (r RequestKeyStateCheck) ApplyKeyStateCheck(checkKeyState *bool) {
+	*checkKeyState = r.checkKeyState
+}
+
+// WithKeyStateCheck specifies that the signer should check that the key is usable (e.g. not
+// disabled or scheduled for deletion) before signing, where the backend exposes the key state.
+// The key state is cached along with the other key metadata.
+func WithKeyStateCheck(checkKeyState bool) RequestKeyStateCheck {
+	return RequestKeyStateCheck{checkKeyState: checkKeyState}
+}
@@ -18,6 +18,8 @@ package options
 import (
 	"crypto"
 	"crypto/rsa"
+	"crypto/x509"
+	"time"
 )
 
 // RequestHash implements the functional option pattern for setting a Hash
@@ -55,6 +57,26 @@ func WithED25519ph() RequestED25519ph {
 	return RequestED25519ph{useED25519ph: true}
 }
 
+// RequestStrictED25519 implements the functional option pattern for specifying that strict
+// (non-malleable) verification should be used when loading a verifier and a ED25519 key is
+// detected
+type RequestStrictED25519 struct {
+	NoOpOptionImpl
+	strict bool
+}
+
+// ApplyStrictED25519 sets the strict ED25519 flag as requested by the functional option
+func (r RequestStrictED25519) ApplyStrictED25519(strict *bool) {
+	*strict = r.strict
+}
+
+// WithStrictED25519 specifies that ED25519 and ED25519ph signatures should be verified strictly:
+// non-canonical encodings of S, R or the public key and small-order R or public key values are
+// rejected with a *signature.NonCanonicalSignatureError, even where crypto/ed25519 would accept them
+func WithStrictED25519() RequestStrictED25519 {
+	return RequestStrictED25519{strict: true}
+}
+
 // RequestPSSOptions implements the functional option pattern for specifying RSA
 // PSS should be used when loading a signer or verifier and a RSA key is
 // detected
@@ -74,3 +96,94 @@ func (r RequestPSSOptions) ApplyRSAPSS(opts **rsa.PSSOptions) {
 func WithRSAPSS(opts *rsa.PSSOptions) RequestPSSOptions {
 	return RequestPSSOptions{opts: opts}
 }
+
+// RequestAllowedAlgorithms implements the functional option pattern for restricting the
+// signature algorithms a verifier will accept
+type RequestAllowedAlgorithms struct {
+	NoOpOptionImpl
+	algorithms []string
+}
+
+// ApplyAllowedAlgorithms sets the allowed algorithms as requested by the functional option
+func (r RequestAllowedAlgorithms) ApplyAllowedAlgorithms(algorithms *[]string) {
+	*algorithms = r.algorithms
+}
+
+// WithAllowedAlgorithms specifies that a verifier should only accept signatures made with one of
+// the given algorithm identifiers (e.g. "ecdsa-p256-sha256"), regardless of what its key supports
+func WithAllowedAlgorithms(algorithms ...string) RequestAllowedAlgorithms {
+	return RequestAllowedAlgorithms{algorithms: algorithms}
+}
+
+// KeyAttestation is a hardware attestation (e.g. from an HSM or TPM) that a key was generated in
+// and cannot be exported from a device, expressed as a certificate chain issued by the device vendor
+type KeyAttestation struct {
+	// Chain is the attestation certificate chain, leaf first. The leaf must certify the key being loaded.
+	Chain []*x509.Certificate
+	// Roots are the trusted attestation roots, e.g. the device vendor's CA
+	Roots *x509.CertPool
+	// RequiredExtensions maps the dotted OID of each extension the leaf must carry (such as a
+	// vendor's "key is non-exportable" marker) to its expected DER value; a nil value only
+	// requires the extension to be present
+	RequiredExtensions map[string][]byte
+	// Policy, if set, is run on the leaf after the chain and extensions are validated, for
+	// properties that need vendor-specific parsing
+	Policy CertificatePolicy
+	// CurrentTime is the time at which the chain is validated; the zero value means now
+	CurrentTime time.Time
+}
+
+// RequestKeyAttestation implements the functional option pattern for requiring a hardware
+// attestation of the key when loading a verifier
+type RequestKeyAttestation struct {
+	NoOpOptionImpl
+	attestation *KeyAttestation
+}
+
+// ApplyKeyAttestation sets the key attestation as requested by the functional option
+func (r RequestKeyAttestation) ApplyKeyAttestation(attestation **KeyAttestation) {
+	*attestation = r.attestation
+}
+
+// WithKeyAttestation specifies that a verifier should only be loaded if attestation shows the
+// key to be hardware-backed with the expected properties
+func WithKeyAttestation(attestation KeyAttestation) RequestKeyAttestation {
+	return RequestKeyAttestation{attestation: &attestation}
+}
+
+// RequestPublicKeyPin implements the functional option pattern for pinning the public key a
+// verifier may use
+type RequestPublicKeyPin struct {
+	NoOpOptionImpl
+	fingerprint string
+}
+
+// ApplyPublicKeyPin sets the pinned key fingerprint as requested by the functional option
+func (r RequestPublicKeyPin) ApplyPublicKeyPin(fingerprint *string) {
+	*fingerprint = r.fingerprint
+}
+
+// WithPublicKeyPin specifies that a verifier should only be loaded for the public key with the
+// given fingerprint, as computed by cryptoutils.KeyFingerprint
+func WithPublicKeyPin(fingerprint string) RequestPublicKeyPin {
+	return RequestPublicKeyPin{fingerprint: fingerprint}
+}
+
+// RequestSkippedFileHandler implements the functional option pattern for reporting files that are
+// skipped when loading keys from a directory
+type RequestSkippedFileHandler struct {
+	NoOpOptionImpl
+	handler func(error)
+}
+
+// ApplySkippedFileHandler sets the skipped file handler as requested by the functional option
+func (r RequestSkippedFileHandler) ApplySkippedFileHandler(handler *func(error)) {
+	*handler = r.handler
+}
+
+// WithSkippedFileHandler specifies a function that is called with the error for each file that
+// cannot be read or parsed, and is therefore skipped, when loading keys from a directory. Skipped
+// files are not reported otherwise.
+func WithSkippedFileHandler(handler func(error)) RequestSkippedFileHandler {
+	return RequestSkippedFileHandler{handler: handler}
+}
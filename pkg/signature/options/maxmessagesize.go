@@ -0,0 +1,39 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+// RequestMaxMessageSize implements the functional option pattern for capping the size of a message being verified
+type RequestMaxMessageSize struct {
+	NoOpOptionImpl
+	maxSize int64
+}
+
+// ApplyMaxMessageSize sets the specified maximum message size as the functional option
+func (r RequestMaxMessageSize) ApplyMaxMessageSize(maxSize *int64) {
+	*maxSize = r.maxSize
+}
+
+// WithMaxMessageSize caps the number of message bytes that are read while verifying a signature.
+// Verification stops with a *signature.MessageTooLargeError as soon as the message is found to be
+// longer than maxSize bytes, without reading the rest of the stream. The limit applies to the
+// message as it is hashed, i.e. after any decompression requested with WithDecompressor, so it
+// also bounds the output of the decompressor. A value of zero or less means no limit, which is
+// the default.
+//
+// The limit is not used if a digest is supplied with WithDigest.
+func WithMaxMessageSize(maxSize int64) RequestMaxMessageSize {
+	return RequestMaxMessageSize{maxSize: maxSize}
+}
@@ -0,0 +1,54 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import "crypto"
+
+// RequestMinimumHash implements the functional option pattern for requiring a minimum hash strength when verifying
+type RequestMinimumHash struct {
+	NoOpOptionImpl
+	minimum crypto.Hash
+}
+
+// ApplyMinimumHash sets the minimum hash strength as a functional option
+func (r RequestMinimumHash) ApplyMinimumHash(minimum *crypto.Hash) {
+	*minimum = r.minimum
+}
+
+// WithMinimumHash specifies that verification should fail if the signature's digest was computed
+// with a hash function whose output is shorter than that of minimum, e.g. WithMinimumHash(crypto.SHA256)
+// rejects SHA-1 and SHA-224. SHA-1 can be exempted with WithAllowSHA1(true).
+func WithMinimumHash(minimum crypto.Hash) RequestMinimumHash {
+	return RequestMinimumHash{minimum: minimum}
+}
+
+// RequestAllowSHA1 implements the functional option pattern for allowing or rejecting SHA-1 when verifying
+type RequestAllowSHA1 struct {
+	NoOpOptionImpl
+	allow bool
+}
+
+// ApplyAllowSHA1 sets whether SHA-1 is allowed as a functional option
+func (r RequestAllowSHA1) ApplyAllowSHA1(allow **bool) {
+	*allow = &r.allow
+}
+
+// WithAllowSHA1 explicitly allows (true) or rejects (false) signatures whose digest was computed
+// with SHA-1, regardless of any minimum given with WithMinimumHash. Without this option, SHA-1 is
+// allowed unless a minimum hash strength excludes it.
+func WithAllowSHA1(allow bool) RequestAllowSHA1 {
+	return RequestAllowSHA1{allow: allow}
+}
@@ -34,6 +34,18 @@ func (NoOpOptionImpl) ApplyCryptoSignerOpts(_ *crypto.SignerOpts) {}
 // ApplyDigest is a no-op required to fully implement the requisite interfaces
 func (NoOpOptionImpl) ApplyDigest(_ *[]byte) {}
 
+// ApplyDecompressor is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyDecompressor(_ *Decompressor) {}
+
+// ApplyProgress is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyProgress(_ *ProgressFunc) {}
+
+// ApplyMaxMessageSize is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyMaxMessageSize(_ *int64) {}
+
+// ApplyDomainSeparation is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyDomainSeparation(_ *[]byte) {}
+
 // ApplyRand is a no-op required to fully implement the requisite interfaces
 func (NoOpOptionImpl) ApplyRand(_ *io.Reader) {}
 
@@ -46,9 +58,30 @@ func (NoOpOptionImpl) ApplyRPCAuthOpts(_ *RPCAuth) {}
 // ApplyKeyVersion is a no-op required to fully implement the requisite interfaces
 func (NoOpOptionImpl) ApplyKeyVersion(_ *string) {}
 
+// ApplyDefaultAlgorithm is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyDefaultAlgorithm(_ *string) {}
+
 // ApplyKeyVersionUsed is a no-op required to fully implement the requisite interfaces
 func (NoOpOptionImpl) ApplyKeyVersionUsed(_ **string) {}
 
+// ApplyBackendRequestID is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyBackendRequestID(_ **string) {}
+
+// ApplyKeyStateCheck is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyKeyStateCheck(_ *bool) {}
+
+// ApplyVerifyAfterSign is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyVerifyAfterSign(_ *bool) {}
+
+// ApplyDeterministicSignature is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyDeterministicSignature(_ *bool) {}
+
+// ApplyDryRun is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyDryRun(_ *bool) {}
+
+// ApplyKeyParameters is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyKeyParameters(_ *map[string]string) {}
+
 // ApplyHash is a no-op required to fully implement the requisite interfaces
 func (NoOpOptionImpl) ApplyHash(_ *crypto.Hash) {}
 
@@ -57,3 +90,30 @@ func (NoOpOptionImpl) ApplyED25519ph(_ *bool) {}
 
 // ApplyRSAPSS is a no-op required to fully implement the requisite interfaces
 func (NoOpOptionImpl) ApplyRSAPSS(_ **rsa.PSSOptions) {}
+
+// ApplyAllowedAlgorithms is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyAllowedAlgorithms(_ *[]string) {}
+
+// ApplyCertificatePolicy is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyCertificatePolicy(_ *CertificatePolicy) {}
+
+// ApplyMinimumHash is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyMinimumHash(_ *crypto.Hash) {}
+
+// ApplyAllowSHA1 is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyAllowSHA1(_ **bool) {}
+
+// ApplyKeyAttestation is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyKeyAttestation(_ **KeyAttestation) {}
+
+// ApplyStrictED25519 is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyStrictED25519(_ *bool) {}
+
+// ApplyPublicKeyPin is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyPublicKeyPin(_ *string) {}
+
+// ApplySkippedFileHandler is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplySkippedFileHandler(_ *func(error)) {}
+
+// ApplyKeyID is a no-op required to fully implement the requisite interfaces
+func (NoOpOptionImpl) ApplyKeyID(_ *string) {}
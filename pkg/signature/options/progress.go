@@ -0,0 +1,41 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+// ProgressFunc is called as a message is streamed into the hash function with the number of
+// bytes read so far and the total size of the message, or -1 if the size is not known
+type ProgressFunc func(processed, total int64)
+
+// RequestProgress implements the functional option pattern for reporting hashing progress
+type RequestProgress struct {
+	NoOpOptionImpl
+	progress ProgressFunc
+}
+
+// ApplyProgress sets the specified progress callback as the functional option
+func (r RequestProgress) ApplyProgress(progress *ProgressFunc) {
+	*progress = r.progress
+}
+
+// WithProgress specifies a callback to be invoked as the message is read while computing its
+// digest. The total is taken from the message reader if it has a Len() or Size() method (as
+// bytes.Reader, strings.Reader and io.SectionReader do), and is otherwise reported as -1. Byte
+// counts refer to the message as supplied, before any decompression.
+//
+// The callback is not used if a digest is supplied with WithDigest.
+func WithProgress(progress ProgressFunc) RequestProgress {
+	return RequestProgress{progress: progress}
+}
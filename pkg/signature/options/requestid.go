@@ -0,0 +1,35 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+// RequestBackendRequestID implements the functional option pattern for obtaining the request ID
+// assigned by a KMS backend to a signing request
+type RequestBackendRequestID struct {
+	NoOpOptionImpl
+	requestID *string
+}
+
+// ApplyBackendRequestID requests to store the backend's request ID as a functional option
+func (r RequestBackendRequestID) ApplyBackendRequestID(requestID **string) {
+	*requestID = r.requestID
+}
+
+// ReturnBackendRequestID specifies that the request ID reported by the KMS backend for a successful
+// signing request should be stored in the pointer provided. It is left unchanged if the backend
+// does not report one.
+func ReturnBackendRequestID(requestID *string) RequestBackendRequestID {
+	return RequestBackendRequestID{requestID: requestID}
+}
@@ -0,0 +1,34 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+// RequestVerifyAfterSign implements the functional option pattern for verifying a signature locally after it is produced
+type RequestVerifyAfterSign struct {
+	NoOpOptionImpl
+	verifyAfterSign bool
+}
+
+// ApplyVerifyAfterSign sets whether to verify a signature locally after it is produced as a functional option
+func (r RequestVerifyAfterSign) ApplyVerifyAfterSign(verifyAfterSign *bool) {
+	*verifyAfterSign = r.verifyAfterSign
+}
+
+// WithVerifyAfterSign specifies that a KMS signer should verify each signature returned by the
+// backend against the key's public key before returning it, to detect misbehaving backends at
+// the cost of extra latency. Providers that can only verify remotely ignore this option.
+func WithVerifyAfterSign(verifyAfterSign bool) RequestVerifyAfterSign {
+	return RequestVerifyAfterSign{verifyAfterSign: verifyAfterSign}
+}
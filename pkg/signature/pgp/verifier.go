@@ -0,0 +1,105 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build pgp
+
+package pgp
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+const armorHeader = "-----BEGIN PGP"
+
+// Verifier verifies detached OpenPGP signatures against a set of OpenPGP public keys
+type Verifier struct {
+	keyring openpgp.EntityList
+}
+
+var _ signature.Verifier = (*Verifier)(nil)
+
+// LoadVerifier returns a Verifier that accepts signatures made by any key in the supplied keyring
+func LoadVerifier(keyring openpgp.EntityList) (*Verifier, error) {
+	if len(keyring) == 0 {
+		return nil, errors.New("no OpenPGP keys provided")
+	}
+	return &Verifier{keyring: keyring}, nil
+}
+
+// LoadVerifierFromBytes parses an ASCII-armored or binary OpenPGP public keyring
+// and returns a Verifier for the keys within it
+func LoadVerifierFromBytes(keyring []byte) (*Verifier, error) {
+	var entities openpgp.EntityList
+	var err error
+	if isArmored(keyring) {
+		entities, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(keyring))
+	} else {
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(keyring))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing OpenPGP keyring: %w", err)
+	}
+	return LoadVerifier(entities)
+}
+
+// PublicKey returns the primary public key of the first entity in the keyring.
+//
+// All options provided in arguments to this method are ignored.
+func (v *Verifier) PublicKey(_ ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	entity := v.keyring[0]
+	if entity.PrimaryKey == nil {
+		return nil, errors.New("OpenPGP entity has no primary key")
+	}
+	return entity.PrimaryKey.PublicKey, nil
+}
+
+// VerifySignature verifies a detached OpenPGP signature, either ASCII-armored or
+// binary, over the message. The hash function is taken from the signature packet.
+//
+// All options provided in arguments to this method are ignored.
+func (v *Verifier) VerifySignature(sig, message io.Reader, _ ...signature.VerifyOption) error {
+	if sig == nil {
+		return errors.New("nil signature passed to VerifySignature")
+	}
+	if message == nil {
+		return errors.New("nil message passed to VerifySignature")
+	}
+
+	sigBytes, err := io.ReadAll(sig)
+	if err != nil {
+		return fmt.Errorf("reading signature: %w", err)
+	}
+
+	if isArmored(sigBytes) {
+		_, err = openpgp.CheckArmoredDetachedSignature(v.keyring, message, bytes.NewReader(sigBytes), nil)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(v.keyring, message, bytes.NewReader(sigBytes), nil)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid signature when validating OpenPGP signature: %w", err)
+	}
+	return nil
+}
+
+func isArmored(b []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(b), []byte(armorHeader))
+}
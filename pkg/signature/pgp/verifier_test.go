@@ -0,0 +1,66 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build pgp
+
+package pgp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+func TestVerifier(t *testing.T) {
+	entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("unexpected error generating entity: %v", err)
+	}
+	var pub bytes.Buffer
+	if err := entity.Serialize(&pub); err != nil {
+		t.Fatalf("unexpected error serializing public key: %v", err)
+	}
+	v, err := LoadVerifierFromBytes(pub.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+
+	message := []byte("sign me")
+	var binarySig, armoredSig bytes.Buffer
+	if err := openpgp.DetachSign(&binarySig, entity, bytes.NewReader(message), nil); err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	if err := openpgp.ArmoredDetachSign(&armoredSig, entity, bytes.NewReader(message), nil); err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+
+	for name, sig := range map[string][]byte{"binary": binarySig.Bytes(), "armored": armoredSig.Bytes()} {
+		if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+			t.Errorf("%s: unexpected error verifying signature: %v", name, err)
+		}
+		if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("tampered"))); err == nil {
+			t.Errorf("%s: expected error verifying signature over a different message", name)
+		}
+	}
+
+	if _, err := v.PublicKey(); err != nil {
+		t.Errorf("unexpected error getting public key: %v", err)
+	}
+
+	if _, err := LoadVerifier(nil); err == nil {
+		t.Error("expected error loading verifier with no keys")
+	}
+}
@@ -17,9 +17,111 @@ package signature
 
 import (
 	"crypto"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
 )
 
 // PublicKeyProvider returns a PublicKey associated with a digital signature
 type PublicKeyProvider interface {
 	PublicKey(opts ...PublicKeyOption) (crypto.PublicKey, error)
 }
+
+// KeyFingerprint returns the fingerprint (or "kid") of the public key provided by p, as
+// computed by cryptoutils.KeyFingerprint. The options are passed to p.PublicKey.
+func KeyFingerprint(p PublicKeyProvider, opts ...PublicKeyOption) (string, error) {
+	pub, err := p.PublicKey(opts...)
+	if err != nil {
+		return "", fmt.Errorf("getting public key: %w", err)
+	}
+	return cryptoutils.KeyFingerprint(pub)
+}
+
+// ErrPublicKeyMismatch is returned by CheckSamePublicKey when the public keys differ
+var ErrPublicKeyMismatch = errors.New("public keys do not match")
+
+// CheckSamePublicKey checks that first and second, e.g. a local SignerVerifier and a KMS-hosted
+// copy of the same key, expose the identical public key. It returns an error wrapping
+// ErrPublicKeyMismatch, naming both key fingerprints (see KeyFingerprint), if the keys differ,
+// and any other error if either key cannot be obtained or is of an unsupported type. The options
+// are passed to both PublicKey calls.
+func CheckSamePublicKey(first, second PublicKeyProvider, opts ...PublicKeyOption) error {
+	firstKey, err := first.PublicKey(opts...)
+	if err != nil {
+		return fmt.Errorf("getting first public key: %w", err)
+	}
+	secondKey, err := second.PublicKey(opts...)
+	if err != nil {
+		return fmt.Errorf("getting second public key: %w", err)
+	}
+	firstFP, err := cryptoutils.KeyFingerprint(firstKey)
+	if err != nil {
+		return fmt.Errorf("first public key: %w", err)
+	}
+	secondFP, err := cryptoutils.KeyFingerprint(secondKey)
+	if err != nil {
+		return fmt.Errorf("second public key: %w", err)
+	}
+	if err := cryptoutils.EqualKeys(firstKey, secondKey); err != nil {
+		return fmt.Errorf("%w: %q != %q", ErrPublicKeyMismatch, firstFP, secondFP)
+	}
+	return nil
+}
+
+// KeyPinMismatchError is returned when a verifier's public key does not have the pinned fingerprint
+type KeyPinMismatchError struct {
+	// Pin is the expected fingerprint
+	Pin string
+	// Fingerprint is the fingerprint of the key that was offered
+	Fingerprint string
+}
+
+func (e *KeyPinMismatchError) Error() string {
+	return fmt.Sprintf("public key fingerprint %q does not match pinned fingerprint %q", e.Fingerprint, e.Pin)
+}
+
+func checkKeyPin(pub crypto.PublicKey, pin string) error {
+	fingerprint, err := cryptoutils.KeyFingerprint(pub)
+	if err != nil {
+		return fmt.Errorf("computing key fingerprint: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(fingerprint), []byte(pin)) != 1 {
+		return &KeyPinMismatchError{Pin: pin, Fingerprint: fingerprint}
+	}
+	return nil
+}
+
+// pinnedVerifier only verifies signatures if the wrapped verifier's public key has the pinned fingerprint
+type pinnedVerifier struct {
+	Verifier
+	pin string
+}
+
+// PinVerifier returns a Verifier that, on every call to VerifySignature, first checks that the
+// public key of v has the fingerprint pin (see KeyFingerprint) and otherwise rejects the signature
+// with a *KeyPinMismatchError, even if it is valid under that key. The key is fetched with the
+// RPC options passed to VerifySignature, so this also applies to KMS verifiers whose effective
+// key can change, e.g. with the key version. For in-memory keys, options.WithPublicKeyPin checks
+// the pin once when the verifier is loaded instead.
+func PinVerifier(v Verifier, pin string) Verifier {
+	return &pinnedVerifier{Verifier: v, pin: pin}
+}
+
+// VerifySignature verifies the signature with the wrapped verifier if its public key matches the pin
+func (p *pinnedVerifier) VerifySignature(signature, message io.Reader, opts ...VerifyOption) error {
+	pubOpts := make([]PublicKeyOption, 0, len(opts))
+	for _, o := range opts {
+		pubOpts = append(pubOpts, o)
+	}
+	pub, err := p.Verifier.PublicKey(pubOpts...)
+	if err != nil {
+		return fmt.Errorf("getting public key: %w", err)
+	}
+	if err := checkKeyPin(pub, p.pin); err != nil {
+		return err
+	}
+	return p.Verifier.VerifySignature(signature, message, opts...)
+}
@@ -0,0 +1,109 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestPublicKeyPin(t *testing.T) {
+	pinned, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	attacker, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	pin, err := KeyFingerprint(pinned)
+	if err != nil {
+		t.Fatalf("unexpected error computing fingerprint: %v", err)
+	}
+
+	message := []byte("sign me")
+	sig, _ := pinned.SignMessage(bytes.NewReader(message))
+	attackerSig, _ := attacker.SignMessage(bytes.NewReader(message))
+	pinnedPub, _ := pinned.PublicKey()
+	attackerPub, _ := attacker.PublicKey()
+
+	t.Run("load option", func(t *testing.T) {
+		v, err := LoadVerifierWithOpts(pinnedPub, options.WithPublicKeyPin(pin))
+		if err != nil {
+			t.Fatalf("unexpected error loading pinned verifier: %v", err)
+		}
+		if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+			t.Errorf("unexpected error verifying: %v", err)
+		}
+
+		var pinErr *KeyPinMismatchError
+		if _, err := LoadVerifierWithOpts(attackerPub, options.WithPublicKeyPin(pin)); !errors.As(err, &pinErr) {
+			t.Fatalf("expected *KeyPinMismatchError, got %v", err)
+		}
+		if pinErr.Pin != pin {
+			t.Errorf("Pin = %q, expected %q", pinErr.Pin, pin)
+		}
+	})
+
+	t.Run("wrapper", func(t *testing.T) {
+		if err := PinVerifier(pinned, pin).VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+			t.Errorf("unexpected error verifying: %v", err)
+		}
+		// the signature is valid under the attacker's key, but the key is not the pinned one
+		if err := attacker.VerifySignature(bytes.NewReader(attackerSig), bytes.NewReader(message)); err != nil {
+			t.Fatalf("unexpected error verifying without pin: %v", err)
+		}
+		var pinErr *KeyPinMismatchError
+		if err := PinVerifier(attacker, pin).VerifySignature(bytes.NewReader(attackerSig), bytes.NewReader(message)); !errors.As(err, &pinErr) {
+			t.Errorf("expected *KeyPinMismatchError, got %v", err)
+		}
+	})
+}
+
+func TestCheckSamePublicKey(t *testing.T) {
+	local, priv, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	// a second, independently loaded copy of the same key, as after importing it into a KMS
+	migrated, err := LoadSignerVerifier(priv, crypto.SHA384)
+	if err != nil {
+		t.Fatalf("unexpected error loading signer/verifier: %v", err)
+	}
+	if err := CheckSamePublicKey(local, migrated); err != nil {
+		t.Errorf("unexpected error for identical keys: %v", err)
+	}
+
+	other, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	if err := CheckSamePublicKey(local, other); !errors.Is(err, ErrPublicKeyMismatch) {
+		t.Errorf("expected ErrPublicKeyMismatch, got %v", err)
+	}
+
+	ks, err := LoadKeySetVerifier([]Verifier{local}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error loading key set: %v", err)
+	}
+	if err := CheckSamePublicKey(local, ks); err == nil || errors.Is(err, ErrPublicKeyMismatch) {
+		t.Errorf("expected an error getting the public key, got %v", err)
+	}
+}
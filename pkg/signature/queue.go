@@ -0,0 +1,227 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// ErrQueueFull is returned by SigningQueue.Submit when the queue is at capacity and was
+// created without blocking
+var ErrQueueFull = errors.New("signing queue is full")
+
+// ErrQueueClosed is returned for requests submitted to, or still pending in, a closed SigningQueue
+var ErrQueueClosed = errors.New("signing queue is closed")
+
+// SigningQueue smooths bursts of signing requests to a rate that a Signer (typically a KMS
+// provider with a request quota) can sustain. Requests are buffered up to a fixed capacity and
+// dispatched no faster than the configured rate; requests are signed concurrently, so slow
+// individual calls do not reduce throughput.
+type SigningQueue struct {
+	signer        Signer
+	interval      time.Duration
+	blockWhenFull bool
+
+	requests  chan *SignFuture
+	done      chan struct{}
+	closeDone sync.Once
+	mu        sync.RWMutex
+	closed    bool
+	wg        sync.WaitGroup
+
+	depth    atomic.Int64
+	inFlight atomic.Int64
+}
+
+// SignFuture is the pending result of a request submitted to a SigningQueue or an AsyncSigner
+type SignFuture struct {
+	ctx     context.Context
+	message []byte
+	opts    []SignOption
+
+	done chan struct{}
+	sig  []byte
+	err  error
+}
+
+// Done returns a channel that is closed once the request has completed
+func (f *SignFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the request has completed or ctx is done, and returns the signature or error
+func (f *SignFuture) Wait(ctx context.Context) ([]byte, error) {
+	select {
+	case <-f.done:
+		return f.sig, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *SignFuture) complete(sig []byte, err error) {
+	f.sig, f.err = sig, err
+	close(f.done)
+}
+
+// NewSigningQueue returns a SigningQueue that signs with s at no more than qps requests per
+// second, buffering up to capacity requests. When the queue is full, Submit blocks if
+// blockWhenFull is true, and otherwise returns ErrQueueFull. Close must be called to release the
+// queue's resources.
+func NewSigningQueue(s Signer, qps float64, capacity int, blockWhenFull bool) (*SigningQueue, error) {
+	if s == nil {
+		return nil, errors.New("signer cannot be nil")
+	}
+	if qps <= 0 {
+		return nil, errors.New("qps must be positive")
+	}
+	if capacity <= 0 {
+		return nil, errors.New("capacity must be positive")
+	}
+	q := &SigningQueue{
+		signer:        s,
+		interval:      time.Duration(float64(time.Second) / qps),
+		blockWhenFull: blockWhenFull,
+		requests:      make(chan *SignFuture, capacity),
+		done:          make(chan struct{}),
+	}
+	q.wg.Add(1)
+	go q.dispatch()
+	return q, nil
+}
+
+// Submit queues message for signing with opts, and returns a SignFuture for the result. ctx
+// bounds both the time spent waiting for space in the queue and the request itself; a request
+// whose ctx is done before it is dispatched fails without calling the Signer.
+func (q *SigningQueue) Submit(ctx context.Context, message []byte, opts ...SignOption) (*SignFuture, error) {
+	if message == nil {
+		return nil, errors.New("message cannot be nil")
+	}
+	f := &SignFuture{
+		ctx:     ctx,
+		message: message,
+		opts:    opts,
+		done:    make(chan struct{}),
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+	q.depth.Add(1)
+	if !q.blockWhenFull {
+		select {
+		case q.requests <- f:
+			return f, nil
+		default:
+			q.depth.Add(-1)
+			return nil, ErrQueueFull
+		}
+	}
+	select {
+	case q.requests <- f:
+		return f, nil
+	case <-ctx.Done():
+		q.depth.Add(-1)
+		return nil, ctx.Err()
+	case <-q.done:
+		q.depth.Add(-1)
+		return nil, ErrQueueClosed
+	}
+}
+
+// Depth returns the number of requests waiting to be dispatched
+func (q *SigningQueue) Depth() int {
+	return int(q.depth.Load())
+}
+
+// InFlight returns the number of requests that have been dispatched to the Signer and not yet completed
+func (q *SigningQueue) InFlight() int {
+	return int(q.inFlight.Load())
+}
+
+// Close stops the queue from accepting requests, fails requests that have not yet been
+// dispatched with ErrQueueClosed, and waits for dispatched requests to complete.
+func (q *SigningQueue) Close() {
+	// close done before taking the write lock, so that a Submit blocked on a full queue (which
+	// holds the read lock) returns ErrQueueClosed rather than deadlocking Close
+	q.closeDone.Do(func() { close(q.done) })
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.requests)
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+func (q *SigningQueue) dispatch() {
+	defer q.wg.Done()
+	var next time.Time
+	for f := range q.requests {
+		q.depth.Add(-1)
+		if err := q.waitForSlot(f.ctx, next); err != nil {
+			f.complete(nil, err)
+			continue
+		}
+		next = time.Now().Add(q.interval)
+
+		q.inFlight.Add(1)
+		q.wg.Add(1)
+		go func(f *SignFuture) {
+			defer q.wg.Done()
+			defer q.inFlight.Add(-1)
+			opts := append(f.opts[:len(f.opts):len(f.opts)], options.WithContext(f.ctx))
+			f.complete(q.signer.SignMessage(bytes.NewReader(f.message), opts...))
+		}(f)
+	}
+}
+
+// waitForSlot waits until next, returning an error if ctx is done or the queue is closed first
+func (q *SigningQueue) waitForSlot(ctx context.Context, next time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-q.done:
+		return ErrQueueClosed
+	default:
+	}
+	wait := time.Until(next)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.done:
+		return ErrQueueClosed
+	}
+}
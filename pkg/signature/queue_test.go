@@ -0,0 +1,188 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSigningQueue(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	q, err := NewSigningQueue(sv, 1000, 4, true)
+	if err != nil {
+		t.Fatalf("unexpected error creating queue: %v", err)
+	}
+	defer q.Close()
+
+	ctx := context.Background()
+	messages := [][]byte{[]byte("one"), []byte("two"), []byte("three")}
+	var futures []*SignFuture
+	for _, m := range messages {
+		f, err := q.Submit(ctx, m)
+		if err != nil {
+			t.Fatalf("unexpected error submitting: %v", err)
+		}
+		futures = append(futures, f)
+	}
+	for i, f := range futures {
+		sig, err := f.Wait(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error signing: %v", err)
+		}
+		if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(messages[i])); err != nil {
+			t.Errorf("unexpected error verifying signature: %v", err)
+		}
+	}
+	if q.Depth() != 0 {
+		t.Errorf("expected empty queue, got depth %d", q.Depth())
+	}
+}
+
+func TestSigningQueueBackpressure(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	const capacity = 2
+	// one request per hour: after the first is dispatched, the rest stay queued
+	q, err := NewSigningQueue(sv, 1.0/3600, capacity, false)
+	if err != nil {
+		t.Fatalf("unexpected error creating queue: %v", err)
+	}
+
+	ctx := context.Background()
+	var futures []*SignFuture
+	for len(futures) <= capacity+2 {
+		f, err := q.Submit(ctx, []byte("message"))
+		if errors.Is(err, ErrQueueFull) {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error submitting: %v", err)
+		}
+		futures = append(futures, f)
+	}
+	if len(futures) > capacity+2 {
+		t.Fatal("expected ErrQueueFull")
+	}
+	if d := q.Depth(); d == 0 || d > capacity {
+		t.Errorf("expected depth between 1 and %d, got %d", capacity, d)
+	}
+	if _, err := futures[0].Wait(ctx); err != nil {
+		t.Errorf("unexpected error for first request: %v", err)
+	}
+
+	q.Close()
+	for _, f := range futures[1:] {
+		if _, err := f.Wait(ctx); !errors.Is(err, ErrQueueClosed) {
+			t.Errorf("expected ErrQueueClosed for pending request, got %v", err)
+		}
+	}
+	if _, err := q.Submit(ctx, []byte("message")); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("expected ErrQueueClosed after Close, got %v", err)
+	}
+}
+
+func TestSigningQueueContext(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	q, err := NewSigningQueue(sv, 1.0/3600, 1, true)
+	if err != nil {
+		t.Fatalf("unexpected error creating queue: %v", err)
+	}
+	defer q.Close()
+
+	if _, err := q.Submit(context.Background(), []byte("first")); err != nil {
+		t.Fatalf("unexpected error submitting: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	f, err := q.Submit(ctx, []byte("second"))
+	if err != nil {
+		t.Fatalf("unexpected error submitting: %v", err)
+	}
+	if _, err := f.Wait(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestSigningQueueCloseUnblocksSubmit(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	q, err := NewSigningQueue(sv, 1.0/3600, 1, true)
+	if err != nil {
+		t.Fatalf("unexpected error creating queue: %v", err)
+	}
+
+	// the first request is dispatched, the second waits for a slot and the third fills the queue
+	ctx := context.Background()
+	for _, m := range []string{"first", "second", "third"} {
+		if _, err := q.Submit(ctx, []byte(m)); err != nil {
+			t.Fatalf("unexpected error submitting: %v", err)
+		}
+	}
+	submitted := make(chan error, 1)
+	go func() {
+		_, err := q.Submit(ctx, []byte("blocked"))
+		submitted <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		q.Close()
+		close(closed)
+	}()
+	select {
+	case err := <-submitted:
+		if !errors.Is(err, ErrQueueClosed) {
+			t.Errorf("expected ErrQueueClosed from blocked Submit, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not unblock a Submit waiting for space in the queue")
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return")
+	}
+}
+
+func TestNewSigningQueueErrors(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	if _, err := NewSigningQueue(nil, 1, 1, true); err == nil {
+		t.Error("expected error for nil signer")
+	}
+	if _, err := NewSigningQueue(sv, 0, 1, true); err == nil {
+		t.Error("expected error for zero qps")
+	}
+	if _, err := NewSigningQueue(sv, 1, 0, true); err == nil {
+		t.Error("expected error for zero capacity")
+	}
+}
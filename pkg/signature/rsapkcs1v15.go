@@ -44,6 +44,13 @@ func LoadRSAPKCS1v15Signer(priv *rsa.PrivateKey, hf crypto.Hash) (*RSAPKCS1v15Si
 		return nil, errors.New("invalid hash function specified")
 	}
 
+	if err := fipsCheck(&priv.PublicKey, hf); err != nil {
+		return nil, err
+	}
+	if err := deprecationCheck(&priv.PublicKey, hf); err != nil {
+		return nil, err
+	}
+
 	return &RSAPKCS1v15Signer{
 		priv:     priv,
 		hashFunc: hf,
@@ -127,6 +134,13 @@ func LoadRSAPKCS1v15Verifier(pub *rsa.PublicKey, hashFunc crypto.Hash) (*RSAPKCS
 		return nil, errors.New("invalid hash function specified")
 	}
 
+	if err := fipsCheck(pub, hashFunc); err != nil {
+		return nil, err
+	}
+	if err := deprecationCheck(pub, hashFunc); err != nil {
+		return nil, err
+	}
+
 	return &RSAPKCS1v15Verifier{
 		publicKey: pub,
 		hashFunc:  hashFunc,
@@ -63,6 +63,13 @@ func LoadRSAPSSSigner(priv *rsa.PrivateKey, hf crypto.Hash, opts *rsa.PSSOptions
 		return nil, errors.New("invalid hash function specified")
 	}
 
+	if err := fipsCheck(&priv.PublicKey, hf); err != nil {
+		return nil, err
+	}
+	if err := deprecationCheck(&priv.PublicKey, hf); err != nil {
+		return nil, err
+	}
+
 	return &RSAPSSSigner{
 		priv:     priv,
 		pssOpts:  opts,
@@ -153,6 +160,13 @@ func LoadRSAPSSVerifier(pub *rsa.PublicKey, hashFunc crypto.Hash, opts *rsa.PSSO
 		return nil, errors.New("invalid hash function specified")
 	}
 
+	if err := fipsCheck(pub, hashFunc); err != nil {
+		return nil, err
+	}
+	if err := deprecationCheck(pub, hashFunc); err != nil {
+		return nil, err
+	}
+
 	return &RSAPSSVerifier{
 		publicKey: pub,
 		hashFunc:  hashFunc,
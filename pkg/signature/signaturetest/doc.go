@@ -0,0 +1,21 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signaturetest contains an insecure SignerVerifier for exercising signing plumbing in
+// tests without performing any cryptography.
+//
+// The implementation is only compiled when the "signaturetest" build tag is set, and it panics
+// if used outside of a test binary, so that it cannot end up in production code by accident.
+package signaturetest
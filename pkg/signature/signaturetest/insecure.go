@@ -0,0 +1,80 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build signaturetest
+
+package signaturetest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// InsecureSignature is the signature returned by InsecureSignerVerifier.SignMessage
+var InsecureSignature = []byte("INSECURE-TEST-SIGNATURE")
+
+// InsecureSignerVerifier is a signature.SignerVerifier that returns InsecureSignature for every
+// message and accepts every signature. It provides NO security and must only be used in tests.
+type InsecureSignerVerifier struct {
+	publicKey ed25519.PublicKey
+}
+
+var _ signature.SignerVerifier = (*InsecureSignerVerifier)(nil)
+
+// NewInsecureSignerVerifier returns an InsecureSignerVerifier. It panics if called outside of a
+// test binary.
+func NewInsecureSignerVerifier() *InsecureSignerVerifier {
+	if !testing.Testing() {
+		panic("signaturetest: InsecureSignerVerifier must only be used in tests")
+	}
+	// a fixed, well-known key so that plumbing which encodes or fingerprints the public key works
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	return &InsecureSignerVerifier{publicKey: priv.Public().(ed25519.PublicKey)}
+}
+
+// SignMessage reads the message and returns InsecureSignature
+func (i *InsecureSignerVerifier) SignMessage(message io.Reader, _ ...signature.SignOption) ([]byte, error) {
+	if message == nil {
+		return nil, errors.New("message cannot be nil")
+	}
+	if _, err := io.Copy(io.Discard, message); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(InsecureSignature), nil
+}
+
+// PublicKey returns a fixed Ed25519 public key, derived from an all-zero seed, that does not
+// correspond to the signatures produced
+func (i *InsecureSignerVerifier) PublicKey(_ ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	return i.publicKey, nil
+}
+
+// VerifySignature reads the signature and message and returns nil, regardless of their content
+func (i *InsecureSignerVerifier) VerifySignature(sig, message io.Reader, _ ...signature.VerifyOption) error {
+	if sig == nil || message == nil {
+		return errors.New("signature and message cannot be nil")
+	}
+	if _, err := io.Copy(io.Discard, sig); err != nil {
+		return err
+	}
+	_, err := io.Copy(io.Discard, message)
+	return err
+}
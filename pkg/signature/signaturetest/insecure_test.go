@@ -0,0 +1,50 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build signaturetest
+
+package signaturetest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestInsecureSignerVerifier(t *testing.T) {
+	sv := NewInsecureSignerVerifier()
+
+	sig, err := sv.SignMessage(bytes.NewReader([]byte("message")))
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	if !bytes.Equal(sig, InsecureSignature) {
+		t.Errorf("expected %q, got %q", InsecureSignature, sig)
+	}
+	if err := sv.VerifySignature(bytes.NewReader([]byte("anything")), bytes.NewReader([]byte("other message"))); err != nil {
+		t.Errorf("expected any signature to verify, got %v", err)
+	}
+	if err := sv.VerifySignature(nil, bytes.NewReader(nil)); err == nil {
+		t.Error("expected error verifying nil signature")
+	}
+
+	pub, err := sv.PublicKey()
+	if err != nil {
+		t.Fatalf("unexpected error getting public key: %v", err)
+	}
+	if _, ok := pub.(ed25519.PublicKey); !ok {
+		t.Errorf("expected ed25519.PublicKey, got %T", pub)
+	}
+}
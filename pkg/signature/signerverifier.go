@@ -16,11 +16,14 @@
 package signature
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rsa"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -97,9 +100,78 @@ func LoadSignerVerifierFromPEMFileWithOpts(path string, pf cryptoutils.PassFunc,
 	if err != nil {
 		return nil, err
 	}
-	priv, err := cryptoutils.UnmarshalPEMToPrivateKey(fileBytes, pf)
+	return LoadSignerVerifierFromPEM(fileBytes, pf, opts...)
+}
+
+// LoadSignerVerifierFromPEM returns a signature.SignerVerifier for the PEM-encoded private key in
+// pemBytes, parsing it only once. The returned object signs with the parsed private key and
+// verifies with the public key derived from it at load time, which PublicKey() returns without
+// re-deriving it, so a single value can stand in for both a Signer and a Verifier of a local key.
+// The options are as for LoadSignerVerifierWithOpts.
+func LoadSignerVerifierFromPEM(pemBytes []byte, pf cryptoutils.PassFunc, opts ...LoadOption) (SignerVerifier, error) {
+	priv, err := cryptoutils.UnmarshalPEMToPrivateKey(pemBytes, pf)
 	if err != nil {
 		return nil, err
 	}
 	return LoadSignerVerifierWithOpts(priv, opts...)
 }
+
+// SignAndVerify signs message with sv and then verifies the resulting signature as a
+// self-check, returning the signature only if verification succeeds. The message is
+// hashed once (as a stream) and the same digest is used for both operations, which
+// catches backend or key misconfiguration at signing time at minimal extra cost.
+//
+// hashFunc must be the hash function sv was configured with, or crypto.Hash(0) for a
+// pure ED25519 SignerVerifier (in which case the message is read into memory). The
+// options are applied as for ComputeDigestForSigning, and are also passed to the
+// SignMessage and VerifySignature calls, except for those that transform the message
+// (domain separation, decompression, progress and size limits), which have already been
+// applied to the digest.
+func SignAndVerify(sv SignerVerifier, hashFunc crypto.Hash, message io.Reader, opts ...SignOption) ([]byte, error) {
+	_, pure := sv.(*ED25519SignerVerifier)
+	if pure && hashFunc != crypto.Hash(0) {
+		return nil, errors.New("ED25519 signs the raw message; hashFunc must be crypto.Hash(0)")
+	}
+	if !pure && hashFunc == crypto.Hash(0) {
+		return nil, errors.New("hashFunc must be the hash function the SignerVerifier was configured with")
+	}
+
+	digest, _, err := ComputeDigestForSigning(message, hashFunc, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the domain separation label, decompressor, progress callback and size limit (if any)
+	// have already been applied to digest
+	precomputed := []interface {
+		SignOption
+		VerifyOption
+	}{
+		options.WithDigest(digest),
+		options.WithDomainSeparation(nil),
+		options.WithDecompressor(nil),
+		options.WithProgress(nil),
+		options.WithMaxMessageSize(0),
+	}
+
+	signOpts := append([]SignOption{}, opts...)
+	verifyOpts := make([]VerifyOption, 0, len(opts)+len(precomputed))
+	for _, opt := range opts {
+		if vo, ok := opt.(VerifyOption); ok {
+			verifyOpts = append(verifyOpts, vo)
+		}
+	}
+	for _, opt := range precomputed {
+		signOpts = append(signOpts, opt)
+		verifyOpts = append(verifyOpts, opt)
+	}
+
+	sig, err := sv.SignMessage(bytes.NewReader(digest), signOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(digest), verifyOpts...); err != nil {
+		return nil, fmt.Errorf("self-verification of signature failed: %w", err)
+	}
+	return sig, nil
+}
@@ -16,10 +16,13 @@ package signature
 
 import (
 	"bytes"
+	"compress/gzip"
 	"crypto"
 	"crypto/ed25519"
 	"crypto/rsa"
 	"encoding/base64"
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
@@ -80,3 +83,486 @@ func TestConvertED25519ph(t *testing.T) {
 	testingSigner(t, newSV, "ed25519", crypto.SHA256, message)
 	testingVerifier(t, newSV, "ed25519", crypto.SHA256, sig, message)
 }
+
+func TestDomainSeparation(t *testing.T) {
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ecdsa signer/verifier: %v", err)
+	}
+	rsaSV, _, err := NewDefaultRSAPKCS1v15SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating rsa signer/verifier: %v", err)
+	}
+	ed25519SV, _, err := NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519 signer/verifier: %v", err)
+	}
+	ed25519phSV, _, err := NewDefaultED25519phSignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519ph signer/verifier: %v", err)
+	}
+
+	message := []byte("sign me")
+	for name, sv := range map[string]SignerVerifier{
+		"ecdsa":     ecdsaSV,
+		"rsa":       rsaSV,
+		"ed25519":   ed25519SV,
+		"ed25519ph": ed25519phSV,
+	} {
+		t.Run(name, func(t *testing.T) {
+			sig, err := sv.SignMessage(bytes.NewReader(message), options.WithDomainSeparation([]byte("label-a")))
+			if err != nil {
+				t.Fatalf("unexpected error signing message: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithDomainSeparation([]byte("label-a"))); err != nil {
+				t.Errorf("unexpected error verifying with matching label: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithDomainSeparation([]byte("label-b"))); err == nil {
+				t.Error("expected error verifying with mismatched label")
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err == nil {
+				t.Error("expected error verifying without label")
+			}
+		})
+	}
+
+	// the label is length-prefixed, so shifting bytes between label and message must not verify
+	sig, err := ecdsaSV.SignMessage(bytes.NewReader([]byte("bc")), options.WithDomainSeparation([]byte("a")))
+	if err != nil {
+		t.Fatalf("unexpected error signing message: %v", err)
+	}
+	if err := ecdsaSV.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("c")), options.WithDomainSeparation([]byte("ab"))); err == nil {
+		t.Error("expected error verifying with shifted label boundary")
+	}
+
+	if _, err := ecdsaSV.SignMessage(nil, options.WithDigest(make([]byte, crypto.SHA256.Size())), options.WithDomainSeparation([]byte("label-a"))); err == nil {
+		t.Error("expected error combining WithDigest and WithDomainSeparation")
+	}
+}
+
+func TestKeyBinding(t *testing.T) {
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ecdsa signer/verifier: %v", err)
+	}
+	ed25519SV, _, err := NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519 signer/verifier: %v", err)
+	}
+
+	const keyA = "awskms:///arn:aws:kms:us-east-1:111122223333:alias/key-a"
+	const keyB = "awskms:///arn:aws:kms:us-east-1:111122223333:alias/key-b"
+	message := []byte("sign me")
+	for name, sv := range map[string]SignerVerifier{"ecdsa": ecdsaSV, "ed25519": ed25519SV} {
+		t.Run(name, func(t *testing.T) {
+			sig, err := sv.SignMessage(bytes.NewReader(message), options.WithKeyBinding(keyA, []byte("label")))
+			if err != nil {
+				t.Fatalf("unexpected error signing message: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithKeyBinding(keyA, []byte("label"))); err != nil {
+				t.Errorf("unexpected error verifying with matching key URI: %v", err)
+			}
+			for desc, opt := range map[string]VerifyOption{
+				"mismatched key URI": options.WithKeyBinding(keyB, []byte("label")),
+				"mismatched label":   options.WithKeyBinding(keyA, []byte("other")),
+				"plain label":        options.WithDomainSeparation([]byte("label")),
+				// the key URI is length-prefixed, so moving bytes into the label must not verify
+				"shifted boundary": options.WithKeyBinding(keyA[:len(keyA)-1], append([]byte{keyA[len(keyA)-1]}, "label"...)),
+			} {
+				if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), opt); err == nil {
+					t.Errorf("expected error verifying with %s", desc)
+				}
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err == nil {
+				t.Error("expected error verifying without key binding")
+			}
+		})
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ecdsa signer/verifier: %v", err)
+	}
+	rsaSV, _, err := NewDefaultRSAPSSSignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating rsa signer/verifier: %v", err)
+	}
+	ed25519SV, _, err := NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519 signer/verifier: %v", err)
+	}
+	ed25519phSV, _, err := NewDefaultED25519phSignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519ph signer/verifier: %v", err)
+	}
+
+	message := []byte("sign me")
+	for name, tc := range map[string]struct {
+		sv       SignerVerifier
+		hashFunc crypto.Hash
+	}{
+		"ecdsa":     {ecdsaSV, crypto.SHA256},
+		"rsa":       {rsaSV, crypto.SHA256},
+		"ed25519":   {ed25519SV, crypto.Hash(0)},
+		"ed25519ph": {ed25519phSV, crypto.SHA512},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sig, err := SignAndVerify(tc.sv, tc.hashFunc, bytes.NewReader(message))
+			if err != nil {
+				t.Fatalf("unexpected error from SignAndVerify: %v", err)
+			}
+			if err := tc.sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+				t.Errorf("unexpected error verifying signature: %v", err)
+			}
+
+			label := options.WithDomainSeparation([]byte("label"))
+			sig, err = SignAndVerify(tc.sv, tc.hashFunc, bytes.NewReader(message), label)
+			if err != nil {
+				t.Fatalf("unexpected error from SignAndVerify with label: %v", err)
+			}
+			if err := tc.sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), label); err != nil {
+				t.Errorf("unexpected error verifying labelled signature: %v", err)
+			}
+
+			// message transforms are applied only once, while hashing
+			var compressed bytes.Buffer
+			zw := gzip.NewWriter(&compressed)
+			if _, err := zw.Write(message); err != nil {
+				t.Fatal(err)
+			}
+			if err := zw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			var processed []int64
+			progress := options.WithProgress(func(n, _ int64) { processed = append(processed, n) })
+			sig, err = SignAndVerify(tc.sv, tc.hashFunc, bytes.NewReader(compressed.Bytes()), options.WithDecompressor(options.GzipDecompressor), progress, options.WithMaxMessageSize(int64(len(message))))
+			if err != nil {
+				t.Fatalf("unexpected error from SignAndVerify with decompressor: %v", err)
+			}
+			for i := 1; i < len(processed); i++ {
+				if processed[i] < processed[i-1] {
+					t.Errorf("expected progress to be reported for a single pass, got %v", processed)
+					break
+				}
+			}
+			if err := tc.sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(compressed.Bytes()), options.WithDecompressor(options.GzipDecompressor)); err != nil {
+				t.Errorf("unexpected error verifying signature over decompressed message: %v", err)
+			}
+		})
+	}
+
+	// a hash function that does not match the signer fails the self-check
+	if _, err := SignAndVerify(ecdsaSV, crypto.SHA512, bytes.NewReader(message)); err == nil {
+		t.Error("expected error when hashFunc does not match the signer")
+	}
+	if _, err := SignAndVerify(ed25519SV, crypto.SHA256, bytes.NewReader(message)); err == nil {
+		t.Error("expected error for hashed ED25519")
+	}
+	// the raw message must not be signed as if it were a digest
+	for name, sv := range map[string]SignerVerifier{"ecdsa": ecdsaSV, "rsa": rsaSV, "ed25519ph": ed25519phSV} {
+		if _, err := SignAndVerify(sv, crypto.Hash(0), bytes.NewReader(message)); err == nil {
+			t.Errorf("expected error for unhashed %s", name)
+		}
+	}
+}
+
+func TestZeroLengthMessage(t *testing.T) {
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ecdsa signer/verifier: %v", err)
+	}
+	rsaSV, _, err := NewDefaultRSAPKCS1v15SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating rsa signer/verifier: %v", err)
+	}
+	rsaPSSSV, _, err := NewDefaultRSAPSSSignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating rsa-pss signer/verifier: %v", err)
+	}
+	ed25519SV, _, err := NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519 signer/verifier: %v", err)
+	}
+	ed25519phSV, _, err := NewDefaultED25519phSignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519ph signer/verifier: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		sv       SignerVerifier
+		hashFunc crypto.Hash
+	}{
+		"ecdsa":     {ecdsaSV, crypto.SHA256},
+		"rsa":       {rsaSV, crypto.SHA256},
+		"rsa-pss":   {rsaPSSSV, crypto.SHA256},
+		"ed25519":   {ed25519SV, crypto.Hash(0)},
+		"ed25519ph": {ed25519phSV, crypto.SHA512},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sig, err := tc.sv.SignMessage(bytes.NewReader([]byte{}))
+			if err != nil {
+				t.Fatalf("unexpected error signing empty message: %v", err)
+			}
+			if err := tc.sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(nil)); err != nil {
+				t.Errorf("unexpected error verifying empty message: %v", err)
+			}
+			if err := tc.sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte{0})); err == nil {
+				t.Error("expected error verifying non-empty message")
+			}
+			if tc.hashFunc != crypto.Hash(0) {
+				// the signature covers the digest of the empty string
+				hasher := tc.hashFunc.New()
+				if err := tc.sv.VerifySignature(bytes.NewReader(sig), nil, options.WithDigest(hasher.Sum(nil))); err != nil {
+					t.Errorf("unexpected error verifying against digest of empty string: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecompressor(t *testing.T) {
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ecdsa signer/verifier: %v", err)
+	}
+	ed25519SV, _, err := NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519 signer/verifier: %v", err)
+	}
+
+	message := []byte("sign me")
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(message); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gz := options.WithDecompressor(options.GzipDecompressor)
+
+	for name, sv := range map[string]SignerVerifier{
+		"ecdsa":   ecdsaSV,
+		"ed25519": ed25519SV,
+	} {
+		t.Run(name, func(t *testing.T) {
+			sig, err := sv.SignMessage(bytes.NewReader(compressed.Bytes()), gz)
+			if err != nil {
+				t.Fatalf("unexpected error signing compressed message: %v", err)
+			}
+			// the signature covers the decompressed content
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+				t.Errorf("unexpected error verifying decompressed message: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(compressed.Bytes()), gz); err != nil {
+				t.Errorf("unexpected error verifying compressed message: %v", err)
+			}
+			if _, err := sv.SignMessage(bytes.NewReader(message), gz); err == nil {
+				t.Error("expected error signing message that is not gzip-compressed")
+			}
+			corrupt := bytes.Clone(compressed.Bytes())
+			corrupt[len(corrupt)-5] ^= 0xff
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(corrupt), gz); err == nil {
+				t.Error("expected error verifying corrupt compressed message")
+			}
+		})
+	}
+}
+
+func TestProgress(t *testing.T) {
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ecdsa signer/verifier: %v", err)
+	}
+	ed25519SV, _, err := NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519 signer/verifier: %v", err)
+	}
+
+	message := bytes.Repeat([]byte("a"), 100000)
+	for name, sv := range map[string]SignerVerifier{
+		"ecdsa":   ecdsaSV,
+		"ed25519": ed25519SV,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			var lastProcessed, lastTotal int64
+			progress := options.WithProgress(func(processed, total int64) {
+				if processed < lastProcessed {
+					t.Errorf("progress went backwards: %d after %d", processed, lastProcessed)
+				}
+				calls++
+				lastProcessed, lastTotal = processed, total
+			})
+
+			sig, err := sv.SignMessage(bytes.NewReader(message), progress)
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			if calls == 0 || lastProcessed != int64(len(message)) || lastTotal != int64(len(message)) {
+				t.Errorf("unexpected progress after signing: %d calls, %d/%d", calls, lastProcessed, lastTotal)
+			}
+
+			// the total is unknown for readers without a size
+			calls, lastProcessed = 0, 0
+			if err := sv.VerifySignature(bytes.NewReader(sig), io.MultiReader(bytes.NewReader(message)), progress); err != nil {
+				t.Fatalf("unexpected error verifying: %v", err)
+			}
+			if calls == 0 || lastProcessed != int64(len(message)) || lastTotal != -1 {
+				t.Errorf("unexpected progress after verifying: %d calls, %d/%d", calls, lastProcessed, lastTotal)
+			}
+
+			// a nil callback is ignored
+			if _, err := sv.SignMessage(bytes.NewReader(message), options.WithProgress(nil)); err != nil {
+				t.Errorf("unexpected error signing with nil progress callback: %v", err)
+			}
+		})
+	}
+}
+
+// endlessReader yields zero bytes forever
+type endlessReader struct{}
+
+func (endlessReader) Read(b []byte) (int, error) {
+	clear(b)
+	return len(b), nil
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ecdsa signer/verifier: %v", err)
+	}
+	ed25519SV, _, err := NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating ed25519 signer/verifier: %v", err)
+	}
+
+	message := bytes.Repeat([]byte("a"), 1000)
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(message); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gz := options.WithDecompressor(options.GzipDecompressor)
+
+	for name, sv := range map[string]SignerVerifier{
+		"ecdsa":   ecdsaSV,
+		"ed25519": ed25519SV,
+	} {
+		t.Run(name, func(t *testing.T) {
+			sig, err := sv.SignMessage(bytes.NewReader(message))
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			for _, limit := range []int64{0, -1, int64(len(message)), int64(len(message)) + 1} {
+				if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithMaxMessageSize(limit)); err != nil {
+					t.Errorf("unexpected error verifying with limit %d: %v", limit, err)
+				}
+			}
+
+			var tooLarge *MessageTooLargeError
+			err = sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithMaxMessageSize(int64(len(message))-1))
+			if !errors.As(err, &tooLarge) || tooLarge.Limit != int64(len(message))-1 {
+				t.Errorf("expected *MessageTooLargeError for oversized message, got %v", err)
+			}
+			// an unbounded stream is abandoned once the limit is passed
+			err = sv.VerifySignature(bytes.NewReader(sig), endlessReader{}, options.WithMaxMessageSize(1<<20))
+			if !errors.As(err, &tooLarge) {
+				t.Errorf("expected *MessageTooLargeError for endless message, got %v", err)
+			}
+			// the limit applies to the decompressed content
+			if compressed.Len() >= len(message)-1 {
+				t.Fatalf("test message did not compress: %d bytes", compressed.Len())
+			}
+			err = sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(compressed.Bytes()), gz, options.WithMaxMessageSize(int64(len(message))-1))
+			if !errors.As(err, &tooLarge) {
+				t.Errorf("expected *MessageTooLargeError for oversized decompressed message, got %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(compressed.Bytes()), gz, options.WithMaxMessageSize(int64(len(message)))); err != nil {
+				t.Errorf("unexpected error verifying compressed message within limit: %v", err)
+			}
+		})
+	}
+
+	ks, err := LoadKeySetVerifier([]Verifier{ecdsaSV, ed25519SV}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error creating key set: %v", err)
+	}
+	sig, err := ed25519SV.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+	var tooLarge *MessageTooLargeError
+	if err := ks.VerifySignature(bytes.NewReader(sig), endlessReader{}, options.WithMaxMessageSize(1<<20)); !errors.As(err, &tooLarge) {
+		t.Errorf("expected *MessageTooLargeError from key set, got %v", err)
+	}
+	if err := ks.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithMaxMessageSize(int64(len(message)))); err != nil {
+		t.Errorf("unexpected error verifying with key set: %v", err)
+	}
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	fp, err := KeyFingerprint(sv)
+	if err != nil {
+		t.Fatalf("unexpected error computing fingerprint: %v", err)
+	}
+	pub, _ := sv.PublicKey()
+	expected, err := cryptoutils.KeyFingerprint(pub)
+	if err != nil {
+		t.Fatalf("unexpected error computing fingerprint: %v", err)
+	}
+	if fp != expected {
+		t.Errorf("KeyFingerprint() = %q, expected %q", fp, expected)
+	}
+	if fp2, _ := KeyFingerprint(sv.ECDSAVerifier); fp2 != fp {
+		t.Errorf("expected verifier fingerprint %q to match signer/verifier fingerprint %q", fp2, fp)
+	}
+}
+
+func TestLoadSignerVerifierFromPEM(t *testing.T) {
+	_, ecdsaPriv, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	ecdsaPEM, err := cryptoutils.MarshalPrivateKeyToPEM(ecdsaPriv)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling key: %v", err)
+	}
+
+	for name, pemBytes := range map[string][]byte{"rsa": []byte(rsaKey), "ecdsa": ecdsaPEM} {
+		t.Run(name, func(t *testing.T) {
+			sv, err := LoadSignerVerifierFromPEM(pemBytes, cryptoutils.SkipPassword, options.WithHash(crypto.SHA384))
+			if err != nil {
+				t.Fatalf("unexpected error loading signer/verifier: %v", err)
+			}
+			first, _ := sv.PublicKey()
+			second, _ := sv.PublicKey()
+			if first != second {
+				t.Error("expected PublicKey() to return the cached public key")
+			}
+
+			message := []byte("sign me")
+			sig, err := sv.SignMessage(bytes.NewReader(message))
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+				t.Errorf("unexpected error verifying: %v", err)
+			}
+		})
+	}
+
+	if _, err := LoadSignerVerifierFromPEM([]byte("not a pem"), cryptoutils.SkipPassword); err == nil {
+		t.Error("expected error for invalid PEM")
+	}
+}
@@ -38,6 +38,14 @@ type Signature struct {
 
 // Armor generates a PEM armored signature block.
 func Armor(s *ssh.Signature, p ssh.PublicKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  pemType,
+		Bytes: Marshal(s, p),
+	})
+}
+
+// Marshal generates the unarmored SSHSIG wire-format signature blob.
+func Marshal(s *ssh.Signature, p ssh.PublicKey) []byte {
 	sig := wrappedSig{
 		Version:       1,
 		PublicKey:     string(p.Marshal()),
@@ -47,12 +55,7 @@ func Armor(s *ssh.Signature, p ssh.PublicKey) []byte {
 	}
 
 	copy(sig.MagicHeader[:], magicHeader)
-
-	enc := pem.EncodeToMemory(&pem.Block{
-		Type:  pemType,
-		Bytes: ssh.Marshal(sig),
-	})
-	return enc
+	return ssh.Marshal(sig)
 }
 
 // Decode parses a PEM armored signature block.
@@ -65,10 +68,13 @@ func Decode(b []byte) (*Signature, error) {
 	if pemBlock.Type != pemType {
 		return nil, fmt.Errorf("wrong pem block type: %s. Expected SSH-SIGNATURE", pemBlock.Type)
 	}
+	return Unmarshal(pemBlock.Bytes)
+}
 
-	// Now we unmarshal it into the Signature block
+// Unmarshal parses an unarmored SSHSIG wire-format signature blob.
+func Unmarshal(b []byte) (*Signature, error) {
 	sig := wrappedSig{}
-	if err := ssh.Unmarshal(pemBlock.Bytes, &sig); err != nil {
+	if err := ssh.Unmarshal(b, &sig); err != nil {
 		return nil, err
 	}
 
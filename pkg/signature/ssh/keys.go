@@ -0,0 +1,106 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"io"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+	"golang.org/x/crypto/ssh"
+)
+
+var supportedKeyTypes = map[string]struct{}{
+	ssh.KeyAlgoED25519:  {},
+	ssh.KeyAlgoECDSA256: {},
+	ssh.KeyAlgoECDSA384: {},
+	ssh.KeyAlgoECDSA521: {},
+	ssh.KeyAlgoRSA:      {},
+}
+
+func checkKeyType(pub ssh.PublicKey) error {
+	if _, ok := supportedKeyTypes[pub.Type()]; !ok {
+		return fmt.Errorf("unsupported SSH key type: %s", pub.Type())
+	}
+	return nil
+}
+
+// LoadSigner returns a Signer for the given OpenSSH private key. Only
+// ssh-ed25519, ecdsa-sha2-nistp{256,384,521} and ssh-rsa keys are supported.
+func LoadSigner(sshPrivateKey []byte) (*Signer, error) {
+	s, err := ssh.ParsePrivateKey(sshPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKeyType(s.PublicKey()); err != nil {
+		return nil, err
+	}
+	as, ok := s.(ssh.AlgorithmSigner)
+	if !ok {
+		return nil, fmt.Errorf("private key %T is not a ssh.AlgorithmSigner", s)
+	}
+	return &Signer{signer: as}, nil
+}
+
+// Verifier implements signature.Verifier for SSH public keys.
+type Verifier struct {
+	publicKey ssh.PublicKey
+}
+
+// LoadVerifier returns a Verifier for the given public key, in the
+// authorized_keys format (e.g. "ssh-ed25519 AAAA... comment"). Only
+// ssh-ed25519, ecdsa-sha2-nistp{256,384,521} and ssh-rsa keys are supported.
+func LoadVerifier(authorizedKey []byte) (*Verifier, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(authorizedKey)
+	if err != nil {
+		return nil, err
+	}
+	return LoadVerifierFromPublicKey(pub)
+}
+
+// LoadVerifierFromPublicKey returns a Verifier for the given parsed public key.
+func LoadVerifierFromPublicKey(pub ssh.PublicKey) (*Verifier, error) {
+	if err := checkKeyType(pub); err != nil {
+		return nil, err
+	}
+	return &Verifier{publicKey: pub}, nil
+}
+
+// PublicKey returns the public key for a Verifier.
+func (v *Verifier) PublicKey(_ ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	return v.publicKey, nil
+}
+
+// VerifySignature verifies a supplied signature, which may be either a PEM
+// armored SSH signature or an unarmored SSHSIG wire-format blob.
+func (v *Verifier) VerifySignature(signature, message io.Reader, _ ...signature.VerifyOption) error {
+	b, err := io.ReadAll(signature)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("-----BEGIN ")) {
+		return Verify(message, b, v.publicKey)
+	}
+	sig, err := Unmarshal(b)
+	if err != nil {
+		return err
+	}
+	return verify(message, sig, v.publicKey)
+}
+
+var _ signature.Verifier = (*Verifier)(nil)
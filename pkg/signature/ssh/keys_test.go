@@ -0,0 +1,125 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/dsa" //nolint:staticcheck // only used to produce an unsupported key
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestLoadSignerVerifier(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPEM, err := ssh.MarshalPrivateKey(ecKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecSSHPub, err := ssh.NewPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("my good data to be signed!")
+	for _, tt := range []struct {
+		name string
+		pub  string
+		priv string
+	}{
+		{
+			name: "rsa",
+			pub:  sshPublicKey,
+			priv: sshPrivateKey,
+		},
+		{
+			name: "ed25519",
+			pub:  ed25519PublicKey,
+			priv: ed25519PrivateKey,
+		},
+		{
+			name: "ecdsa",
+			pub:  string(ssh.MarshalAuthorizedKey(ecSSHPub)),
+			priv: string(pem.EncodeToMemory(ecPEM)),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := LoadSigner([]byte(tt.priv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := LoadVerifier([]byte(tt.pub))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			armored, err := s.SignMessage(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.VerifySignature(bytes.NewReader(armored), bytes.NewReader(data)); err != nil {
+				t.Errorf("unexpected error verifying armored signature: %v", err)
+			}
+			if err := s.VerifySignature(bytes.NewReader(armored), bytes.NewReader(data)); err != nil {
+				t.Errorf("unexpected error verifying with signer: %v", err)
+			}
+			if err := v.VerifySignature(bytes.NewReader(armored), strings.NewReader("bad data")); err == nil {
+				t.Error("expected error verifying tampered data")
+			}
+
+			// the unarmored wire-format blob verifies too
+			decoded, err := Decode(armored)
+			if err != nil {
+				t.Fatal(err)
+			}
+			blob := Marshal(decoded.signature, decoded.pk)
+			if err := v.VerifySignature(bytes.NewReader(blob), bytes.NewReader(data)); err != nil {
+				t.Errorf("unexpected error verifying signature blob: %v", err)
+			}
+			if _, err := Unmarshal(blob[1:]); err == nil {
+				t.Error("expected error unmarshalling truncated blob")
+			}
+		})
+	}
+}
+
+func TestLoadVerifierUnsupportedKeyType(t *testing.T) {
+	var params dsa.Parameters
+	if err := dsa.GenerateParameters(&params, rand.Reader, dsa.L1024N160); err != nil {
+		t.Fatal(err)
+	}
+	priv := &dsa.PrivateKey{PublicKey: dsa.PublicKey{Parameters: params}}
+	if err := dsa.GenerateKey(priv, rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadVerifier(ssh.MarshalAuthorizedKey(pub))
+	if err == nil || !strings.Contains(err.Error(), "unsupported SSH key type: ssh-dss") {
+		t.Errorf("expected unsupported key type error, got %v", err)
+	}
+}
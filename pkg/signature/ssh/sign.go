@@ -99,11 +99,7 @@ func (s *Signer) PublicKey(_ ...signature.PublicKeyOption) (crypto.PublicKey, er
 
 // SignMessage signs the supplied message.
 func (s *Signer) SignMessage(message io.Reader, _ ...signature.SignOption) ([]byte, error) {
-	b, err := io.ReadAll(message)
-	if err != nil {
-		return nil, err
-	}
-	sig, err := s.signer.Sign(rand.Reader, b)
+	sig, err := sign(s.signer, message)
 	if err != nil {
 		return nil, err
 	}
@@ -28,7 +28,10 @@ func Verify(message io.Reader, armoredSignature []byte, pubKey ssh.PublicKey) er
 	if err != nil {
 		return err
 	}
+	return verify(message, decodedSignature, pubKey)
+}
 
+func verify(message io.Reader, decodedSignature *Signature, pubKey ssh.PublicKey) error {
 	// Hash the message so we can verify it against the signature.
 	h := supportedHashAlgorithms[decodedSignature.hashAlg]()
 	if _, err := io.Copy(h, message); err != nil {
@@ -0,0 +1,130 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// SigningStats is a point-in-time snapshot of the counters of a StatsCollector
+type SigningStats struct {
+	// Signs and Verifies count completed operations, including those that failed
+	Signs    uint64
+	Verifies uint64
+	// SignErrors and VerifyErrors count operations that returned an error; for verification
+	// this includes signatures that did not verify
+	SignErrors   uint64
+	VerifyErrors uint64
+	// BytesSigned and BytesVerified count the message bytes read while hashing. Operations
+	// given a precomputed digest with options.WithDigest do not read the message.
+	BytesSigned   uint64
+	BytesVerified uint64
+}
+
+// StatsCollector accumulates signing and verification counters for one or more
+// StatsSignerVerifiers. The zero value is ready to use, and it is safe for concurrent use.
+type StatsCollector struct {
+	signs, verifies            atomic.Uint64
+	signErrors, verifyErrors   atomic.Uint64
+	bytesSigned, bytesVerified atomic.Uint64
+}
+
+// Snapshot returns the current value of each counter. The counters are read individually, so a
+// snapshot taken while operations are in progress may reflect some of their effects and not others.
+func (c *StatsCollector) Snapshot() SigningStats {
+	return SigningStats{
+		Signs:         c.signs.Load(),
+		Verifies:      c.verifies.Load(),
+		SignErrors:    c.signErrors.Load(),
+		VerifyErrors:  c.verifyErrors.Load(),
+		BytesSigned:   c.bytesSigned.Load(),
+		BytesVerified: c.bytesVerified.Load(),
+	}
+}
+
+// StatsSignerVerifier is a SignerVerifier that records each SignMessage and VerifySignature call
+// in a StatsCollector before returning the result of the wrapped SignerVerifier. Stats are opt-in:
+// a SignerVerifier that is not wrapped pays no cost for them.
+type StatsSignerVerifier struct {
+	SignerVerifier
+	stats *StatsCollector
+}
+
+// NewStatsSignerVerifier returns a StatsSignerVerifier that records the operations of sv in stats.
+// A single StatsCollector may be shared by several wrappers to aggregate their counters.
+func NewStatsSignerVerifier(sv SignerVerifier, stats *StatsCollector) (*StatsSignerVerifier, error) {
+	if sv == nil {
+		return nil, errors.New("signer/verifier cannot be nil")
+	}
+	if stats == nil {
+		return nil, errors.New("stats collector cannot be nil")
+	}
+	return &StatsSignerVerifier{
+		SignerVerifier: sv,
+		stats:          stats,
+	}, nil
+}
+
+// Stats returns the collector the operations are recorded in
+func (s *StatsSignerVerifier) Stats() *StatsCollector {
+	return s.stats
+}
+
+// SignMessage signs message with the wrapped SignerVerifier and records the operation
+func (s *StatsSignerVerifier) SignMessage(message io.Reader, opts ...SignOption) ([]byte, error) {
+	var n atomic.Uint64
+	sig, err := s.SignerVerifier.SignMessage(countingReader(message, &n), opts...)
+	s.stats.signs.Add(1)
+	s.stats.bytesSigned.Add(n.Load())
+	if err != nil {
+		s.stats.signErrors.Add(1)
+	}
+	return sig, err
+}
+
+// VerifySignature verifies the signature with the wrapped SignerVerifier and records the operation
+func (s *StatsSignerVerifier) VerifySignature(signature, message io.Reader, opts ...VerifyOption) error {
+	var n atomic.Uint64
+	err := s.SignerVerifier.VerifySignature(signature, countingReader(message, &n), opts...)
+	s.stats.verifies.Add(1)
+	s.stats.bytesVerified.Add(n.Load())
+	if err != nil {
+		s.stats.verifyErrors.Add(1)
+	}
+	return err
+}
+
+// countingReader wraps r so that the number of bytes read from it is added to n; a nil r is
+// returned unchanged so that the wrapped implementation still rejects it
+func countingReader(r io.Reader, n *atomic.Uint64) io.Reader {
+	if r == nil {
+		return nil
+	}
+	return &statsReader{r: r, n: n}
+}
+
+type statsReader struct {
+	r io.Reader
+	n *atomic.Uint64
+}
+
+func (s *statsReader) Read(b []byte) (int, error) {
+	n, err := s.r.Read(b)
+	s.n.Add(uint64(n))
+	return n, err
+}
@@ -0,0 +1,95 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"sync"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestStatsSignerVerifier(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	var stats StatsCollector
+	s, err := NewStatsSignerVerifier(sv, &stats)
+	if err != nil {
+		t.Fatalf("unexpected error wrapping signer/verifier: %v", err)
+	}
+	if s.Stats() != &stats {
+		t.Error("expected wrapper to return its collector")
+	}
+
+	message := []byte("sign me")
+	const n = 10
+	var wg sync.WaitGroup
+	sigs := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sig, err := s.SignMessage(bytes.NewReader(message))
+			if err != nil {
+				t.Errorf("unexpected error signing: %v", err)
+			}
+			sigs[i] = sig
+		}(i)
+	}
+	wg.Wait()
+	for _, sig := range sigs {
+		if err := s.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+			t.Errorf("unexpected error verifying: %v", err)
+		}
+	}
+	if err := s.VerifySignature(bytes.NewReader(sigs[0]), bytes.NewReader([]byte("tampered"))); err == nil {
+		t.Error("expected error verifying tampered message")
+	}
+	if _, err := s.SignMessage(nil); err == nil {
+		t.Error("expected error signing nil message")
+	}
+	// a precomputed digest does not read the message
+	digest, _, err := ComputeDigestForSigning(bytes.NewReader(message), crypto.SHA256, ecdsaSupportedHashFuncs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SignMessage(bytes.NewReader(message), options.WithDigest(digest)); err != nil {
+		t.Errorf("unexpected error signing digest: %v", err)
+	}
+
+	want := SigningStats{
+		Signs:         n + 2,
+		Verifies:      n + 1,
+		SignErrors:    1,
+		VerifyErrors:  1,
+		BytesSigned:   n * uint64(len(message)),
+		BytesVerified: n*uint64(len(message)) + uint64(len("tampered")),
+	}
+	if got := stats.Snapshot(); got != want {
+		t.Errorf("expected stats %+v, got %+v", want, got)
+	}
+
+	if _, err := NewStatsSignerVerifier(nil, &stats); err == nil {
+		t.Error("expected error wrapping nil signer/verifier")
+	}
+	if _, err := NewStatsSignerVerifier(sv, nil); err == nil {
+		t.Error("expected error for nil collector")
+	}
+}
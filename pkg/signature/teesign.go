@@ -0,0 +1,160 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// ErrTeeSignerClosed is returned when writing to a TeeSigner after Close
+var ErrTeeSignerClosed = errors.New("tee signer is closed")
+
+// TeeSigner is an io.WriteCloser that writes a stream through to a destination (e.g. an
+// upload) while hashing it, and signs the digest when it is closed, so that a large artifact
+// can be signed without reading it a second time. It is safe for concurrent use, though
+// concurrent writes are written and hashed in an unspecified order.
+type TeeSigner struct {
+	w      io.Writer
+	hasher *ResumableHasher
+	signer Signer
+	opts   []SignOption
+
+	mu       sync.Mutex
+	n        uint64
+	writeErr error
+	closed   bool
+	sig      []byte
+	err      error
+}
+
+// NewTeeSigner returns a TeeSigner that writes to w and signs what was written with s.
+//
+// The digest is computed with SHA256 (SHA512 for Ed25519ph) unless a hash function is given with
+// options.WithCryptoSignerOpts, and a label given with options.WithDomainSeparation is bound
+// into it exactly as by SignMessage, so the signature verifies with the same options. The other
+// options are passed to s when signing. options.WithDigest cannot be used, and s must be able to
+// sign a precomputed digest, which rules out pure Ed25519 (use Ed25519ph instead).
+func NewTeeSigner(w io.Writer, s Signer, opts ...SignOption) (*TeeSigner, error) {
+	if w == nil {
+		return nil, errors.New("writer cannot be nil")
+	}
+	if s == nil {
+		return nil, errors.New("signer cannot be nil")
+	}
+	var signerOpts crypto.SignerOpts
+	var digest, label []byte
+	for _, opt := range opts {
+		opt.ApplyCryptoSignerOpts(&signerOpts)
+		opt.ApplyDigest(&digest)
+		opt.ApplyDomainSeparation(&label)
+	}
+	if len(digest) > 0 {
+		return nil, errors.New("a precomputed digest cannot be given to a tee signer")
+	}
+	pub, err := s.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("getting public key: %w", err)
+	}
+	if _, ok := pub.(ed25519.PublicKey); ok {
+		switch s.(type) {
+		case *ED25519phSigner, *ED25519phSignerVerifier:
+		default:
+			return nil, errors.New("ed25519 signs the message rather than its digest; use ed25519ph with a tee signer")
+		}
+		if signerOpts == nil {
+			signerOpts = crypto.SHA512
+		}
+	}
+	if signerOpts == nil {
+		signerOpts = crypto.SHA256
+	}
+	hasher, err := NewResumableHasher(signerOpts.HashFunc())
+	if err != nil {
+		return nil, err
+	}
+	if label != nil {
+		if _, err := io.Copy(hasher, withDomainSeparation(bytes.NewReader(nil), label)); err != nil {
+			return nil, err
+		}
+	}
+	return &TeeSigner{
+		w:      w,
+		hasher: hasher,
+		signer: s,
+		// the label is already in the digest, and cannot be combined with one
+		opts: append(opts[:len(opts):len(opts)], options.WithDomainSeparation(nil)),
+	}, nil
+}
+
+// Write writes p to the destination and adds the bytes that were written to the digest. After a
+// failed write the stream is incomplete, so Close returns the write error instead of signing.
+func (t *TeeSigner) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return 0, ErrTeeSignerClosed
+	}
+	if t.writeErr != nil {
+		return 0, t.writeErr
+	}
+	n, err := t.w.Write(p)
+	_, _ = t.hasher.Write(p[:n])
+	t.n += uint64(n)
+	if err != nil {
+		t.writeErr = err
+	}
+	return n, err
+}
+
+// Close signs the digest of everything written and returns any error from signing or from an
+// earlier write. The destination is not closed. Calling Close more than once returns the same
+// result.
+func (t *TeeSigner) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return t.err
+	}
+	t.closed = true
+	if t.writeErr != nil {
+		t.err = fmt.Errorf("writing to destination: %w", t.writeErr)
+		return t.err
+	}
+	t.sig, t.err = t.hasher.SignDigest(t.signer, t.opts...)
+	return t.err
+}
+
+// Signature returns the signature once Close has succeeded, and nil otherwise
+func (t *TeeSigner) Signature() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sig
+}
+
+// BytesWritten returns the number of bytes written through to the destination
+func (t *TeeSigner) BytesWritten() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.n
+}
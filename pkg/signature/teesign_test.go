@@ -0,0 +1,162 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+type failingWriter struct {
+	limit int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > f.limit {
+		n := f.limit
+		f.limit = 0
+		return n, errors.New("destination full")
+	}
+	f.limit -= len(p)
+	return len(p), nil
+}
+
+func TestTeeSigner(t *testing.T) {
+	message := bytes.Repeat([]byte("0123456789"), 1000)
+
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaSV, _, err := NewDefaultRSAPKCS1v15SignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edphSV, err := LoadED25519phSignerVerifier(edPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		sv   SignerVerifier
+		opts []SignOption
+	}{
+		{name: "ecdsa", sv: ecdsaSV},
+		{name: "rsa sha512", sv: rsaSV, opts: []SignOption{options.WithCryptoSignerOpts(crypto.SHA512)}},
+		{name: "ed25519ph", sv: edphSV},
+		{name: "domain separation", sv: ecdsaSV, opts: []SignOption{options.WithDomainSeparation([]byte("label"))}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var dst bytes.Buffer
+			ts, err := NewTeeSigner(&dst, tc.sv, tc.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error creating tee signer: %v", err)
+			}
+			for i := 0; i < len(message); i += 777 {
+				end := min(i+777, len(message))
+				if _, err := ts.Write(message[i:end]); err != nil {
+					t.Fatalf("unexpected error writing: %v", err)
+				}
+			}
+			if ts.Signature() != nil {
+				t.Error("expected no signature before Close")
+			}
+			if err := ts.Close(); err != nil {
+				t.Fatalf("unexpected error closing: %v", err)
+			}
+			if !bytes.Equal(dst.Bytes(), message) {
+				t.Error("destination does not hold the message")
+			}
+			if ts.BytesWritten() != uint64(len(message)) {
+				t.Errorf("expected %d bytes written, got %d", len(message), ts.BytesWritten())
+			}
+			var verifyOpts []VerifyOption
+			for _, o := range tc.opts {
+				verifyOpts = append(verifyOpts, o.(VerifyOption))
+			}
+			if err := tc.sv.VerifySignature(bytes.NewReader(ts.Signature()), bytes.NewReader(message), verifyOpts...); err != nil {
+				t.Errorf("unexpected error verifying signature: %v", err)
+			}
+			if _, err := ts.Write([]byte("more")); !errors.Is(err, ErrTeeSignerClosed) {
+				t.Errorf("expected ErrTeeSignerClosed writing after Close, got %v", err)
+			}
+		})
+	}
+
+	t.Run("label mismatch", func(t *testing.T) {
+		ts, err := NewTeeSigner(&bytes.Buffer{}, ecdsaSV, options.WithDomainSeparation([]byte("label")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = ts.Write(message)
+		if err := ts.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := ecdsaSV.VerifySignature(bytes.NewReader(ts.Signature()), bytes.NewReader(message)); err == nil {
+			t.Error("expected verification without the label to fail")
+		}
+	})
+}
+
+func TestTeeSignerErrors(t *testing.T) {
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSV, _, err := NewDefaultED25519SignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewTeeSigner(&bytes.Buffer{}, edSV); err == nil {
+		t.Error("expected error creating tee signer for pure ed25519")
+	}
+	if _, err := NewTeeSigner(&bytes.Buffer{}, ecdsaSV, options.WithDigest(make([]byte, 32))); err == nil {
+		t.Error("expected error creating tee signer with a precomputed digest")
+	}
+	if _, err := NewTeeSigner(nil, ecdsaSV); err == nil {
+		t.Error("expected error creating tee signer with nil writer")
+	}
+
+	ts, err := NewTeeSigner(&failingWriter{limit: 10}, ecdsaSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := ts.Write(make([]byte, 16)); err == nil || n != 10 {
+		t.Errorf("expected short write error, got %d, %v", n, err)
+	}
+	if _, err := ts.Write([]byte("x")); err == nil {
+		t.Error("expected writes after a failed write to fail")
+	}
+	if err := ts.Close(); err == nil {
+		t.Error("expected Close to fail after a failed write")
+	}
+	if ts.Signature() != nil {
+		t.Error("expected no signature after a failed write")
+	}
+}
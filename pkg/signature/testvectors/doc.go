@@ -0,0 +1,25 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testvectors provides known-answer test vectors for the signature algorithms supported by
+// package signature, and a conformance check that runs a SignerVerifier against them.
+//
+// The vectors cover RSA PKCS #1 v1.5 and PSS, ECDSA over P-256, P-384 and P-521, and Ed25519. Each
+// has a fixed private key, message, digest and a valid signature. RSA PKCS #1 v1.5 and Ed25519
+// signatures are deterministic and must be reproduced exactly; ECDSA and RSA PSS signatures are
+// randomized, so for those a fresh signature is only checked to verify against the public key.
+//
+// The keys are published and must never be used for anything but testing.
+package testvectors
@@ -0,0 +1,131 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testvectors
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// Vector is a known-answer test vector for one signature algorithm
+type Vector struct {
+	// Algorithm identifies the signature algorithm
+	Algorithm signature.Algorithm
+	// PrivateKeyPEM is the PKCS #8 PEM-encoded signing key
+	PrivateKeyPEM string
+	// Hash is the hash function applied to Message, or crypto.Hash(0) for Ed25519
+	Hash crypto.Hash
+	// RSAPSS is set if the vector uses RSA PSS rather than PKCS #1 v1.5
+	RSAPSS bool
+	// Message is the signed message
+	Message []byte
+	// Digest is the digest of Message with Hash; it is nil for Ed25519, which signs the message itself
+	Digest []byte
+	// Signature is a valid signature over Message
+	Signature []byte
+	// Deterministic is set if signing Message must reproduce Signature exactly
+	Deterministic bool
+}
+
+// LoadOptions returns the options for loading a signer or verifier for the vector's algorithm
+func (v Vector) LoadOptions() []signature.LoadOption {
+	opts := []signature.LoadOption{options.WithHash(v.Hash)}
+	if v.RSAPSS {
+		opts = append(opts, options.WithRSAPSS(&rsa.PSSOptions{Hash: v.Hash}))
+	}
+	return opts
+}
+
+// Load returns the reference SignerVerifier from package signature for the vector
+func (v Vector) Load() (signature.SignerVerifier, error) {
+	return signature.LoadSignerVerifierFromPEM([]byte(v.PrivateKeyPEM), cryptoutils.SkipPassword, v.LoadOptions()...)
+}
+
+// Check runs sv, which must hold the vector's private key, against the vector. It checks that sv:
+//   - verifies the vector's signature, both over the message and over its precomputed digest;
+//   - rejects the signature over a modified message;
+//   - produces a signature over the message that verifies with the reference verifier for the
+//     vector's public key, and that equals the vector's signature if the algorithm is deterministic.
+func Check(sv signature.SignerVerifier, v Vector) error {
+	if sv == nil {
+		return errors.New("nil SignerVerifier")
+	}
+	ref, err := v.Load()
+	if err != nil {
+		return fmt.Errorf("%s: loading reference key: %w", v.Algorithm, err)
+	}
+	refPub, err := ref.PublicKey()
+	if err != nil {
+		return fmt.Errorf("%s: reading reference public key: %w", v.Algorithm, err)
+	}
+	pub, err := sv.PublicKey()
+	if err != nil {
+		return fmt.Errorf("%s: reading public key: %w", v.Algorithm, err)
+	}
+	if err := cryptoutils.EqualKeys(refPub, pub); err != nil {
+		return fmt.Errorf("%s: public key does not match the vector: %w", v.Algorithm, err)
+	}
+
+	if err := sv.VerifySignature(bytes.NewReader(v.Signature), bytes.NewReader(v.Message)); err != nil {
+		return fmt.Errorf("%s: verifying known-answer signature: %w", v.Algorithm, err)
+	}
+	if v.Digest != nil {
+		if err := sv.VerifySignature(bytes.NewReader(v.Signature), nil, options.WithDigest(v.Digest)); err != nil {
+			return fmt.Errorf("%s: verifying known-answer signature over digest: %w", v.Algorithm, err)
+		}
+	}
+	tampered := append([]byte{}, v.Message...)
+	tampered[0] ^= 0xff
+	if err := sv.VerifySignature(bytes.NewReader(v.Signature), bytes.NewReader(tampered)); err == nil {
+		return fmt.Errorf("%s: signature over a modified message was accepted", v.Algorithm)
+	}
+
+	sig, err := sv.SignMessage(bytes.NewReader(v.Message))
+	if err != nil {
+		return fmt.Errorf("%s: signing: %w", v.Algorithm, err)
+	}
+	if v.Deterministic && !bytes.Equal(sig, v.Signature) {
+		return fmt.Errorf("%s: signature does not match the known answer", v.Algorithm)
+	}
+	if err := ref.VerifySignature(bytes.NewReader(sig), bytes.NewReader(v.Message)); err != nil {
+		return fmt.Errorf("%s: signature rejected by the reference verifier: %w", v.Algorithm, err)
+	}
+	return nil
+}
+
+// CheckAll runs Check for every vector in Vectors, using load to obtain the SignerVerifier under
+// test for each one, and returns all failures joined together
+func CheckAll(load func(Vector) (signature.SignerVerifier, error)) error {
+	var errs []error
+	for _, v := range Vectors() {
+		sv, err := load(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: loading: %w", v.Algorithm, err))
+			continue
+		}
+		if err := Check(sv, v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,95 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testvectors
+
+import (
+	"bytes"
+	"crypto"
+	"io"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+func TestVectors(t *testing.T) {
+	if err := CheckAll(Vector.Load); err != nil {
+		t.Fatalf("reference implementation failed the known-answer tests: %v", err)
+	}
+
+	seen := map[signature.Algorithm]bool{}
+	for _, v := range Vectors() {
+		seen[v.Algorithm] = true
+		if v.Hash != crypto.Hash(0) {
+			h := v.Hash.New()
+			h.Write(v.Message)
+			if !bytes.Equal(h.Sum(nil), v.Digest) {
+				t.Errorf("%s: digest does not match message", v.Algorithm)
+			}
+		}
+	}
+	for _, alg := range []signature.Algorithm{
+		signature.AlgorithmRSAPKCS1v15SHA256,
+		signature.AlgorithmRSAPSSSHA256,
+		signature.AlgorithmECDSAP256SHA256,
+		signature.AlgorithmECDSAP384SHA384,
+		signature.AlgorithmECDSAP521SHA512,
+		signature.AlgorithmED25519,
+	} {
+		if !seen[alg] {
+			t.Errorf("no vector for %s", alg)
+		}
+	}
+}
+
+// brokenSigner signs every message with a fixed, unrelated key
+type brokenSigner struct {
+	signature.SignerVerifier
+	wrong signature.Signer
+}
+
+func (b brokenSigner) SignMessage(message io.Reader, opts ...signature.SignOption) ([]byte, error) {
+	return b.wrong.SignMessage(message, opts...)
+}
+
+func TestCheckDetectsFailures(t *testing.T) {
+	vectors := Vectors()
+	wrong, _, err := signature.NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	for _, v := range vectors {
+		if v.Algorithm != signature.AlgorithmECDSAP256SHA256 {
+			continue
+		}
+		sv, err := v.Load()
+		if err != nil {
+			t.Fatalf("unexpected error loading vector: %v", err)
+		}
+		if err := Check(brokenSigner{SignerVerifier: sv, wrong: wrong}, v); err == nil {
+			t.Error("expected signatures from the wrong key to be detected")
+		}
+		if err := Check(wrong, v); err == nil {
+			t.Error("expected a mismatched public key to be detected")
+		}
+		v.Signature[len(v.Signature)-1] ^= 0xff
+		if err := Check(sv, v); err == nil {
+			t.Error("expected a corrupted known-answer signature to be detected")
+		}
+	}
+	if err := Check(nil, vectors[0]); err == nil {
+		t.Error("expected error for nil SignerVerifier")
+	}
+}
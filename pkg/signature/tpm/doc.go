@@ -0,0 +1,21 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tpm contains a signature.SignerVerifier backed by a key held in a TPM 2.0.
+//
+// It is a separate Go module, github.com/sigstore/sigstore/pkg/signature/tpm,
+// so that consumers that do not need TPM support do not pull in the TPM
+// dependency.
+package tpm
@@ -0,0 +1,26 @@
+module github.com/sigstore/sigstore/pkg/signature/tpm
+
+replace github.com/sigstore/sigstore => ../../../
+
+go 1.22.0
+
+require (
+	github.com/google/go-tpm v0.9.1
+	github.com/sigstore/sigstore v1.6.4
+)
+
+require (
+	github.com/go-jose/go-jose/v4 v4.0.1 // indirect
+	github.com/google/go-containerregistry v0.19.2 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/letsencrypt/boulder v0.0.0-20240620165639-de9c06129bec // indirect
+	github.com/opencontainers/go-digest v1.0.0 // indirect
+	github.com/secure-systems-lab/go-securesystemslib v0.8.0 // indirect
+	github.com/titanous/rocacheck v0.0.0-20171023193734-afe73141d399 // indirect
+	golang.org/x/crypto v0.24.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/term v0.21.0 // indirect
+	google.golang.org/protobuf v1.34.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	software.sslmate.com/src/go-pkcs12 v0.7.3 // indirect
+)
@@ -0,0 +1,259 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpm
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/big"
+	"sync"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpm2/transport"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// ErrTPMNotFound is returned by Open when there is no TPM device at the given path, or at any
+// of the default paths if none is given
+var ErrTPMNotFound = errors.New("no TPM 2.0 device found")
+
+// HandleError is returned by LoadSignerVerifier when the key at a handle cannot be read, e.g.
+// because nothing is persisted at that handle or access to it is denied
+type HandleError struct {
+	Handle uint32
+	Err    error
+}
+
+func (e *HandleError) Error() string {
+	return fmt.Sprintf("reading TPM key at handle 0x%08x: %v", e.Handle, e.Err)
+}
+
+func (e *HandleError) Unwrap() error {
+	return e.Err
+}
+
+var tpmSupportedHashFuncs = []crypto.Hash{
+	crypto.SHA256,
+	crypto.SHA384,
+	crypto.SHA512,
+}
+
+var tpmHashAlgs = map[crypto.Hash]tpm2.TPMAlgID{
+	crypto.SHA256: tpm2.TPMAlgSHA256,
+	crypto.SHA384: tpm2.TPMAlgSHA384,
+	crypto.SHA512: tpm2.TPMAlgSHA512,
+}
+
+// Open opens the TPM at path, which may be a TPM character device (e.g. /dev/tpmrm0) or the
+// Unix domain socket of a software TPM. If path is empty, /dev/tpmrm0 and then /dev/tpm0 are
+// tried. ErrTPMNotFound is returned (wrapped) if the device does not exist.
+func Open(path string) (transport.TPMCloser, error) {
+	var paths []string
+	if path != "" {
+		paths = append(paths, path)
+	}
+	t, err := transport.OpenTPM(paths...)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %w", ErrTPMNotFound, err)
+		}
+		return nil, fmt.Errorf("opening TPM: %w", err)
+	}
+	return t, nil
+}
+
+// SignerVerifier is a signature.SignerVerifier that signs with an ECDSA or RSA key held in a
+// TPM 2.0, so that the private key never leaves the device. Signatures are verified locally
+// with the key's public part.
+//
+// ECDSA signatures are DER-encoded, as for signature.ECDSASigner; RSA keys sign using
+// RSASSA-PKCS1-v1_5. It is safe for concurrent use; commands are sent to the TPM one at a time.
+type SignerVerifier struct {
+	tpm      transport.TPM
+	handle   tpm2.AuthHandle
+	hashFunc crypto.Hash
+	pub      crypto.PublicKey
+	verifier signature.Verifier
+
+	mu sync.Mutex
+}
+
+var _ signature.SignerVerifier = (*SignerVerifier)(nil)
+
+// LoadSignerVerifier returns a SignerVerifier for the signing key loaded or persisted at handle
+// (e.g. 0x81000001) in t, authorizing its use with the password auth, which may be nil for a key
+// without one. hashFunc is used to compute digests unless options.WithCryptoSignerOpts is given,
+// and must be SHA256, SHA384 or SHA512.
+//
+// A *HandleError is returned if the key at handle cannot be read.
+func LoadSignerVerifier(t transport.TPM, handle uint32, auth []byte, hashFunc crypto.Hash) (*SignerVerifier, error) {
+	if t == nil {
+		return nil, errors.New("TPM cannot be nil")
+	}
+	if _, ok := tpmHashAlgs[hashFunc]; !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm: %q not in %v", hashFunc.String(), tpmSupportedHashFuncs)
+	}
+	rsp, err := tpm2.ReadPublic{ObjectHandle: tpm2.TPMHandle(handle)}.Execute(t)
+	if err != nil {
+		return nil, &HandleError{Handle: handle, Err: err}
+	}
+	pub, err := publicKey(rsp.OutPublic)
+	if err != nil {
+		return nil, &HandleError{Handle: handle, Err: err}
+	}
+	verifier, err := signature.LoadVerifier(pub, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+	return &SignerVerifier{
+		tpm: t,
+		handle: tpm2.AuthHandle{
+			Handle: tpm2.TPMHandle(handle),
+			Name:   rsp.Name,
+			Auth:   tpm2.PasswordAuth(auth),
+		},
+		hashFunc: hashFunc,
+		pub:      pub,
+		verifier: verifier,
+	}, nil
+}
+
+// publicKey converts the public area of a TPM signing key to a crypto.PublicKey
+func publicKey(public tpm2.TPM2BPublic) (crypto.PublicKey, error) {
+	pub, err := public.Contents()
+	if err != nil {
+		return nil, err
+	}
+	if !pub.ObjectAttributes.SignEncrypt {
+		return nil, errors.New("key is not a signing key")
+	}
+	switch pub.Type {
+	case tpm2.TPMAlgECC:
+		params, err := pub.Parameters.ECCDetail()
+		if err != nil {
+			return nil, err
+		}
+		curve, err := params.CurveID.Curve()
+		if err != nil {
+			return nil, err
+		}
+		point, err := pub.Unique.ECC()
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(point.X.Buffer),
+			Y:     new(big.Int).SetBytes(point.Y.Buffer),
+		}, nil
+	case tpm2.TPMAlgRSA:
+		params, err := pub.Parameters.RSADetail()
+		if err != nil {
+			return nil, err
+		}
+		modulus, err := pub.Unique.RSA()
+		if err != nil {
+			return nil, err
+		}
+		return tpm2.RSAPub(params, modulus)
+	}
+	return nil, fmt.Errorf("unsupported TPM key type 0x%04x", uint16(pub.Type))
+}
+
+// PublicKey returns the public key of the TPM key. As this value is held in memory, all options
+// provided in arguments to this method are ignored.
+func (s *SignerVerifier) PublicKey(_ ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	return s.pub, nil
+}
+
+// SignMessage signs the provided message with the TPM key. If the message is provided,
+// this method will compute the digest according to the hash function specified
+// when the SignerVerifier was created.
+//
+// This function recognizes the following Options listed in order of preference:
+//
+// - WithDigest()
+//
+// - WithCryptoSignerOpts()
+//
+// All other options are ignored if specified.
+func (s *SignerVerifier) SignMessage(message io.Reader, opts ...signature.SignOption) ([]byte, error) {
+	digest, hf, err := signature.ComputeDigestForSigning(message, s.hashFunc, tpmSupportedHashFuncs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s.signDigest(digest, hf)
+}
+
+func (s *SignerVerifier) signDigest(digest []byte, hf crypto.Hash) ([]byte, error) {
+	hashAlg := tpmHashAlgs[hf]
+	var scheme tpm2.TPMTSigScheme
+	switch s.pub.(type) {
+	case *ecdsa.PublicKey:
+		scheme = tpm2.TPMTSigScheme{
+			Scheme:  tpm2.TPMAlgECDSA,
+			Details: tpm2.NewTPMUSigScheme(tpm2.TPMAlgECDSA, &tpm2.TPMSSchemeHash{HashAlg: hashAlg}),
+		}
+	case *rsa.PublicKey:
+		scheme = tpm2.TPMTSigScheme{
+			Scheme:  tpm2.TPMAlgRSASSA,
+			Details: tpm2.NewTPMUSigScheme(tpm2.TPMAlgRSASSA, &tpm2.TPMSSchemeHash{HashAlg: hashAlg}),
+		}
+	}
+
+	s.mu.Lock()
+	rsp, err := tpm2.Sign{
+		KeyHandle:  s.handle,
+		Digest:     tpm2.TPM2BDigest{Buffer: digest},
+		InScheme:   scheme,
+		Validation: tpm2.TPMTTKHashCheck{Tag: tpm2.TPMSTHashCheck},
+	}.Execute(s.tpm)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("signing with TPM key at handle 0x%08x: %w", uint32(s.handle.Handle), err)
+	}
+
+	switch rsp.Signature.SigAlg {
+	case tpm2.TPMAlgECDSA:
+		sig, err := rsp.Signature.Signature.ECDSA()
+		if err != nil {
+			return nil, err
+		}
+		return cryptoutils.MarshalECDSASignature(new(big.Int).SetBytes(sig.SignatureR.Buffer), new(big.Int).SetBytes(sig.SignatureS.Buffer))
+	case tpm2.TPMAlgRSASSA:
+		sig, err := rsp.Signature.Signature.RSASSA()
+		if err != nil {
+			return nil, err
+		}
+		return sig.Sig.Buffer, nil
+	}
+	return nil, fmt.Errorf("unexpected TPM signature algorithm 0x%04x", uint16(rsp.Signature.SigAlg))
+}
+
+// VerifySignature verifies the signature for the given message locally with the public key of
+// the TPM key. Unless provided in an option, the digest of the message will be computed using
+// the hash function specified when the SignerVerifier was created.
+//
+// This function returns nil if the verification succeeded, and an error message otherwise.
+func (s *SignerVerifier) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
+	return s.verifier.VerifySignature(sig, message, opts...)
+}
@@ -0,0 +1,161 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpm
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpm2/transport"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// openTestTPM opens the TPM (typically a swtpm socket) named by SIGSTORE_TEST_TPM, skipping
+// the test if it is not set
+func openTestTPM(t *testing.T) transport.TPM {
+	t.Helper()
+	path := os.Getenv("SIGSTORE_TEST_TPM")
+	if path == "" {
+		t.Skip("SIGSTORE_TEST_TPM not set")
+	}
+	tpm, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening TPM: %v", err)
+	}
+	t.Cleanup(func() { tpm.Close() })
+	return tpm
+}
+
+// createSigningKey creates a transient signing key in the owner hierarchy and returns its handle
+func createSigningKey(t *testing.T, tpm transport.TPM, template tpm2.TPMTPublic) uint32 {
+	t.Helper()
+	rsp, err := tpm2.CreatePrimary{
+		PrimaryHandle: tpm2.TPMRHOwner,
+		InPublic:      tpm2.New2B(template),
+	}.Execute(tpm)
+	if err != nil {
+		t.Fatalf("unexpected error creating key: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = tpm2.FlushContext{FlushHandle: rsp.ObjectHandle}.Execute(tpm)
+	})
+	return uint32(rsp.ObjectHandle)
+}
+
+var signingAttributes = tpm2.TPMAObject{
+	SignEncrypt:         true,
+	FixedTPM:            true,
+	FixedParent:         true,
+	SensitiveDataOrigin: true,
+	UserWithAuth:        true,
+}
+
+func TestSignerVerifier(t *testing.T) {
+	tpm := openTestTPM(t)
+	for name, template := range map[string]tpm2.TPMTPublic{
+		"ecdsa": {
+			Type:             tpm2.TPMAlgECC,
+			NameAlg:          tpm2.TPMAlgSHA256,
+			ObjectAttributes: signingAttributes,
+			Parameters: tpm2.NewTPMUPublicParms(tpm2.TPMAlgECC, &tpm2.TPMSECCParms{
+				CurveID: tpm2.TPMECCNistP256,
+			}),
+		},
+		"rsa": {
+			Type:             tpm2.TPMAlgRSA,
+			NameAlg:          tpm2.TPMAlgSHA256,
+			ObjectAttributes: signingAttributes,
+			Parameters: tpm2.NewTPMUPublicParms(tpm2.TPMAlgRSA, &tpm2.TPMSRSAParms{
+				KeyBits: 2048,
+			}),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handle := createSigningKey(t, tpm, template)
+			sv, err := LoadSignerVerifier(tpm, handle, nil, crypto.SHA256)
+			if err != nil {
+				t.Fatalf("unexpected error loading signer/verifier: %v", err)
+			}
+
+			message := []byte("sign me")
+			sig, err := sv.SignMessage(bytes.NewReader(message))
+			if err != nil {
+				t.Fatalf("unexpected error signing: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+				t.Errorf("unexpected error verifying: %v", err)
+			}
+			// the signature verifies independently of the TPM
+			pub, err := sv.PublicKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := signature.LoadVerifier(pub, crypto.SHA256)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+				t.Errorf("unexpected error verifying with public key: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("tampered"))); err == nil {
+				t.Error("expected error verifying tampered message")
+			}
+
+			digest := sha256.Sum256(message)
+			sig, err = sv.SignMessage(nil, options.WithDigest(digest[:]))
+			if err != nil {
+				t.Fatalf("unexpected error signing digest: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+				t.Errorf("unexpected error verifying signature over digest: %v", err)
+			}
+			sig, err = sv.SignMessage(bytes.NewReader(message), options.WithCryptoSignerOpts(crypto.SHA384))
+			if err != nil {
+				t.Fatalf("unexpected error signing with SHA384: %v", err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message), options.WithCryptoSignerOpts(crypto.SHA384)); err != nil {
+				t.Errorf("unexpected error verifying SHA384 signature: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadSignerVerifierErrors(t *testing.T) {
+	tpm := openTestTPM(t)
+	var handleErr *HandleError
+	if _, err := LoadSignerVerifier(tpm, 0x81fffff0, nil, crypto.SHA256); !errors.As(err, &handleErr) || handleErr.Handle != 0x81fffff0 {
+		t.Errorf("expected *HandleError for empty handle, got %v", err)
+	}
+	if _, err := LoadSignerVerifier(tpm, 0x81fffff0, nil, crypto.SHA1); err == nil {
+		t.Error("expected error for unsupported hash function")
+	}
+	if _, err := LoadSignerVerifier(nil, 0x81000001, nil, crypto.SHA256); err == nil {
+		t.Error("expected error for nil TPM")
+	}
+}
+
+func TestOpenMissing(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "tpm0")); !errors.Is(err, ErrTPMNotFound) {
+		t.Errorf("expected ErrTPMNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tsa
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// maxResponseSize bounds the size of a TSA response; tokens hold a signature and a short
+// certificate chain, so anything larger is treated as an error
+const maxResponseSize = 1 << 20
+
+var hashOIDs = map[crypto.Hash]asn1.ObjectIdentifier{
+	crypto.SHA256: {2, 16, 840, 1, 101, 3, 4, 2, 1},
+	crypto.SHA384: {2, 16, 840, 1, 101, 3, 4, 2, 2},
+	crypto.SHA512: {2, 16, 840, 1, 101, 3, 4, 2, 3},
+}
+
+func hashForOID(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	for hf, o := range hashOIDs {
+		if o.Equal(oid) {
+			return hf, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported digest algorithm %v", oid)
+}
+
+// RequestError is returned when a timestamp could not be obtained from the TSA: the request
+// failed to reach it, it returned an HTTP error, it rejected the request or its response was
+// invalid. It is never returned for a failure to sign the message itself.
+type RequestError struct {
+	URL string
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("requesting timestamp from %s: %v", e.URL, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Client requests timestamps from an RFC 3161 Time-Stamp Authority over HTTP
+type Client struct {
+	url        string
+	httpClient *http.Client
+	hashFunc   crypto.Hash
+}
+
+// NewClient returns a Client for the TSA at url that hashes the data to be timestamped with
+// hashFunc (SHA-256, SHA-384 or SHA-512). If httpClient is nil, http.DefaultClient is used.
+func NewClient(url string, hashFunc crypto.Hash, httpClient *http.Client) (*Client, error) {
+	if url == "" {
+		return nil, errors.New("TSA URL must be provided")
+	}
+	if _, ok := hashOIDs[hashFunc]; !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm: %q", hashFunc.String())
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{url: url, httpClient: httpClient, hashFunc: hashFunc}, nil
+}
+
+// Timestamp requests a timestamp over data and returns the DER-encoded TimeStampToken. The
+// response is checked to be for this request (its message imprint and nonce must match), but the
+// token is not verified; use VerifyTimestamp for that. All failures are reported as a *RequestError.
+func (c *Client) Timestamp(ctx context.Context, data []byte) ([]byte, error) {
+	token, err := c.timestamp(ctx, data)
+	if err != nil {
+		return nil, &RequestError{URL: c.url, Err: err}
+	}
+	return token, nil
+}
+
+func (c *Client) timestamp(ctx context.Context, data []byte) ([]byte, error) {
+	h := c.hashFunc.New()
+	h.Write(data)
+	digest := h.Sum(nil)
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+	req, err := marshalRequest(c.hashFunc, digest, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/timestamp-query")
+	httpReq.Header.Set("Accept", "application/timestamp-reply")
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if len(body) > maxResponseSize {
+		return nil, errors.New("response too large")
+	}
+
+	token, err := parseResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if t.info.nonce == nil || t.info.nonce.Cmp(nonce) != 0 {
+		return nil, errors.New("timestamp nonce does not match the request")
+	}
+	if t.info.imprintHash != c.hashFunc || !bytes.Equal(t.info.imprint, digest) {
+		return nil, errors.New("timestamp message imprint does not match the request")
+	}
+	return token, nil
+}
+
+func marshalRequest(hf crypto.Hash, digest []byte, nonce *big.Int) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { // TimeStampReq
+		b.AddASN1Int64(1)
+		b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { // MessageImprint
+			b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				b.AddASN1ObjectIdentifier(hashOIDs[hf])
+			})
+			b.AddASN1OctetString(digest)
+		})
+		b.AddASN1BigInt(nonce)
+		// certReq, so that the token can be verified without knowing the TSA certificate
+		b.AddASN1Boolean(true)
+	})
+	return b.Bytes()
+}
+
+// PKIStatus values that indicate a timestamp was issued (RFC 3161, section 2.4.2)
+const (
+	statusGranted         = 0
+	statusGrantedWithMods = 1
+)
+
+func parseResponse(der []byte) ([]byte, error) {
+	var resp, statusInfo cryptobyte.String
+	var status int64
+	input := cryptobyte.String(der)
+	if !input.ReadASN1(&resp, cbasn1.SEQUENCE) || !input.Empty() ||
+		!resp.ReadASN1(&statusInfo, cbasn1.SEQUENCE) ||
+		!statusInfo.ReadASN1Integer(&status) {
+		return nil, errors.New("malformed TimeStampResp")
+	}
+	if status != statusGranted && status != statusGrantedWithMods {
+		var text []string
+		var freeText cryptobyte.String
+		if statusInfo.PeekASN1Tag(cbasn1.SEQUENCE) && statusInfo.ReadASN1(&freeText, cbasn1.SEQUENCE) {
+			for !freeText.Empty() {
+				var s cryptobyte.String
+				if !freeText.ReadASN1(&s, cbasn1.UTF8String) {
+					break
+				}
+				text = append(text, string(s))
+			}
+		}
+		return nil, fmt.Errorf("timestamp request rejected with status %d: %s", status, strings.Join(text, "; "))
+	}
+	var token cryptobyte.String
+	if !resp.ReadASN1Element(&token, cbasn1.SEQUENCE) || !resp.Empty() {
+		return nil, errors.New("malformed TimeStampResp: missing TimeStampToken")
+	}
+	return token, nil
+}
+
+// TimestampedSignature is a signature bundled with an RFC 3161 timestamp over it
+type TimestampedSignature struct {
+	Signature []byte `json:"signature"`
+	// TimestampToken is the DER-encoded TimeStampToken over Signature
+	TimestampToken []byte `json:"timestampToken"`
+}
+
+// SignAndTimestamp signs message with s and obtains a timestamp over the resulting signature
+// from c. Signing failures are returned as is, wrapped with "signing:"; failures to obtain the
+// timestamp are returned as a *RequestError, so callers can tell them apart (e.g. to retry).
+func SignAndTimestamp(ctx context.Context, s signature.Signer, c *Client, message io.Reader, opts ...signature.SignOption) (*TimestampedSignature, error) {
+	sig, err := s.SignMessage(message, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	token, err := c.Timestamp(ctx, sig)
+	if err != nil {
+		return nil, err
+	}
+	return &TimestampedSignature{Signature: sig, TimestampToken: token}, nil
+}
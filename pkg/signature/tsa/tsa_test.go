@@ -0,0 +1,418 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tsa
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+var (
+	oidSignedData      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+
+	tagContext0 = cbasn1.Tag(0).ContextSpecific().Constructed()
+)
+
+// testTSA is a minimal RFC 3161 TSA that signs timestamps with an ECDSA P-256 key
+type testTSA struct {
+	t      *testing.T
+	roots  *x509.CertPool
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	server *httptest.Server
+
+	// knobs for failure cases
+	httpStatus    int
+	status        int64
+	badNonce      bool
+	badImprint    bool
+	trailingData  bool
+	genTimeOffset time.Duration
+}
+
+func newCertificate(t *testing.T, template, parent *x509.Certificate, pub crypto.PublicKey, priv crypto.Signer) *x509.Certificate {
+	t.Helper()
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+	if err != nil {
+		t.Fatalf("unexpected error creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error parsing certificate: %v", err)
+	}
+	return cert
+}
+
+func newTestTSA(t *testing.T, criticalEKU bool) *testTSA {
+	t.Helper()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test TSA root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	ca := newCertificate(t, caTemplate, caTemplate, caKey.Public(), caKey)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test TSA"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}
+	if criticalEKU {
+		value, _ := asn1.Marshal([]asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 8}})
+		template.ExtraExtensions = []pkix.Extension{{Id: oidExtKeyUsage, Critical: true, Value: value}}
+	}
+
+	tsa := &testTSA{
+		t:          t,
+		roots:      x509.NewCertPool(),
+		cert:       newCertificate(t, template, ca, key.Public(), caKey),
+		key:        key,
+		httpStatus: http.StatusOK,
+	}
+	tsa.roots.AddCert(ca)
+	tsa.server = httptest.NewServer(tsa)
+	t.Cleanup(tsa.server.Close)
+	return tsa
+}
+
+func (tsa *testTSA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") != "application/timestamp-query" {
+		http.Error(w, "unexpected content type", http.StatusBadRequest)
+		return
+	}
+	if tsa.httpStatus != http.StatusOK {
+		http.Error(w, "unavailable", tsa.httpStatus)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+
+	var req, imprint, alg, digest cryptobyte.String
+	var version int64
+	var hashOID asn1.ObjectIdentifier
+	nonce := new(big.Int)
+	input := cryptobyte.String(body)
+	if !input.ReadASN1(&req, cbasn1.SEQUENCE) ||
+		!req.ReadASN1Integer(&version) ||
+		!req.ReadASN1(&imprint, cbasn1.SEQUENCE) ||
+		!imprint.ReadASN1(&alg, cbasn1.SEQUENCE) ||
+		!alg.ReadASN1ObjectIdentifier(&hashOID) ||
+		!imprint.ReadASN1(&digest, cbasn1.OCTET_STRING) ||
+		!req.ReadASN1Integer(nonce) {
+		http.Error(w, "malformed request", http.StatusBadRequest)
+		return
+	}
+	if tsa.badNonce {
+		nonce.Add(nonce, big.NewInt(1))
+	}
+	if tsa.badImprint {
+		digest = append(cryptobyte.String{}, digest...)
+		digest[0] ^= 0xff
+	}
+
+	var resp cryptobyte.Builder
+	resp.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1Int64(tsa.status)
+			if tsa.status != statusGranted {
+				b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+					b.AddASN1(cbasn1.UTF8String, func(b *cryptobyte.Builder) { b.AddBytes([]byte("policy not supported")) })
+				})
+			}
+		})
+		if tsa.status == statusGranted {
+			b.AddBytes(tsa.token(hashOID, digest, nonce))
+		}
+	})
+	if tsa.trailingData {
+		resp.AddUint8(0)
+	}
+	w.Header().Set("Content-Type", "application/timestamp-reply")
+	w.Write(resp.BytesOrPanic())
+}
+
+func (tsa *testTSA) token(hashOID asn1.ObjectIdentifier, digest []byte, nonce *big.Int) []byte {
+	var info cryptobyte.Builder
+	info.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1Int64(1)
+		b.AddASN1ObjectIdentifier(asn1.ObjectIdentifier{1, 2, 3, 4})
+		b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { b.AddASN1ObjectIdentifier(hashOID) })
+			b.AddASN1OctetString(digest)
+		})
+		b.AddASN1Int64(42)
+		// with fractional seconds, as many TSAs produce
+		b.AddASN1(cbasn1.GeneralizedTime, func(b *cryptobyte.Builder) {
+			b.AddBytes([]byte(time.Now().Add(tsa.genTimeOffset).UTC().Format("20060102150405.000Z")))
+		})
+		b.AddASN1BigInt(nonce)
+	})
+	tstInfo := info.BytesOrPanic()
+
+	infoDigest := sha256.Sum256(tstInfo)
+	attrs := [][]byte{
+		attribute(oidContentType, func(b *cryptobyte.Builder) { b.AddASN1ObjectIdentifier(oidTSTInfo) }),
+		attribute(oidMessageDigest, func(b *cryptobyte.Builder) { b.AddASN1OctetString(infoDigest[:]) }),
+	}
+	sort.Slice(attrs, func(i, j int) bool { return bytes.Compare(attrs[i], attrs[j]) < 0 })
+	var signed cryptobyte.Builder
+	signed.AddASN1(cbasn1.SET, func(b *cryptobyte.Builder) { b.AddBytes(bytes.Join(attrs, nil)) })
+	attrsDigest := sha256.Sum256(signed.BytesOrPanic())
+	sig, err := ecdsa.SignASN1(rand.Reader, tsa.key, attrsDigest[:])
+	if err != nil {
+		tsa.t.Errorf("signing timestamp: %v", err)
+	}
+
+	var token cryptobyte.Builder
+	token.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1ObjectIdentifier(oidSignedData)
+		b.AddASN1(tagContext0, func(b *cryptobyte.Builder) {
+			b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				b.AddASN1Int64(3)
+				b.AddASN1(cbasn1.SET, func(b *cryptobyte.Builder) {
+					b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { b.AddASN1ObjectIdentifier(hashOIDs[crypto.SHA256]) })
+				})
+				b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+					b.AddASN1ObjectIdentifier(oidTSTInfo)
+					b.AddASN1(tagContext0, func(b *cryptobyte.Builder) { b.AddASN1OctetString(tstInfo) })
+				})
+				b.AddASN1(tagContext0, func(b *cryptobyte.Builder) { b.AddBytes(tsa.cert.Raw) })
+				b.AddASN1(cbasn1.SET, func(b *cryptobyte.Builder) {
+					b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+						b.AddASN1Int64(1)
+						b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+							b.AddBytes(tsa.cert.RawIssuer)
+							b.AddASN1BigInt(tsa.cert.SerialNumber)
+						})
+						b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { b.AddASN1ObjectIdentifier(hashOIDs[crypto.SHA256]) })
+						b.AddASN1(tagContext0, func(b *cryptobyte.Builder) { b.AddBytes(bytes.Join(attrs, nil)) })
+						b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) { b.AddASN1ObjectIdentifier(oidECDSAWithSHA256) })
+						b.AddASN1OctetString(sig)
+					})
+				})
+			})
+		})
+	})
+	return token.BytesOrPanic()
+}
+
+func attribute(oid asn1.ObjectIdentifier, value cryptobyte.BuilderContinuation) []byte {
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1ObjectIdentifier(oid)
+		b.AddASN1(cbasn1.SET, value)
+	})
+	return b.BytesOrPanic()
+}
+
+func TestSignAndTimestamp(t *testing.T) {
+	tsa := newTestTSA(t, true)
+	client, err := NewClient(tsa.server.URL, crypto.SHA256, tsa.server.Client())
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %v", err)
+	}
+	sv, _, err := signature.NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	message := []byte("sign and timestamp me")
+
+	ts, err := SignAndTimestamp(context.Background(), sv, client, bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error signing and timestamping: %v", err)
+	}
+	// the bundle survives a JSON round trip
+	encoded, err := json.Marshal(ts)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling: %v", err)
+	}
+	decoded := &TimestampedSignature{}
+	if err := json.Unmarshal(encoded, decoded); err != nil {
+		t.Fatalf("unexpected error unmarshalling: %v", err)
+	}
+
+	opts := VerifyOptions{Roots: tsa.roots}
+	stamp, err := Verify(sv, decoded, bytes.NewReader(message), opts)
+	if err != nil {
+		t.Fatalf("unexpected error verifying: %v", err)
+	}
+	if d := time.Since(stamp.Time); d < 0 || d > time.Minute {
+		t.Errorf("unexpected timestamp time %v", stamp.Time)
+	}
+	if !stamp.Certificate.Equal(tsa.cert) {
+		t.Error("unexpected TSA certificate")
+	}
+	if stamp.SerialNumber.Int64() != 42 {
+		t.Errorf("unexpected serial number %v", stamp.SerialNumber)
+	}
+
+	if _, err := Verify(sv, decoded, bytes.NewReader([]byte("other message")), opts); err == nil {
+		t.Error("expected error verifying a different message")
+	}
+	if _, err := VerifyTimestamp(ts.TimestampToken, []byte("other signature"), opts); err == nil {
+		t.Error("expected error verifying a timestamp over different data")
+	}
+	if _, err := VerifyTimestamp(ts.TimestampToken, ts.Signature, VerifyOptions{Roots: x509.NewCertPool()}); err == nil {
+		t.Error("expected error verifying with untrusted roots")
+	}
+	tampered := bytes.Clone(ts.TimestampToken)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := VerifyTimestamp(tampered, ts.Signature, opts); err == nil {
+		t.Error("expected error verifying a tampered token")
+	}
+	if _, err := VerifyTimestamp(append(bytes.Clone(ts.TimestampToken), 0), ts.Signature, opts); err == nil {
+		t.Error("expected error verifying a token with trailing data")
+	}
+}
+
+func TestVerifyTimestampClockSkew(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		offset time.Duration
+	}{
+		{"before validity", -time.Hour - 30*time.Second},
+		{"after validity", time.Hour + 30*time.Second},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tsa := newTestTSA(t, true)
+			tsa.genTimeOffset = tt.offset
+			client, _ := NewClient(tsa.server.URL, crypto.SHA256, tsa.server.Client())
+			token, err := client.Timestamp(context.Background(), []byte("data"))
+			if err != nil {
+				t.Fatalf("unexpected error timestamping: %v", err)
+			}
+			if _, err := VerifyTimestamp(token, []byte("data"), VerifyOptions{Roots: tsa.roots}); err == nil {
+				t.Error("expected error for a timestamp outside the TSA certificate's validity")
+			}
+			if _, err := VerifyTimestamp(token, []byte("data"), VerifyOptions{Roots: tsa.roots, ClockSkew: time.Minute}); err != nil {
+				t.Errorf("unexpected error for a timestamp within the clock skew: %v", err)
+			}
+			if _, err := VerifyTimestamp(token, []byte("data"), VerifyOptions{Roots: tsa.roots, ClockSkew: 10 * time.Second}); err == nil {
+				t.Error("expected error for a timestamp beyond the clock skew")
+			}
+		})
+	}
+}
+
+func TestVerifyTimestampRequiresCriticalEKU(t *testing.T) {
+	tsa := newTestTSA(t, false)
+	client, _ := NewClient(tsa.server.URL, crypto.SHA256, tsa.server.Client())
+	token, err := client.Timestamp(context.Background(), []byte("data"))
+	if err != nil {
+		t.Fatalf("unexpected error timestamping: %v", err)
+	}
+	if _, err := VerifyTimestamp(token, []byte("data"), VerifyOptions{Roots: tsa.roots}); err == nil || !strings.Contains(err.Error(), "critical") {
+		t.Errorf("expected error for non-critical extended key usage, got %v", err)
+	}
+}
+
+type failingSigner struct {
+	signature.Signer
+}
+
+func (failingSigner) SignMessage(_ io.Reader, _ ...signature.SignOption) ([]byte, error) {
+	return nil, errors.New("signing backend unavailable")
+}
+
+func TestTimestampErrors(t *testing.T) {
+	sv, _, _ := signature.NewDefaultECDSASignerVerifier()
+	message := []byte("message")
+
+	for _, tt := range []struct {
+		name  string
+		setup func(*testTSA)
+		want  string
+	}{
+		{"http error", func(tsa *testTSA) { tsa.httpStatus = http.StatusServiceUnavailable }, "503"},
+		{"rejected", func(tsa *testTSA) { tsa.status = 2 }, "policy not supported"},
+		{"waiting", func(tsa *testTSA) { tsa.status = 3 }, "status 3"},
+		{"nonce mismatch", func(tsa *testTSA) { tsa.badNonce = true }, "nonce"},
+		{"imprint mismatch", func(tsa *testTSA) { tsa.badImprint = true }, "imprint"},
+		{"trailing data", func(tsa *testTSA) { tsa.trailingData = true }, "malformed TimeStampResp"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tsa := newTestTSA(t, true)
+			tt.setup(tsa)
+			client, _ := NewClient(tsa.server.URL, crypto.SHA256, tsa.server.Client())
+			_, err := SignAndTimestamp(context.Background(), sv, client, bytes.NewReader(message))
+			var reqErr *RequestError
+			if !errors.As(err, &reqErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected *RequestError containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		tsa := newTestTSA(t, true)
+		client, _ := NewClient(tsa.server.URL, crypto.SHA256, tsa.server.Client())
+		tsa.server.Close()
+		var reqErr *RequestError
+		if _, err := SignAndTimestamp(context.Background(), sv, client, bytes.NewReader(message)); !errors.As(err, &reqErr) {
+			t.Errorf("expected *RequestError, got %v", err)
+		}
+	})
+
+	t.Run("signing failure", func(t *testing.T) {
+		tsa := newTestTSA(t, true)
+		client, _ := NewClient(tsa.server.URL, crypto.SHA256, tsa.server.Client())
+		_, err := SignAndTimestamp(context.Background(), failingSigner{sv}, client, bytes.NewReader(message))
+		var reqErr *RequestError
+		if err == nil || errors.As(err, &reqErr) {
+			t.Errorf("expected a signing error that is not a *RequestError, got %v", err)
+		}
+	})
+
+	if _, err := NewClient("", crypto.SHA256, nil); err == nil {
+		t.Error("expected error for empty URL")
+	}
+	if _, err := NewClient("https://tsa.example.com", crypto.SHA1, nil); err == nil {
+		t.Error("expected error for unsupported hash function")
+	}
+}
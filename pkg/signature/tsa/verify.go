@@ -0,0 +1,287 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/cms"
+)
+
+var (
+	oidTSTInfo     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidExtKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37}
+)
+
+const generalizedTimeFormat = "20060102150405Z0700"
+
+// VerifyOptions configures the verification of a timestamp
+type VerifyOptions struct {
+	// Roots are the trusted roots for the TSA certificate
+	Roots *x509.CertPool
+	// Intermediates are additional certificates that may be used to chain the TSA certificate to
+	// one of Roots; the certificates included in the token are always used
+	Intermediates *x509.CertPool
+	// TSACertificate is the TSA's signing certificate, required if the token does not include it
+	TSACertificate *x509.Certificate
+	// ClockSkew is how far the time of the timestamp may fall outside the validity period of the
+	// TSA certificate, to tolerate skew between the TSA's clock and that of the CA that issued
+	// its certificate; zero requires the time to be within the validity period
+	ClockSkew time.Duration
+}
+
+// Timestamp holds the verified contents of a timestamp token
+type Timestamp struct {
+	// Time is the time at which the TSA issued the timestamp
+	Time time.Time
+	// SerialNumber is the serial number the TSA assigned to the timestamp
+	SerialNumber *big.Int
+	// Policy is the TSA policy under which the timestamp was issued
+	Policy asn1.ObjectIdentifier
+	// Certificate is the TSA certificate that signed the timestamp
+	Certificate *x509.Certificate
+}
+
+// VerifyTimestamp verifies that token is an RFC 3161 TimeStampToken over data, signed by a TSA
+// certificate that chains to opts.Roots, was valid at the time of the timestamp (within
+// opts.ClockSkew) and has the time-stamping extended key usage as its only, critical, extended key
+// usage.
+//
+// TSA keys may be RSA (PKCS #1 v1.5), ECDSA or Ed25519, with SHA-256, SHA-384 or SHA-512 as the
+// digest algorithm. The ESS signing-certificate attribute is not checked; the TSA certificate is
+// identified by the issuer and serial number or subject key identifier in the token's SignerInfo.
+func VerifyTimestamp(token, data []byte, opts VerifyOptions) (*Timestamp, error) {
+	if opts.Roots == nil {
+		return nil, errors.New("TSA roots must be provided")
+	}
+	t, err := parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	h := t.info.imprintHash.New()
+	h.Write(data)
+	if !bytes.Equal(h.Sum(nil), t.info.imprint) {
+		return nil, errors.New("timestamp message imprint does not match the data")
+	}
+
+	cert := t.sd.SignerCertificate()
+	if cert == nil {
+		cert = opts.TSACertificate
+	}
+	if cert == nil {
+		return nil, errors.New("TSA certificate not found in the token and not provided")
+	}
+	if err := t.checkSignature(cert); err != nil {
+		return nil, err
+	}
+	if err := checkTimestampingEKU(cert); err != nil {
+		return nil, err
+	}
+
+	if err := cryptoutils.CheckExpirationWithSkew(cert, t.info.genTime, opts.ClockSkew); err != nil {
+		return nil, fmt.Errorf("verifying TSA certificate: %w", err)
+	}
+	// the TSA certificate's own validity has been checked above, with the skew tolerance, so the
+	// chain is verified at the closest time within it
+	verifyTime := t.info.genTime
+	if verifyTime.Before(cert.NotBefore) {
+		verifyTime = cert.NotBefore
+	} else if verifyTime.After(cert.NotAfter) {
+		verifyTime = cert.NotAfter
+	}
+
+	intermediates := x509.NewCertPool()
+	if opts.Intermediates != nil {
+		intermediates = opts.Intermediates.Clone()
+	}
+	for _, c := range t.sd.Certificates {
+		if c != cert {
+			intermediates.AddCert(c)
+		}
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         opts.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   verifyTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}); err != nil {
+		return nil, fmt.Errorf("verifying TSA certificate: %w", err)
+	}
+
+	return &Timestamp{
+		Time:         t.info.genTime,
+		SerialNumber: t.info.serial,
+		Policy:       t.info.policy,
+		Certificate:  cert,
+	}, nil
+}
+
+// Verify verifies ts.Signature over message with v and then the timestamp over the signature,
+// returning the verified timestamp
+func Verify(v signature.Verifier, ts *TimestampedSignature, message io.Reader, opts VerifyOptions, verifyOpts ...signature.VerifyOption) (*Timestamp, error) {
+	if ts == nil {
+		return nil, errors.New("nil timestamped signature")
+	}
+	if err := v.VerifySignature(bytes.NewReader(ts.Signature), message, verifyOpts...); err != nil {
+		return nil, err
+	}
+	return VerifyTimestamp(ts.TimestampToken, ts.Signature, opts)
+}
+
+// RFC 3161, section 2.3: the TSA certificate must have a single, critical, time-stamping extended key usage
+func checkTimestampingEKU(cert *x509.Certificate) error {
+	if len(cert.ExtKeyUsage) != 1 || cert.ExtKeyUsage[0] != x509.ExtKeyUsageTimeStamping || len(cert.UnknownExtKeyUsage) != 0 {
+		return errors.New("TSA certificate must have time stamping as its only extended key usage")
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidExtKeyUsage) && ext.Critical {
+			return nil
+		}
+	}
+	return errors.New("TSA certificate extended key usage must be critical")
+}
+
+type tstInfo struct {
+	policy      asn1.ObjectIdentifier
+	imprintHash crypto.Hash
+	imprint     []byte
+	serial      *big.Int
+	genTime     time.Time
+	nonce       *big.Int
+}
+
+type timestampToken struct {
+	sd     *cms.SignedData
+	digest crypto.Hash
+	info   tstInfo
+}
+
+func parseToken(der []byte) (*timestampToken, error) {
+	sd, err := cms.ParseSignedData(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing TimeStampToken: %w", err)
+	}
+	if !sd.ContentType.Equal(oidTSTInfo) {
+		return nil, fmt.Errorf("unexpected encapsulated content type %v", sd.ContentType)
+	}
+	if sd.Content == nil {
+		return nil, errors.New("TimeStampToken has no encapsulated TSTInfo")
+	}
+	digest, err := hashForOID(sd.DigestAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	info, err := parseTSTInfo(sd.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &timestampToken{sd: sd, digest: digest, info: *info}, nil
+}
+
+func parseTSTInfo(der []byte) (*tstInfo, error) {
+	var info, imprint, imprintAlg, hashedMessage, genTime cryptobyte.String
+	var version int64
+	var hashOID asn1.ObjectIdentifier
+	out := &tstInfo{serial: new(big.Int)}
+
+	input := cryptobyte.String(der)
+	if !input.ReadASN1(&info, cbasn1.SEQUENCE) || !input.Empty() ||
+		!info.ReadASN1Integer(&version) ||
+		!info.ReadASN1ObjectIdentifier(&out.policy) ||
+		!info.ReadASN1(&imprint, cbasn1.SEQUENCE) ||
+		!imprint.ReadASN1(&imprintAlg, cbasn1.SEQUENCE) ||
+		!imprintAlg.ReadASN1ObjectIdentifier(&hashOID) ||
+		!imprint.ReadASN1(&hashedMessage, cbasn1.OCTET_STRING) || !imprint.Empty() ||
+		!info.ReadASN1Integer(out.serial) ||
+		!info.ReadASN1(&genTime, cbasn1.GeneralizedTime) ||
+		!info.SkipOptionalASN1(cbasn1.SEQUENCE) || // accuracy
+		!info.SkipOptionalASN1(cbasn1.BOOLEAN) { // ordering
+		return nil, errors.New("malformed TSTInfo")
+	}
+	if version != 1 {
+		return nil, fmt.Errorf("unsupported TSTInfo version %d", version)
+	}
+	var err error
+	if out.imprintHash, err = hashForOID(hashOID); err != nil {
+		return nil, err
+	}
+	out.imprint = hashedMessage
+	// genTime may carry fractional seconds, which time.Parse accepts after the seconds field
+	if out.genTime, err = time.Parse(generalizedTimeFormat, string(genTime)); err != nil {
+		return nil, fmt.Errorf("malformed TSTInfo genTime: %w", err)
+	}
+	if info.PeekASN1Tag(cbasn1.INTEGER) {
+		out.nonce = new(big.Int)
+		if !info.ReadASN1Integer(out.nonce) {
+			return nil, errors.New("malformed TSTInfo nonce")
+		}
+	}
+	return out, nil
+}
+
+// checkSignature checks the signed attributes against the TSTInfo and the SignerInfo signature
+// over them against cert
+func (t *timestampToken) checkSignature(cert *x509.Certificate) error {
+	if err := t.sd.CheckSignedAttributes(oidTSTInfo, t.digest, bytes.NewReader(t.sd.Content)); err != nil {
+		return err
+	}
+	signed, err := t.sd.SignedBytes()
+	if err != nil {
+		return err
+	}
+	alg, err := signatureAlgorithm(cert.PublicKey, t.digest)
+	if err != nil {
+		return err
+	}
+	if err := cert.CheckSignature(alg, signed, t.sd.Signature); err != nil {
+		return fmt.Errorf("verifying timestamp signature: %w", err)
+	}
+	return nil
+}
+
+func signatureAlgorithm(pub crypto.PublicKey, hf crypto.Hash) (x509.SignatureAlgorithm, error) {
+	algs := map[crypto.Hash][2]x509.SignatureAlgorithm{
+		crypto.SHA256: {x509.SHA256WithRSA, x509.ECDSAWithSHA256},
+		crypto.SHA384: {x509.SHA384WithRSA, x509.ECDSAWithSHA384},
+		crypto.SHA512: {x509.SHA512WithRSA, x509.ECDSAWithSHA512},
+	}[hf]
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return algs[0], nil
+	case *ecdsa.PublicKey:
+		return algs[1], nil
+	case ed25519.PublicKey:
+		return x509.PureEd25519, nil
+	}
+	return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported TSA key type %T", pub)
+}
@@ -16,14 +16,19 @@
 package signature
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature/options"
@@ -46,16 +51,55 @@ func LoadVerifier(publicKey crypto.PublicKey, hashFunc crypto.Hash) (Verifier, e
 
 // LoadVerifierWithOpts returns a signature.Verifier based on the algorithm of the public key
 // provided that will use the hash function specified when computing digests.
+//
+// If options.WithAllowedAlgorithms is given, the returned verifier rejects every signature with
+// an *AlgorithmNotAllowedError unless its algorithm (see AlgorithmFor) is in the allowlist.
+//
+// If options.WithKeyAttestation is given, the attestation is validated with VerifyKeyAttestation
+// before the key is trusted, and no verifier is returned if it fails.
+//
+// If options.WithPublicKeyPin is given, a *KeyPinMismatchError is returned unless publicKey has
+// the pinned fingerprint.
+//
+// If options.WithStrictED25519 is given and publicKey is an ED25519 key, signatures are verified
+// strictly as described for LoadStrictED25519Verifier.
 func LoadVerifierWithOpts(publicKey crypto.PublicKey, opts ...LoadOption) (Verifier, error) {
 	var rsaPSSOptions *rsa.PSSOptions
-	var useED25519ph bool
+	var useED25519ph, strictED25519 bool
+	var allowed []string
+	var attestation *options.KeyAttestation
+	var pin string
 	hashFunc := crypto.SHA256
 	for _, o := range opts {
 		o.ApplyED25519ph(&useED25519ph)
 		o.ApplyHash(&hashFunc)
 		o.ApplyRSAPSS(&rsaPSSOptions)
+		o.ApplyAllowedAlgorithms(&allowed)
+		o.ApplyKeyAttestation(&attestation)
+		o.ApplyPublicKeyPin(&pin)
+		o.ApplyStrictED25519(&strictED25519)
+	}
+
+	if pin != "" {
+		if err := checkKeyPin(publicKey, pin); err != nil {
+			return nil, err
+		}
+	}
+
+	if attestation != nil {
+		if err := VerifyKeyAttestation(publicKey, *attestation); err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := loadVerifier(publicKey, hashFunc, rsaPSSOptions, useED25519ph, strictED25519)
+	if err != nil || allowed == nil {
+		return v, err
 	}
+	return newAllowlistVerifier(v, publicKey, hashFunc, rsaPSSOptions != nil, useED25519ph, allowed)
+}
 
+func loadVerifier(publicKey crypto.PublicKey, hashFunc crypto.Hash, rsaPSSOptions *rsa.PSSOptions, useED25519ph, strictED25519 bool) (Verifier, error) {
 	switch pk := publicKey.(type) {
 	case *rsa.PublicKey:
 		if rsaPSSOptions != nil {
@@ -66,7 +110,14 @@ func LoadVerifierWithOpts(publicKey crypto.PublicKey, opts ...LoadOption) (Verif
 		return LoadECDSAVerifier(pk, hashFunc)
 	case ed25519.PublicKey:
 		if useED25519ph {
-			return LoadED25519phVerifier(pk)
+			v, err := LoadED25519phVerifier(pk)
+			if err == nil {
+				v.strict = strictED25519
+			}
+			return v, err
+		}
+		if strictED25519 {
+			return LoadStrictED25519Verifier(pk)
 		}
 		return LoadED25519Verifier(pk)
 	}
@@ -81,17 +132,27 @@ func LoadVerifierWithOpts(publicKey crypto.PublicKey, opts ...LoadOption) (Verif
 func LoadUnsafeVerifier(publicKey crypto.PublicKey) (Verifier, error) {
 	switch pk := publicKey.(type) {
 	case *rsa.PublicKey:
-		if pk == nil {
+		if pk == nil || pk.N == nil {
 			return nil, errors.New("invalid RSA public key specified")
 		}
+	case *ecdsa.PublicKey:
+		if pk == nil || pk.Curve == nil {
+			return nil, errors.New("invalid ECDSA public key specified")
+		}
+	}
+	if err := fipsCheck(publicKey, crypto.SHA1); err != nil {
+		return nil, err
+	}
+	if err := deprecationCheck(publicKey, crypto.SHA1); err != nil {
+		return nil, err
+	}
+	switch pk := publicKey.(type) {
+	case *rsa.PublicKey:
 		return &RSAPKCS1v15Verifier{
 			publicKey: pk,
 			hashFunc:  crypto.SHA1,
 		}, nil
 	case *ecdsa.PublicKey:
-		if pk == nil {
-			return nil, errors.New("invalid ECDSA public key specified")
-		}
 		return &ECDSAVerifier{
 			publicKey: pk,
 			hashFunc:  crypto.SHA1,
@@ -136,3 +197,48 @@ func LoadVerifierFromPEMFileWithOpts(path string, opts ...LoadOption) (Verifier,
 
 	return LoadVerifierWithOpts(pubKey, opts...)
 }
+
+// LoadVerifierFromBase64DER returns a signature.Verifier for a public key given as a standard
+// base64 encoding of its DER-encoded SubjectPublicKeyInfo, as commonly stored in configuration
+// without PEM armor. Surrounding whitespace is ignored. The options are as for LoadVerifierWithOpts.
+func LoadVerifierFromBase64DER(encoded string, opts ...LoadOption) (Verifier, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("decoding base64 public key: %w", err)
+	}
+	pubKey, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing DER public key as SubjectPublicKeyInfo: %w", err)
+	}
+	return LoadVerifierWithOpts(pubKey, opts...)
+}
+
+// VerifyWithPublicKey is a one-shot convenience that verifies sig over message using
+// publicKey, without the caller constructing a Verifier. The algorithm is selected from
+// the key type as in LoadVerifierWithOpts.
+//
+// SHA256 is used to compute the digest unless a hash function is given with
+// options.WithCryptoSignerOpts; if the value passed there is an *rsa.PSSOptions, an RSA
+// key is verified using RSASSA-PSS instead of PKCS#1 v1.5.
+func VerifyWithPublicKey(publicKey crypto.PublicKey, sig []byte, message io.Reader, opts ...VerifyOption) error {
+	var signerOpts crypto.SignerOpts = crypto.SHA256
+	for _, o := range opts {
+		o.ApplyCryptoSignerOpts(&signerOpts)
+	}
+
+	v, err := LoadVerifierWithOpts(publicKey, loadOptsForSignerOpts(signerOpts)...)
+	if err != nil {
+		return err
+	}
+	return v.VerifySignature(bytes.NewReader(sig), message, opts...)
+}
+
+// loadOptsForSignerOpts returns the LoadOptions that select the hash function, and RSASSA-PSS
+// if signerOpts is an *rsa.PSSOptions, given by signerOpts
+func loadOptsForSignerOpts(signerOpts crypto.SignerOpts) []LoadOption {
+	loadOpts := []LoadOption{options.WithHash(signerOpts.HashFunc())}
+	if pssOpts, ok := signerOpts.(*rsa.PSSOptions); ok {
+		loadOpts = append(loadOpts, options.WithRSAPSS(pssOpts))
+	}
+	return loadOpts
+}
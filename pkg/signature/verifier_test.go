@@ -15,10 +15,17 @@
 package signature
 
 import (
+	"bytes"
 	"crypto"
+	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
 	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
 func TestLoadUnsafeVerifier(t *testing.T) {
@@ -50,3 +57,82 @@ func TestLoadVerifier(t *testing.T) {
 		t.Fatalf("public keys were not equal")
 	}
 }
+
+func TestVerifyWithPublicKey(t *testing.T) {
+	message := []byte("sign me")
+
+	ecdsaSV, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	sig, err := ecdsaSV.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error signing message: %v", err)
+	}
+	pub, _ := ecdsaSV.PublicKey()
+	if err := VerifyWithPublicKey(pub, sig, bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying ecdsa signature: %v", err)
+	}
+	if err := VerifyWithPublicKey(pub, sig, bytes.NewReader([]byte("tampered"))); err == nil {
+		t.Error("expected error verifying tampered message")
+	}
+
+	pssOpts := &rsa.PSSOptions{Hash: crypto.SHA384}
+	rsaSV, _, err := NewRSAPSSSignerVerifier(rand.Reader, 2048, crypto.SHA384)
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	sig, err = rsaSV.SignMessage(bytes.NewReader(message), options.WithCryptoSignerOpts(pssOpts))
+	if err != nil {
+		t.Fatalf("unexpected error signing message: %v", err)
+	}
+	pub, _ = rsaSV.PublicKey()
+	if err := VerifyWithPublicKey(pub, sig, bytes.NewReader(message), options.WithCryptoSignerOpts(pssOpts)); err != nil {
+		t.Errorf("unexpected error verifying rsa-pss signature: %v", err)
+	}
+	if err := VerifyWithPublicKey(pub, sig, bytes.NewReader(message)); err == nil {
+		t.Error("expected error verifying rsa-pss signature as PKCS#1 v1.5 with SHA256")
+	}
+
+	if err := VerifyWithPublicKey("not a key", sig, bytes.NewReader(message)); err == nil {
+		t.Error("expected error for unsupported key type")
+	}
+}
+
+func TestLoadVerifierFromBase64DER(t *testing.T) {
+	sv, _, err := NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error creating signer/verifier: %v", err)
+	}
+	pub, _ := sv.PublicKey()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling public key: %v", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(der)
+
+	verifier, err := LoadVerifierFromBase64DER(" " + encoded + "\n")
+	if err != nil {
+		t.Fatalf("unexpected error loading verifier: %v", err)
+	}
+	loaded, _ := verifier.PublicKey()
+	if !pub.(*ecdsa.PublicKey).Equal(loaded) {
+		t.Fatalf("public keys were not equal")
+	}
+	message := []byte("sign me")
+	sig, err := sv.SignMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("unexpected error signing message: %v", err)
+	}
+	if err := verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+		t.Errorf("unexpected error verifying signature: %v", err)
+	}
+
+	if _, err := LoadVerifierFromBase64DER("not*base64"); err == nil || !strings.Contains(err.Error(), "decoding base64") {
+		t.Errorf("expected base64 decoding error, got %v", err)
+	}
+	notSPKI := base64.StdEncoding.EncodeToString([]byte("definitely not DER"))
+	if _, err := LoadVerifierFromBase64DER(notSPKI); err == nil || !strings.Contains(err.Error(), "SubjectPublicKeyInfo") {
+		t.Errorf("expected SubjectPublicKeyInfo parse error, got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tuf
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TrustMaterial is a snapshot of the certificates and public keys trusted for each usage,
+// e.g. the Fulcio root certificates and the Rekor public keys
+type TrustMaterial struct {
+	Certificates map[UsageKind][]*x509.Certificate
+	PublicKeys   map[UsageKind][]crypto.PublicKey
+	// FetchedAt is the time at which the material was fetched
+	FetchedAt time.Time
+}
+
+// AddPEM parses each CERTIFICATE and PUBLIC KEY block in pemBytes and adds it to the material for usage
+func (m *TrustMaterial) AddPEM(usage UsageKind, pemBytes []byte) error {
+	if m.Certificates == nil {
+		m.Certificates = map[UsageKind][]*x509.Certificate{}
+	}
+	if m.PublicKeys == nil {
+		m.PublicKeys = map[UsageKind][]crypto.PublicKey{}
+	}
+	found := false
+	for block, rest := pem.Decode(pemBytes); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return fmt.Errorf("parsing %s certificate: %w", usage, err)
+			}
+			m.Certificates[usage] = append(m.Certificates[usage], cert)
+		case "PUBLIC KEY":
+			pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return fmt.Errorf("parsing %s public key: %w", usage, err)
+			}
+			m.PublicKeys[usage] = append(m.PublicKeys[usage], pub)
+		default:
+			continue
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("no certificates or public keys found for %s", usage)
+	}
+	return nil
+}
+
+// CertPool returns a pool containing the certificates for usage
+func (m *TrustMaterial) CertPool(usage UsageKind) *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range m.Certificates[usage] {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// TrustFetcher retrieves the current TrustMaterial
+type TrustFetcher func(ctx context.Context) (*TrustMaterial, error)
+
+// trustFallbackTargets are the target names used for each usage by repositories that predate
+// custom target metadata
+var trustFallbackTargets = map[UsageKind][]string{
+	Fulcio: {"fulcio.crt.pem", "fulcio_v1.crt.pem"},
+	Rekor:  {"rekor.pub"},
+	CTFE:   {"ctfe.pub"},
+}
+
+// TUFFetcher returns a TrustFetcher that reads the targets for each of usages from the TUF
+// repository configured in the environment (see NewFromEnv), updating the local metadata
+// when its timestamp has expired. Targets of both active and expired status are included.
+func TUFFetcher(usages ...UsageKind) TrustFetcher {
+	return func(ctx context.Context) (*TrustMaterial, error) {
+		t, err := NewFromEnv(ctx)
+		if err != nil {
+			return nil, err
+		}
+		m := &TrustMaterial{FetchedAt: time.Now()}
+		for _, usage := range usages {
+			targets, err := t.GetTargetsByMeta(usage, trustFallbackTargets[usage])
+			if err != nil {
+				return nil, err
+			}
+			for _, target := range targets {
+				if err := m.AddPEM(usage, target.Target); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return m, nil
+	}
+}
+
+// URLFetcher returns a TrustFetcher that downloads a PEM bundle of certificates and/or public
+// keys for each usage from the corresponding URL. If client is nil, http.DefaultClient is used.
+func URLFetcher(client *http.Client, urls map[UsageKind]string) TrustFetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) (*TrustMaterial, error) {
+		m := &TrustMaterial{FetchedAt: time.Now()}
+		for usage, url := range urls {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return nil, fmt.Errorf("fetching %s trust material: %w", usage, err)
+			}
+			b, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("reading %s trust material: %w", usage, err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("fetching %s trust material: unexpected status %s", usage, resp.Status)
+			}
+			if err := m.AddPEM(usage, b); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+}
+
+// TrustStore serves TrustMaterial that is refreshed in the background on a fixed interval.
+// Each refresh replaces the material atomically; if a refresh fails, the last successfully
+// fetched material continues to be served. It is safe for concurrent use.
+type TrustStore struct {
+	fetch    TrustFetcher
+	interval time.Duration
+
+	current atomic.Pointer[TrustMaterial]
+	mu      sync.Mutex
+	lastErr error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewTrustStore fetches the initial TrustMaterial, returning an error if that fails, and then
+// refreshes it every interval until ctx is done or Close is called.
+func NewTrustStore(ctx context.Context, fetch TrustFetcher, interval time.Duration) (*TrustStore, error) {
+	if fetch == nil {
+		return nil, errors.New("fetcher cannot be nil")
+	}
+	if interval <= 0 {
+		return nil, errors.New("refresh interval must be positive")
+	}
+	m, err := fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching initial trust material: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &TrustStore{
+		fetch:    fetch,
+		interval: interval,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	s.current.Store(m)
+	go s.run(ctx)
+	return s, nil
+}
+
+// Current returns the most recently fetched TrustMaterial. Callers must not modify it.
+func (s *TrustStore) Current() *TrustMaterial {
+	return s.current.Load()
+}
+
+// LastError returns the error from the most recent refresh, or nil if it succeeded
+func (s *TrustStore) LastError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+// Refresh fetches the TrustMaterial immediately. On failure the current material is kept and
+// the error is returned.
+func (s *TrustStore) Refresh(ctx context.Context) error {
+	m, err := s.fetch(ctx)
+	if err == nil {
+		s.current.Store(m)
+	}
+	s.mu.Lock()
+	s.lastErr = err
+	s.mu.Unlock()
+	return err
+}
+
+// Close stops the background refresh
+func (s *TrustStore) Close() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *TrustStore) run(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = s.Refresh(ctx)
+		}
+	}
+}
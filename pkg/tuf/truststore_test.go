@@ -0,0 +1,155 @@
+//
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tuf
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/test"
+)
+
+func TestTrustStore(t *testing.T) {
+	rootCert, _, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, err := cryptoutils.MarshalCertificateToPEM(rootCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKeyPEM := func() []byte {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := cryptoutils.MarshalPublicKeyToPEM(priv.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	var mu sync.Mutex
+	rekorKey := newKeyPEM()
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/fulcio":
+			w.Write(certPEM)
+		case "/rekor":
+			w.Write(rekorKey)
+		}
+	}))
+	defer server.Close()
+
+	fetch := URLFetcher(server.Client(), map[UsageKind]string{
+		Fulcio: server.URL + "/fulcio",
+		Rekor:  server.URL + "/rekor",
+	})
+	ctx := context.Background()
+	// a long interval so that refreshes are driven by the test
+	store, err := NewTrustStore(ctx, fetch, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error creating trust store: %v", err)
+	}
+	defer store.Close()
+
+	first := store.Current()
+	if len(first.Certificates[Fulcio]) != 1 || !first.Certificates[Fulcio][0].Equal(rootCert) {
+		t.Errorf("expected Fulcio root to be loaded, got %v", first.Certificates[Fulcio])
+	}
+	if len(first.PublicKeys[Rekor]) != 1 {
+		t.Fatalf("expected one Rekor key, got %d", len(first.PublicKeys[Rekor]))
+	}
+	if _, err := rootCert.Verify(x509.VerifyOptions{Roots: first.CertPool(Fulcio)}); err != nil {
+		t.Errorf("expected root to verify against its cert pool: %v", err)
+	}
+
+	// rotate the Rekor key
+	mu.Lock()
+	rekorKey = newKeyPEM()
+	mu.Unlock()
+	if err := store.Refresh(ctx); err != nil {
+		t.Fatalf("unexpected error refreshing: %v", err)
+	}
+	second := store.Current()
+	if cryptoutils.EqualKeys(first.PublicKeys[Rekor][0], second.PublicKeys[Rekor][0]) == nil {
+		t.Error("expected rotated Rekor key after refresh")
+	}
+
+	// a failed refresh keeps the last good material
+	mu.Lock()
+	failing = true
+	mu.Unlock()
+	if err := store.Refresh(ctx); err == nil {
+		t.Fatal("expected error refreshing from failing server")
+	}
+	if store.LastError() == nil {
+		t.Error("expected LastError to report the failed refresh")
+	}
+	if store.Current() != second {
+		t.Error("expected last good material to be kept after a failed refresh")
+	}
+}
+
+func TestTrustStoreBackgroundRefresh(t *testing.T) {
+	var mu sync.Mutex
+	fetches := 0
+	fetch := func(context.Context) (*TrustMaterial, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		return &TrustMaterial{FetchedAt: time.Now()}, nil
+	}
+	store, err := NewTrustStore(context.Background(), fetch, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := store.Current()
+	deadline := time.Now().Add(5 * time.Second)
+	for store.Current() == initial && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	store.Close()
+	if store.Current() == initial {
+		t.Error("expected trust material to be refreshed in the background")
+	}
+}
+
+func TestNewTrustStoreInitialFetchError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	fetch := URLFetcher(server.Client(), map[UsageKind]string{Fulcio: server.URL})
+	if _, err := NewTrustStore(context.Background(), fetch, time.Hour); err == nil {
+		t.Error("expected error when the initial fetch fails")
+	}
+}